@@ -28,6 +28,7 @@ import (
 	"github.com/volcengine/veadk-go/utils"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/memory"
 	"google.golang.org/adk/tool"
 )
 
@@ -41,9 +42,17 @@ type Config struct {
 	KnowledgeBase    *knowledgebase.KnowledgeBase
 	PromptManager    prompts.BasePromptManager
 	DisableThought   bool
+
+	LongTermMemory         memory.Service
+	LongTermMemoryMode     LongTermMemoryMode
+	PreloadMemoryMaxTokens int
 }
 
 func New(cfg *Config) (agent.Agent, error) {
+	if err := cfg.LongTermMemoryMode.validate(); err != nil {
+		return nil, err
+	}
+
 	if cfg.Name == "" {
 		cfg.Name = common.DEFAULT_LLMAGENT_NAME
 	}
@@ -103,6 +112,22 @@ func New(cfg *Config) (agent.Agent, error) {
 		cfg.Tools = append(cfg.Tools, knowledgeTool)
 	}
 
+	if cfg.LongTermMemory != nil {
+		if cfg.LongTermMemoryMode.tool() {
+			memoryTool, err := builtin_tools.LoadLongMemoryToolWithService(cfg.LongTermMemory)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Tools = append(cfg.Tools, memoryTool)
+		}
+		if cfg.LongTermMemoryMode.preload() {
+			if cfg.PreloadMemoryMaxTokens <= 0 {
+				cfg.PreloadMemoryMaxTokens = DefaultPreloadMemoryMaxTokens
+			}
+			cfg.BeforeModelCallbacks = append(cfg.BeforeModelCallbacks, preloadMemory(cfg.LongTermMemory, cfg.PreloadMemoryMaxTokens))
+		}
+	}
+
 	return llmagent.New(cfg.Config)
 }
 
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// LongTermMemoryMode decides how an agent with a LongTermMemory reaches it.
+type LongTermMemoryMode string
+
+const (
+	// LongTermMemoryTool lets the model search past conversations with the
+	// search_past_conversations tool. It is the default.
+	LongTermMemoryTool LongTermMemoryMode = "tool"
+	// LongTermMemoryPreload searches past conversations with the user message before every model
+	// call and adds the top results to the system instruction, within PreloadMemoryMaxTokens.
+	LongTermMemoryPreload LongTermMemoryMode = "preload"
+	// LongTermMemoryToolAndPreload does both.
+	LongTermMemoryToolAndPreload LongTermMemoryMode = "tool_and_preload"
+
+	DefaultPreloadMemoryMaxTokens = 1000
+
+	// stateKeyPreloadedMemory caches the preloaded memories of the invocation, so that the
+	// follow-up model calls of a tool-using turn do not search again.
+	stateKeyPreloadedMemory = session.KeyPrefixTemp + "veadk.memory.preloaded"
+)
+
+var ErrInvalidLongTermMemoryMode = errors.New("invalid long-term memory mode")
+
+const preloadedMemoryHeader = "The following memories from past conversations with the user may be relevant. They may be outdated; prefer what the user says now.\n"
+
+func (m LongTermMemoryMode) validate() error {
+	switch m {
+	case "", LongTermMemoryTool, LongTermMemoryPreload, LongTermMemoryToolAndPreload:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidLongTermMemoryMode, m)
+}
+
+func (m LongTermMemoryMode) tool() bool {
+	return m == "" || m == LongTermMemoryTool || m == LongTermMemoryToolAndPreload
+}
+
+func (m LongTermMemoryMode) preload() bool {
+	return m == LongTermMemoryPreload || m == LongTermMemoryToolAndPreload
+}
+
+// preloadMemory returns a before-model callback injecting the memories matching the user message
+// into the system instruction. A failed search is logged and leaves the request unchanged.
+func preloadMemory(service memory.Service, maxTokens int) llmagent.BeforeModelCallback {
+	return func(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+		query := userText(ctx.UserContent())
+		if query == "" {
+			return nil, nil
+		}
+
+		// an empty block is cached too, so that users without memories are not searched on every call
+		var block string
+		found := false
+		if cached, _ := ctx.State().Get(stateKeyPreloadedMemory); cached != nil {
+			if c, ok := cached.(map[string]any); ok && c["query"] == query {
+				block, found = c["block"].(string)
+			}
+		}
+		if !found {
+			resp, err := service.Search(ctx, &memory.SearchRequest{AppName: ctx.AppName(), UserID: ctx.UserID(), Query: query})
+			if err != nil {
+				log.Warn("preload long-term memory failed", "agent", ctx.AgentName(), "error", err)
+				return nil, nil
+			}
+			block = renderMemories(resp.Memories, maxTokens)
+			_ = ctx.State().Set(stateKeyPreloadedMemory, map[string]any{"query": query, "block": block})
+		}
+		if block == "" {
+			return nil, nil
+		}
+
+		if req.Config == nil {
+			req.Config = &genai.GenerateContentConfig{}
+		}
+		if req.Config.SystemInstruction == nil {
+			req.Config.SystemInstruction = genai.NewContentFromText(block, genai.RoleUser)
+		} else {
+			req.Config.SystemInstruction.Parts = append(req.Config.SystemInstruction.Parts, genai.NewPartFromText(block))
+		}
+		return nil, nil
+	}
+}
+
+// renderMemories lists the memories in relevance order, skipping duplicates, until maxTokens.
+func renderMemories(memories []memory.Entry, maxTokens int) string {
+	var b strings.Builder
+	budget := maxTokens - estimateTokens(preloadedMemoryHeader)
+	seen := make(map[string]bool)
+	for _, entry := range memories {
+		text := strings.TrimSpace(userText(entry.Content))
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		line := "- " + text + "\n"
+		if !entry.Timestamp.IsZero() {
+			line = fmt.Sprintf("- [%s] %s\n", entry.Timestamp.Format("2006-01-02"), text)
+		}
+		if budget -= estimateTokens(line); budget < 0 {
+			break
+		}
+		b.WriteString(line)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return preloadedMemoryHeader + strings.TrimSuffix(b.String(), "\n")
+}
+
+func userText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// estimateTokens approximates the token count of text without a tokenizer: one token per CJK
+// character and per four other characters.
+func estimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
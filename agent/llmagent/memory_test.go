@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type fakeLLM struct {
+	reqs []*model.LLMRequest
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.reqs = append(m.reqs, req)
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("ok", genai.RoleModel)}, nil)
+	}
+}
+
+func memories(t *testing.T, texts ...string) memory.Service {
+	t.Helper()
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	for _, text := range texts {
+		event := session.NewEvent("past")
+		event.Author = "user"
+		event.Content = genai.NewContentFromText(text, genai.RoleUser)
+		require.NoError(t, sessions.AppendEvent(t.Context(), created.Session, event))
+	}
+	service := memory.InMemoryService()
+	require.NoError(t, service.AddSession(t.Context(), created.Session))
+	return service
+}
+
+func TestLongTermMemoryModes(t *testing.T) {
+	tests := []struct {
+		mode        LongTermMemoryMode
+		wantTool    bool
+		wantPreload bool
+	}{
+		{mode: "", wantTool: true},
+		{mode: LongTermMemoryPreload, wantPreload: true},
+		{mode: LongTermMemoryToolAndPreload, wantTool: true, wantPreload: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			llm := &fakeLLM{}
+			a, err := New(&Config{
+				Config:             llmagent.Config{Name: "assistant", Model: llm, Instruction: "Be helpful."},
+				LongTermMemory:     memories(t, "my favourite colour is teal", "weekend plans: hiking"),
+				LongTermMemoryMode: tt.mode,
+			})
+			require.NoError(t, err)
+
+			sessions := session.InMemoryService()
+			r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessions})
+			require.NoError(t, err)
+			created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+			require.NoError(t, err)
+			for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("which colour do I like", genai.RoleUser), agent.RunConfig{}) {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, llm.reqs, 1)
+			req := llm.reqs[0]
+			_, hasTool := req.Tools["search_past_conversations"]
+			assert.Equal(t, tt.wantTool, hasTool)
+
+			instruction := userText(req.Config.SystemInstruction)
+			assert.True(t, strings.HasPrefix(instruction, "Be helpful."))
+			assert.Equal(t, tt.wantPreload, strings.Contains(instruction, "my favourite colour is teal"))
+			assert.NotContains(t, instruction, "hiking")
+		})
+	}
+}
+
+func TestInvalidLongTermMemoryMode(t *testing.T) {
+	_, err := New(&Config{
+		Config:             llmagent.Config{Name: "assistant", Model: &fakeLLM{}},
+		LongTermMemory:     memories(t, "my favourite colour is teal"),
+		LongTermMemoryMode: "Preload",
+	})
+	assert.ErrorIs(t, err, ErrInvalidLongTermMemoryMode)
+}
+
+func TestRenderMemories(t *testing.T) {
+	entries := []memory.Entry{
+		{Content: genai.NewContentFromText("first memory", genai.RoleUser)},
+		{Content: genai.NewContentFromText("first memory", genai.RoleUser)},
+		{Content: genai.NewContentFromText(strings.Repeat("long ", 100), genai.RoleUser)},
+		{Content: genai.NewContentFromText("never reached", genai.RoleUser)},
+	}
+	assert.Equal(t, preloadedMemoryHeader+"- first memory", renderMemories(entries, 60))
+	assert.Empty(t, renderMemories(entries, 10))
+	assert.Equal(t, 4, estimateTokens("你好世界"))
+	assert.Equal(t, 3, estimateTokens("hello world"))
+}
+
+type countingMemory struct {
+	memory.Service
+	searches int
+}
+
+func (m *countingMemory) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
+	m.searches++
+	return &memory.SearchResponse{}, nil
+}
+
+type mapState map[string]any
+
+func (s mapState) Get(key string) (any, error) {
+	if value, ok := s[key]; ok {
+		return value, nil
+	}
+	return nil, session.ErrStateKeyNotExist
+}
+
+func (s mapState) Set(key string, value any) error {
+	s[key] = value
+	return nil
+}
+
+func (s mapState) All() iter.Seq2[string, any] {
+	return maps.All(s)
+}
+
+type fakeCallbackContext struct {
+	agent.CallbackContext
+	state mapState
+}
+
+func (c *fakeCallbackContext) UserContent() *genai.Content {
+	return genai.NewContentFromText("which colour do I like", genai.RoleUser)
+}
+
+func (c *fakeCallbackContext) State() session.State { return c.state }
+func (c *fakeCallbackContext) AppName() string      { return "app" }
+func (c *fakeCallbackContext) UserID() string       { return "user" }
+
+func TestPreloadMemoryCachesEmptyResults(t *testing.T) {
+	service := &countingMemory{}
+	callback := preloadMemory(service, DefaultPreloadMemoryMaxTokens)
+	ctx := &fakeCallbackContext{state: mapState{}}
+	for range 3 {
+		req := &model.LLMRequest{}
+		_, err := callback(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, req.Config)
+	}
+	assert.Equal(t, 1, service.searches)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2aclient"
 	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+	"github.com/volcengine/veadk-go/circuitbreaker"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/remoteagent"
 )
@@ -33,8 +34,9 @@ var (
 
 type Config struct {
 	remoteagent.A2AConfig
-	BaseUrl string
-	ApiKey  string
+	BaseUrl        string
+	ApiKey         string
+	CircuitBreaker *circuitbreaker.Breaker
 }
 
 func NewDefaultConfig() *Config {
@@ -51,6 +53,11 @@ func (c *Config) SetApiKey(apiKey string) *Config {
 	return c
 }
 
+func (c *Config) SetCircuitBreaker(breaker *circuitbreaker.Breaker) *Config {
+	c.CircuitBreaker = breaker
+	return c
+}
+
 func (c *Config) SetName(name string) *Config {
 	c.Name = name
 	return c
@@ -126,6 +133,39 @@ func (a *AuthInterceptor) Before(ctx context.Context, req *a2aclient.Request) (c
 	return ctx, nil
 }
 
+type breakerDoneKey struct{}
+
+// CircuitBreakerInterceptor guards A2A calls with a circuit breaker keyed by the remote BaseURL.
+// While the circuit is open, calls fail fast with *circuitbreaker.OpenError.
+type CircuitBreakerInterceptor struct {
+	Breaker *circuitbreaker.Breaker
+}
+
+// Before implements a before request callback. The call is released when it gets its first response, or when
+// ctx is done first, e.g. because the caller abandoned a stream that yielded nothing.
+func (c *CircuitBreakerInterceptor) Before(ctx context.Context, req *a2aclient.Request) (context.Context, error) {
+	done, err := c.Breaker.Allow(ctx, req.BaseURL)
+	if err != nil {
+		return ctx, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		done(context.Cause(ctx))
+	})
+	release := func(err error) {
+		stop()
+		done(err)
+	}
+	return context.WithValue(ctx, breakerDoneKey{}, release), nil
+}
+
+// After implements an after request callback. For streaming calls only the first response is recorded.
+func (c *CircuitBreakerInterceptor) After(ctx context.Context, resp *a2aclient.Response) error {
+	if release, ok := ctx.Value(breakerDoneKey{}).(func(error)); ok {
+		release(resp.Err)
+	}
+	return nil
+}
+
 func NewVeRemoteAgent(config *Config) (agent.Agent, error) {
 	if config.BaseUrl == "" {
 		return nil, ErrBaseUrlInvalid
@@ -138,6 +178,7 @@ func NewVeRemoteAgent(config *Config) (agent.Agent, error) {
 	}
 
 	ctx := context.Background()
+	var interceptors []a2aclient.CallInterceptor
 	if config.ApiKey != "" {
 		resolveOptions := agentcard.WithRequestHeader("Authorization", fmt.Sprintf("Bearer %s", config.ApiKey))
 		// Resolve an AgentCard
@@ -150,8 +191,16 @@ func NewVeRemoteAgent(config *Config) (agent.Agent, error) {
 
 		config.SetAgentCard(card)
 
+		interceptors = append(interceptors, &AuthInterceptor{Token: config.ApiKey})
+	}
+
+	if config.CircuitBreaker != nil {
+		interceptors = append(interceptors, &CircuitBreakerInterceptor{Breaker: config.CircuitBreaker})
+	}
+
+	if len(interceptors) > 0 {
 		clientFactory := a2aclient.NewFactory(
-			a2aclient.WithInterceptors(&AuthInterceptor{Token: config.ApiKey}),
+			a2aclient.WithInterceptors(interceptors...),
 		)
 		config.SetClientFactory(clientFactory)
 	}
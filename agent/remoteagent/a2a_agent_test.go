@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteagent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/circuitbreaker"
+)
+
+func TestCircuitBreakerInterceptor(t *testing.T) {
+	breaker := circuitbreaker.New(circuitbreaker.Config{MinRequests: 1, OpenTimeout: 10 * time.Millisecond})
+	interceptor := &CircuitBreakerInterceptor{Breaker: breaker}
+	req := &a2aclient.Request{BaseURL: "http://remote"}
+
+	ctx, err := interceptor.Before(t.Context(), req)
+	require.NoError(t, err)
+	require.NoError(t, interceptor.After(ctx, &a2aclient.Response{Err: errors.New("boom")}))
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State(req.BaseURL))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, circuitbreaker.StateHalfOpen, breaker.State(req.BaseURL))
+
+	// the probe is abandoned before any response: cancelling its context releases the slot
+	callCtx, cancel := context.WithCancel(t.Context())
+	_, err = interceptor.Before(callCtx, req)
+	require.NoError(t, err)
+	_, err = interceptor.Before(t.Context(), req)
+	assert.True(t, circuitbreaker.IsOpen(err), "only one probe allowed")
+	cancel()
+	assert.Eventually(t, func() bool {
+		ctx, err = interceptor.Before(t.Context(), req)
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	// responses after the first are ignored
+	require.NoError(t, interceptor.After(ctx, &a2aclient.Response{}))
+	require.NoError(t, interceptor.After(ctx, &a2aclient.Response{Err: errors.New("boom")}))
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State(req.BaseURL))
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphagent
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
+	"github.com/volcengine/veadk-go/prompts"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+// Config defines the configuration for a veGraphAgent.
+type Config struct {
+	// Basic agent setup. SubAgents are derived from Graph.Nodes and must be left empty.
+	AgentConfig agent.Config
+
+	Graph Graph
+}
+
+// New creates a GraphAgent.
+//
+// GraphAgent runs its nodes as a directed acyclic graph: a node starts once its dependencies
+// finished, independent nodes run concurrently, and conditional edges can skip whole branches.
+// Every node runs on its own branch, so data flows between nodes through session state: the
+// final text output of a node is stored under its OutputKey (and under the StateKey of each taken
+// edge), where downstream LLM agents can reference it in their instructions, e.g. "{research}".
+//
+// Use the GraphAgent for fan-out/fan-in pipelines that are awkward to express by nesting
+// SequentialAgents and ParallelAgents.
+func New(cfg Config) (agent.Agent, error) {
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("GraphAgent doesn't allow custom Run implementations")
+	}
+	if len(cfg.AgentConfig.SubAgents) > 0 {
+		return nil, fmt.Errorf("GraphAgent derives its sub-agents from the graph nodes")
+	}
+	if err := cfg.Graph.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.AgentConfig.Name == "" {
+		cfg.AgentConfig.Name = common.DEFAULT_GRAPHAGENT_NAME
+	}
+	if cfg.AgentConfig.Description == "" {
+		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
+	}
+
+	g := &graphAgent{
+		graph: Graph{
+			Nodes: append([]Node(nil), cfg.Graph.Nodes...),
+			Edges: append([]Edge(nil), cfg.Graph.Edges...),
+		},
+		nodes: make(map[string]Node, len(cfg.Graph.Nodes)),
+	}
+	for _, node := range g.graph.Nodes {
+		g.nodes[node.name()] = node
+		cfg.AgentConfig.SubAgents = append(cfg.AgentConfig.SubAgents, node.Agent)
+	}
+	cfg.AgentConfig.Run = g.Run
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypeGraph)}, cfg.AgentConfig.BeforeAgentCallbacks...)
+	return agent.New(cfg.AgentConfig)
+}
+
+type graphAgent struct {
+	graph Graph
+	nodes map[string]Node
+}
+
+type nodeResult struct {
+	node  string
+	event *session.Event
+	err   error
+	done  bool
+}
+
+// nodeState tracks how many incoming edges of a node are still unresolved.
+type nodeState struct {
+	pending  int
+	anyTaken bool
+	resolved bool
+}
+
+func (g *graphAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		states := make(map[string]*nodeState, len(g.graph.Nodes))
+		for _, node := range g.graph.Nodes {
+			states[node.name()] = &nodeState{}
+		}
+		for _, edge := range g.graph.Edges {
+			states[edge.To].pending++
+		}
+
+		var (
+			results = make(chan nodeResult)
+			outputs = make(map[string]string)
+			running int
+			ready   []string
+		)
+		start := func(name string) {
+			running++
+			nodeCtx := workflow.NewBranchContext(ctx, runCtx, workflow.Branch(ctx, name))
+			go runNode(nodeCtx, g.nodes[name], results)
+		}
+
+		// resolve records the outcome of an edge into name, queueing the node once it can run and
+		// propagating skips downstream once it never will.
+		var resolve func(name string, taken bool)
+		resolve = func(name string, taken bool) {
+			st := states[name]
+			st.pending--
+			st.anyTaken = st.anyTaken || taken
+			if st.resolved {
+				return
+			}
+			switch {
+			case taken && g.nodes[name].Join == JoinAny, st.pending == 0 && st.anyTaken:
+				st.resolved = true
+				ready = append(ready, name)
+			case st.pending == 0:
+				st.resolved = true
+				log.Debug("graph node skipped", "graph", ctx.Agent().Name(), "node", name)
+				for _, edge := range g.graph.Edges {
+					if edge.From == name {
+						resolve(edge.To, false)
+					}
+				}
+			}
+		}
+
+		for _, node := range g.graph.Nodes {
+			if states[node.name()].pending == 0 {
+				states[node.name()].resolved = true
+				start(node.name())
+			}
+		}
+
+		for running > 0 {
+			var res nodeResult
+			select {
+			case res = <-results:
+			case <-runCtx.Done():
+				yield(nil, runCtx.Err())
+				return
+			}
+
+			if !res.done {
+				if !yield(res.event, res.err) || res.err != nil {
+					return
+				}
+				if text := workflow.FinalText(res.event); text != "" {
+					outputs[res.node] = text
+				}
+				continue
+			}
+
+			running--
+			output := outputs[res.node]
+			delta := map[string]any{}
+			if output != "" {
+				delta[g.nodes[res.node].outputKey()] = output
+			}
+			var taken []Edge
+			for _, edge := range g.graph.Edges {
+				if edge.From != res.node {
+					continue
+				}
+				if edge.Condition == nil || edge.Condition(ctx, output) {
+					taken = append(taken, edge)
+					if edge.StateKey != "" && output != "" {
+						delta[edge.StateKey] = output
+					}
+				} else {
+					resolve(edge.To, false)
+				}
+			}
+			if len(delta) > 0 {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = ctx.Agent().Name()
+				event.Branch = workflow.Branch(ctx, res.node)
+				event.Actions.StateDelta = delta
+				if !yield(event, nil) {
+					return
+				}
+			}
+			for _, edge := range taken {
+				resolve(edge.To, true)
+			}
+
+			for _, name := range ready {
+				start(name)
+			}
+			ready = ready[:0]
+		}
+	}
+}
+
+func runNode(ctx *workflow.BranchContext, node Node, results chan<- nodeResult) {
+	name := node.name()
+	for event, err := range node.Agent.Run(ctx) {
+		select {
+		case results <- nodeResult{node: name, event: event, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+	select {
+	case results <- nodeResult{node: name, done: true}:
+	case <-ctx.Done():
+	}
+}
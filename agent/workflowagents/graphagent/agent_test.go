@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphagent
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// stepAgent answers "<name>(<inputs>)", reading its inputs from session state.
+func stepAgent(t *testing.T, name string, inputs ...string) agent.Agent {
+	return barrierAgent(t, name, nil, inputs...)
+}
+
+// barrierAgent is a stepAgent that waits on barrier before answering.
+func barrierAgent(t *testing.T, name string, barrier *sync.WaitGroup, inputs ...string) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				if barrier != nil {
+					barrier.Done()
+					barrier.Wait()
+				}
+				var values []string
+				for _, key := range inputs {
+					value, _ := ctx.Session().State().Get(key)
+					values = append(values, fmt.Sprint(value))
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(fmt.Sprintf("%s(%s)", name, strings.Join(values, ",")), genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func runGraph(t *testing.T, graph Graph) map[string]string {
+	t.Helper()
+	root, err := New(Config{AgentConfig: agent.Config{Name: "graph"}, Graph: graph})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: root, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	outputs := map[string]string{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("go", genai.RoleUser), agent.RunConfig{}) {
+			if !assert.NoError(t, err) {
+				return
+			}
+			if event.Content != nil {
+				outputs[event.Author] = event.Content.Parts[0].Text
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("graph did not finish")
+	}
+	return outputs
+}
+
+func TestGraphAgentFanOutFanIn(t *testing.T) {
+	barrier := &sync.WaitGroup{}
+	barrier.Add(2)
+	outputs := runGraph(t, Graph{
+		Nodes: []Node{
+			{Agent: stepAgent(t, "plan")},
+			// Both branches block until the other one started, so the test hangs unless they run concurrently.
+			{Agent: barrierAgent(t, "left", barrier, "plan")},
+			{Agent: barrierAgent(t, "right", barrier, "right_input"), OutputKey: "right_result"},
+			{Agent: stepAgent(t, "merge", "left", "right_result")},
+		},
+		Edges: []Edge{
+			{From: "plan", To: "left"},
+			{From: "plan", To: "right", StateKey: "right_input"},
+			{From: "left", To: "merge"},
+			{From: "right", To: "merge"},
+		},
+	})
+
+	assert.Equal(t, "left(plan())", outputs["left"])
+	assert.Equal(t, "right(plan())", outputs["right"])
+	assert.Equal(t, "merge(left(plan()),right(plan()))", outputs["merge"])
+}
+
+func TestGraphAgentConditionalEdges(t *testing.T) {
+	isApproved := func(agent.InvocationContext, string) bool { return false }
+	isRejected := func(agent.InvocationContext, string) bool { return true }
+
+	tests := []struct {
+		name        string
+		join        JoinMode
+		wantRan     []string
+		wantSkipped []string
+	}{
+		{name: "join all runs after the taken branch", join: JoinAll, wantRan: []string{"review", "reject", "notify"}, wantSkipped: []string{"publish"}},
+		{name: "join any runs after the taken branch", join: JoinAny, wantRan: []string{"review", "reject", "notify"}, wantSkipped: []string{"publish"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := runGraph(t, Graph{
+				Nodes: []Node{
+					{Agent: stepAgent(t, "review")},
+					{Agent: stepAgent(t, "publish")},
+					{Agent: stepAgent(t, "reject")},
+					{Agent: stepAgent(t, "notify"), Join: tt.join},
+				},
+				Edges: []Edge{
+					{From: "review", To: "publish", Condition: isApproved},
+					{From: "review", To: "reject", Condition: isRejected},
+					{From: "publish", To: "notify"},
+					{From: "reject", To: "notify"},
+				},
+			})
+			for _, name := range tt.wantRan {
+				assert.Contains(t, outputs, name)
+			}
+			for _, name := range tt.wantSkipped {
+				assert.NotContains(t, outputs, name)
+			}
+		})
+	}
+}
+
+func TestGraphValidate(t *testing.T) {
+	a, b, c := stepAgent(t, "a"), stepAgent(t, "b"), stepAgent(t, "c")
+
+	tests := []struct {
+		name    string
+		graph   Graph
+		wantErr error
+	}{
+		{name: "empty", graph: Graph{}, wantErr: ErrEmptyGraph},
+		{name: "duplicate", graph: Graph{Nodes: []Node{{Agent: a}, {Agent: a}}}, wantErr: ErrDuplicateNode},
+		{name: "unknown node", graph: Graph{Nodes: []Node{{Agent: a}}, Edges: []Edge{{From: "a", To: "x"}}}, wantErr: ErrUnknownNode},
+		{
+			name: "cycle",
+			graph: Graph{
+				Nodes: []Node{{Agent: a}, {Agent: b}, {Agent: c}},
+				Edges: []Edge{{From: "a", To: "b"}, {From: "b", To: "c"}, {From: "c", To: "b"}},
+			},
+			wantErr: ErrCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Graph: tt.graph})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGraphExport(t *testing.T) {
+	graph := Graph{
+		Nodes: []Node{{Agent: stepAgent(t, "plan")}, {Agent: stepAgent(t, "write-up")}, {Agent: stepAgent(t, "merge"), Join: JoinAny}},
+		Edges: []Edge{
+			{From: "plan", To: "write-up"},
+			{From: "plan", To: "merge", Label: "trivial", Condition: func(agent.InvocationContext, string) bool { return true }},
+			{From: "write-up", To: "merge"},
+		},
+	}
+
+	dot, err := graph.DOT()
+	require.NoError(t, err)
+	assert.Contains(t, dot, `plan->"write-up"`)
+	assert.Contains(t, dot, `plan->merge[ label=trivial, style=dashed ];`)
+
+	assert.Equal(t, `flowchart TD
+    n0["plan"]
+    n1["write-up"]
+    n2{"merge"}
+    n0 --> n1
+    n0 -.->|"trivial"| n2
+    n1 --> n2
+`, graph.Mermaid())
+}
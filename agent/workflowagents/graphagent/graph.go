@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphagent
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"google.golang.org/adk/agent"
+)
+
+var (
+	ErrEmptyGraph    = errors.New("graph has no nodes")
+	ErrDuplicateNode = errors.New("duplicate graph node")
+	ErrUnknownNode   = errors.New("edge references unknown node")
+	ErrCycle         = errors.New("graph contains a cycle")
+)
+
+// JoinMode decides when a node with several incoming edges runs.
+type JoinMode int
+
+const (
+	// JoinAll waits until every incoming edge is resolved and runs if at least one was taken.
+	// Edges whose source was skipped count as not taken, so a join after an if/else still runs.
+	JoinAll JoinMode = iota
+	// JoinAny runs as soon as the first incoming edge is taken and ignores the others.
+	JoinAny
+)
+
+func (m JoinMode) String() string {
+	if m == JoinAny {
+		return "any"
+	}
+	return "all"
+}
+
+// Node is a step of the graph. It is identified by the name of its agent.
+type Node struct {
+	Agent agent.Agent
+
+	// OutputKey is the session state key receiving the node's final text output.
+	// Defaults to the agent name.
+	OutputKey string
+
+	Join JoinMode
+}
+
+func (n Node) name() string {
+	return n.Agent.Name()
+}
+
+func (n Node) outputKey() string {
+	if n.OutputKey != "" {
+		return n.OutputKey
+	}
+	return n.name()
+}
+
+// Condition decides whether an edge is taken once its source node finished.
+// output is the final text output of the source node.
+type Condition func(ctx agent.InvocationContext, output string) bool
+
+// Edge makes To depend on From.
+type Edge struct {
+	From string
+	To   string
+
+	// Condition makes the edge conditional. A nil Condition is always taken.
+	Condition Condition
+	// Label describes the condition in exported diagrams.
+	Label string
+
+	// StateKey additionally stores the output of From under this key when the edge is taken,
+	// so that To can read its input under a name of its own.
+	StateKey string
+}
+
+// Graph is a directed acyclic graph of agents.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Validate checks node names and edge ends and rejects cycles.
+func (g Graph) Validate() error {
+	if len(g.Nodes) == 0 {
+		return ErrEmptyGraph
+	}
+	names := make(map[string]bool, len(g.Nodes))
+	for i, node := range g.Nodes {
+		if node.Agent == nil {
+			return fmt.Errorf("node %d has no agent", i)
+		}
+		if names[node.name()] {
+			return fmt.Errorf("%w: %q", ErrDuplicateNode, node.name())
+		}
+		names[node.name()] = true
+	}
+	for _, edge := range g.Edges {
+		if !names[edge.From] {
+			return fmt.Errorf("%w: %q", ErrUnknownNode, edge.From)
+		}
+		if !names[edge.To] {
+			return fmt.Errorf("%w: %q", ErrUnknownNode, edge.To)
+		}
+	}
+	if _, err := g.topologicalOrder(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// topologicalOrder sorts the nodes with Kahn's algorithm, keeping declaration order among independent nodes.
+func (g Graph) topologicalOrder() ([]string, error) {
+	indegree := make(map[string]int, len(g.Nodes))
+	for _, edge := range g.Edges {
+		indegree[edge.To]++
+	}
+	var queue, order []string
+	for _, node := range g.Nodes {
+		if indegree[node.name()] == 0 {
+			queue = append(queue, node.name())
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		order = append(order, name)
+		for _, edge := range g.Edges {
+			if edge.From != name {
+				continue
+			}
+			indegree[edge.To]--
+			if indegree[edge.To] == 0 {
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	if len(order) < len(g.Nodes) {
+		var cyclic []string
+		for name, n := range indegree {
+			if n > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("%w through %s", ErrCycle, strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// DOT renders the graph topology in Graphviz DOT format.
+func (g Graph) DOT() (string, error) {
+	const graphName = "G"
+	dot := gographviz.NewEscape()
+	if err := dot.SetName(graphName); err != nil {
+		return "", err
+	}
+	if err := dot.SetDir(true); err != nil {
+		return "", err
+	}
+	for _, node := range g.Nodes {
+		attrs := map[string]string{"label": node.name()}
+		if node.Join == JoinAny {
+			attrs["shape"] = "diamond"
+		}
+		if err := dot.AddNode(graphName, node.name(), attrs); err != nil {
+			return "", err
+		}
+	}
+	for _, edge := range g.Edges {
+		attrs := map[string]string{}
+		if edge.Condition != nil {
+			attrs["style"] = "dashed"
+		}
+		if edge.Label != "" {
+			attrs["label"] = edge.Label
+		}
+		if err := dot.AddEdge(edge.From, edge.To, true, attrs); err != nil {
+			return "", err
+		}
+	}
+	return dot.String(), nil
+}
+
+// Mermaid renders the graph topology as a Mermaid flowchart.
+func (g Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.name()] = id
+		if node.Join == JoinAny {
+			fmt.Fprintf(&b, "    %s{%s}\n", id, mermaidText(node.name()))
+		} else {
+			fmt.Fprintf(&b, "    %s[%s]\n", id, mermaidText(node.name()))
+		}
+	}
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Condition != nil {
+			arrow = "-.->"
+		}
+		if edge.Label != "" {
+			fmt.Fprintf(&b, "    %s %s|%s| %s\n", ids[edge.From], arrow, mermaidText(edge.Label), ids[edge.To])
+		} else {
+			fmt.Fprintf(&b, "    %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+		}
+	}
+	return b.String()
+}
+
+func mermaidText(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workflow holds helpers shared by the veadk workflow agents.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// Branch returns the branch of child when it runs isolated from its siblings, following the
+// "parent_branch.agent.child" format of the ADK parallel agent.
+func Branch(ctx agent.InvocationContext, child string) string {
+	branch := fmt.Sprintf("%s.%s", ctx.Agent().Name(), child)
+	if ctx.Branch() != "" {
+		branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+	}
+	return branch
+}
+
+// BranchContext runs a child agent on its own branch, with the cancellation of its own context.
+type BranchContext struct {
+	agent.InvocationContext
+	ctx    context.Context
+	branch string
+}
+
+// NewBranchContext derives a child invocation context. ctx must be derived from parent.
+func NewBranchContext(parent agent.InvocationContext, ctx context.Context, branch string) *BranchContext {
+	return &BranchContext{InvocationContext: parent, ctx: ctx, branch: branch}
+}
+
+func (c *BranchContext) Branch() string {
+	return c.branch
+}
+
+func (c *BranchContext) Deadline() (time.Time, bool) {
+	return c.ctx.Deadline()
+}
+
+func (c *BranchContext) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+func (c *BranchContext) Err() error {
+	return c.ctx.Err()
+}
+
+func (c *BranchContext) Value(key any) any {
+	return c.ctx.Value(key)
+}
+
+// FinalText returns the text of a complete model answer, ignoring thoughts.
+func FinalText(event *session.Event) string {
+	if event == nil || event.Partial || event.Content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range event.Content.Parts {
+		if part != nil && part.Text != "" && !part.Thought {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "")
+}
+
+// UserText returns the text parts of the user content that started the invocation.
+func UserText(ctx agent.InvocationContext) string {
+	content := ctx.UserContent()
+	if content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// GenerateText calls llm without streaming and returns the text of its answer, ignoring thoughts.
+func GenerateText(ctx context.Context, llm model.LLM, req *model.LLMRequest) (string, error) {
+	var text strings.Builder
+	for resp, err := range llm.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", err
+		}
+		if resp == nil || resp.Partial || resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if part != nil && !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+	}
+	return text.String(), nil
+}
+
+// TrimCodeFence strips the markdown code fence models often wrap around JSON answers.
+func TrimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	return strings.TrimSpace(text)
+}
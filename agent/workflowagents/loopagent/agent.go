@@ -15,10 +15,26 @@
 package loopagent
 
 import (
+	"fmt"
+	"iter"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
 	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/observability"
 	"github.com/volcengine/veadk-go/prompts"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/adk/agent"
-	googleADKLoopAgent "google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// StateKeyIteration holds the index of the last finished iteration, starting at 1.
+	StateKeyIteration = "veadk.loop.iteration"
+	// StateKeyExitReason holds why the loop stopped.
+	StateKeyExitReason = "veadk.loop.exit_reason"
+
+	ExitReasonMaxIterations = "max_iterations"
+	ExitReasonEscalated     = "escalated"
 )
 
 // Config defines the configuration for a veLoopAgent.
@@ -27,10 +43,13 @@ type Config struct {
 	AgentConfig agent.Config
 
 	// If MaxIterations == 0, then LoopAgent runs indefinitely or until any
-	// sub-agent escalates.
+	// sub-agent escalates or an exit condition holds.
 	MaxIterations uint
 
-	//TODO: add tracers
+	// ExitConditions are checked in order after every iteration; the loop stops at the first
+	// one that holds. With or without them, the iteration count and exit reason are written to
+	// session state (StateKeyIteration, StateKeyExitReason) and to the agent span.
+	ExitConditions []ExitCondition
 }
 
 // New creates a LoopAgent.
@@ -49,8 +68,115 @@ func New(cfg Config) (agent.Agent, error) {
 		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
 	}
 
-	return googleADKLoopAgent.New(googleADKLoopAgent.Config{
-		AgentConfig:   cfg.AgentConfig,
-		MaxIterations: cfg.MaxIterations,
-	})
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("LoopAgent doesn't allow custom Run implementations")
+	}
+	l := &loopAgent{maxIterations: cfg.MaxIterations, exitConditions: cfg.ExitConditions}
+	cfg.AgentConfig.Run = l.Run
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypeLoop)}, cfg.AgentConfig.BeforeAgentCallbacks...)
+	return agent.New(cfg.AgentConfig)
+}
+
+type loopAgent struct {
+	maxIterations  uint
+	exitConditions []ExitCondition
+}
+
+func (l *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		var previous string
+		for index := 1; l.maxIterations == 0 || uint(index) <= l.maxIterations; index++ {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			output, escalated, ok := l.runIteration(ctx, index, yield)
+			if !ok {
+				return
+			}
+			if escalated {
+				yield(l.iterationEvent(ctx, index, ExitReasonEscalated, nil), nil)
+				return
+			}
+
+			delta := map[string]any{}
+			reason := ""
+			iteration := Iteration{Index: index, Output: output, PreviousOutput: previous}
+			for _, condition := range l.exitConditions {
+				verdict, err := condition.Check(ctx, iteration)
+				if err != nil {
+					yield(nil, fmt.Errorf("loop agent %s exit condition failed: %w", ctx.Agent().Name(), err))
+					return
+				}
+				for key, value := range verdict.StateDelta {
+					delta[key] = value
+				}
+				if verdict.Stop {
+					reason = verdict.Reason
+					break
+				}
+			}
+			if reason == "" && l.maxIterations != 0 && uint(index) == l.maxIterations {
+				reason = ExitReasonMaxIterations
+			}
+			if !yield(l.iterationEvent(ctx, index, reason, delta), nil) || reason != "" {
+				return
+			}
+			previous = output
+		}
+	}
+}
+
+// runIteration runs the sub-agents once in sequence, under an iteration span, and returns the
+// last final text answer. It stops early when a sub-agent escalates or the caller stops iterating.
+func (l *loopAgent) runIteration(ctx agent.InvocationContext, index int, yield func(*session.Event, error) bool) (output string, escalated, ok bool) {
+	end := observability.StartWorkflowStep(ctx, ctx.Branch(), fmt.Sprintf("%s %d", observability.SpanLoopIteration, index),
+		attribute.String(observability.AttrWorkflowType, observability.WorkflowTypeLoop),
+		attribute.Int(observability.AttrLoopIteration, index))
+	var stepErr error
+	defer func() { end(stepErr) }()
+
+	for _, subAgent := range ctx.Agent().SubAgents() {
+		for event, err := range subAgent.Run(ctx) {
+			if err != nil {
+				stepErr = err
+			}
+			if !yield(event, err) {
+				return output, escalated, false
+			}
+			if event == nil {
+				continue
+			}
+			if text := workflow.FinalText(event); text != "" {
+				output = text
+			}
+			if event.Actions.Escalate {
+				escalated = true
+			}
+		}
+		if escalated {
+			return output, true, true
+		}
+	}
+	return output, false, true
+}
+
+// iterationEvent records the finished iteration, and the exit reason when the loop stops.
+func (l *loopAgent) iterationEvent(ctx agent.InvocationContext, index int, reason string, delta map[string]any) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	for key, value := range delta {
+		event.Actions.StateDelta[key] = value
+	}
+	event.Actions.StateDelta[StateKeyIteration] = index
+
+	attrs := []attribute.KeyValue{attribute.Int(observability.AttrLoopIteration, index)}
+	if reason != "" {
+		event.Actions.StateDelta[StateKeyExitReason] = reason
+		attrs = append(attrs, attribute.String(observability.AttrLoopExitReason, reason))
+	}
+	observability.SetAgentSpanAttributes(ctx, attrs...)
+	return event
 }
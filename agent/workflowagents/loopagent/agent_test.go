@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopagent
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/observability"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// reviser answers drafts[i] on its i-th run (repeating the last one) and counts its runs in state.
+func reviser(t *testing.T, drafts ...string) agent.Agent {
+	t.Helper()
+	runs := 0
+	a, err := agent.New(agent.Config{
+		Name: "reviser",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				draft := drafts[min(runs, len(drafts)-1)]
+				runs++
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(draft, genai.RoleModel)
+				event.Actions.StateDelta["runs"] = runs
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+type fakeLLM struct {
+	answers []string
+	prompts []string
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		answer := m.answers[min(len(m.prompts), len(m.answers)-1)]
+		m.prompts = append(m.prompts, req.Contents[0].Parts[0].Text)
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(answer, genai.RoleModel)}, nil)
+	}
+}
+
+func runLoop(t *testing.T, cfg Config) session.Session {
+	t.Helper()
+	loop, err := New(cfg)
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: loop, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("write a haiku", genai.RoleUser), agent.RunConfig{}) {
+		require.NoError(t, err)
+	}
+	got, err := sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	return got.Session
+}
+
+func stateValue(t *testing.T, s session.Session, key string) any {
+	t.Helper()
+	value, err := s.State().Get(key)
+	require.NoError(t, err)
+	return value
+}
+
+func TestLoopAgentExitConditions(t *testing.T) {
+	passOnSecond := &fakeLLM{answers: []string{
+		`{"pass": false, "feedback": "too long"}`,
+		"```json\n{\"pass\": true, \"feedback\": \"\"}\n```",
+	}}
+	critic, err := Critic(CriticConfig{Model: passOnSecond})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		cfg           Config
+		wantIteration int
+		wantReason    string
+	}{
+		{
+			name: "state predicate",
+			cfg: Config{
+				AgentConfig: agent.Config{SubAgents: []agent.Agent{reviser(t, "draft")}},
+				ExitConditions: []ExitCondition{StatePredicate(func(state session.ReadonlyState) bool {
+					runs, _ := state.Get("runs")
+					return runs == 3
+				})},
+			},
+			wantIteration: 3,
+			wantReason:    ExitReasonStatePredicate,
+		},
+		{
+			name: "converged",
+			cfg: Config{
+				AgentConfig:    agent.Config{SubAgents: []agent.Agent{reviser(t, "v1", "v2", "v3")}},
+				ExitConditions: []ExitCondition{Converged()},
+			},
+			wantIteration: 4,
+			wantReason:    ExitReasonConverged,
+		},
+		{
+			name: "critic",
+			cfg: Config{
+				AgentConfig:    agent.Config{SubAgents: []agent.Agent{reviser(t, "v1", "v2")}},
+				ExitConditions: []ExitCondition{critic},
+			},
+			wantIteration: 2,
+			wantReason:    ExitReasonCriticPassed,
+		},
+		{
+			name: "max iterations",
+			cfg: Config{
+				AgentConfig:    agent.Config{SubAgents: []agent.Agent{reviser(t, "v1", "v2", "v3", "v4")}},
+				MaxIterations:  2,
+				ExitConditions: []ExitCondition{Converged()},
+			},
+			wantIteration: 2,
+			wantReason:    ExitReasonMaxIterations,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runLoop(t, tt.cfg)
+			assert.Equal(t, tt.wantIteration, stateValue(t, s, "runs"))
+			assert.Equal(t, tt.wantIteration, stateValue(t, s, StateKeyIteration))
+			assert.Equal(t, tt.wantReason, stateValue(t, s, StateKeyExitReason))
+		})
+	}
+
+	assert.Equal(t, "User request:\nwrite a haiku\n\nLatest output:\nv2", passOnSecond.prompts[1])
+}
+
+func TestCriticFeedback(t *testing.T) {
+	critic, err := Critic(CriticConfig{
+		Model:       &fakeLLM{answers: []string{`{"pass": false, "feedback": "add a kigo"}`}},
+		FeedbackKey: "review",
+	})
+	require.NoError(t, err)
+
+	s := runLoop(t, Config{
+		AgentConfig:    agent.Config{SubAgents: []agent.Agent{reviser(t, "draft")}},
+		MaxIterations:  1,
+		ExitConditions: []ExitCondition{critic},
+	})
+	assert.Equal(t, "add a kigo", stateValue(t, s, "review"))
+	assert.Equal(t, ExitReasonMaxIterations, stateValue(t, s, StateKeyExitReason))
+}
+
+func TestLoopWithoutConditions(t *testing.T) {
+	orig := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(orig)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	s := runLoop(t, Config{
+		AgentConfig:   agent.Config{Name: "loop", SubAgents: []agent.Agent{reviser(t, "draft")}},
+		MaxIterations: 3,
+	})
+	assert.Equal(t, 3, stateValue(t, s, "runs"))
+	assert.Equal(t, 3, stateValue(t, s, StateKeyIteration))
+	assert.Equal(t, ExitReasonMaxIterations, stateValue(t, s, StateKeyExitReason))
+
+	var iterations []int64
+	for _, span := range recorder.Ended() {
+		for _, kv := range span.Attributes() {
+			if kv.Key == observability.AttrLoopIteration && span.Name() == fmt.Sprintf("%s %d", observability.SpanLoopIteration, kv.Value.AsInt64()) {
+				iterations = append(iterations, kv.Value.AsInt64())
+			}
+		}
+	}
+	assert.Equal(t, []int64{1, 2, 3}, iterations)
+}
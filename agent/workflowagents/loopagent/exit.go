@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loopagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	ExitReasonStatePredicate = "state_predicate"
+	ExitReasonConverged      = "converged"
+	ExitReasonCriticPassed   = "critic_passed"
+
+	// DefaultCriticFeedbackKey is the state key receiving the critic feedback, so that the next
+	// iteration can address it, e.g. with "{critic_feedback}" in an instruction.
+	DefaultCriticFeedbackKey = "critic_feedback"
+)
+
+// Iteration describes the iteration that just finished.
+type Iteration struct {
+	// Index starts at 1.
+	Index int
+	// Output is the last final text answer produced by the sub-agents in this iteration.
+	Output string
+	// PreviousOutput is the Output of the previous iteration.
+	PreviousOutput string
+}
+
+// Verdict is the result of an exit condition.
+type Verdict struct {
+	Stop   bool
+	Reason string
+	// StateDelta is written to session state whether or not the loop stops.
+	StateDelta map[string]any
+}
+
+// ExitCondition decides whether the loop stops after an iteration.
+type ExitCondition interface {
+	Check(ctx agent.InvocationContext, iteration Iteration) (Verdict, error)
+}
+
+// ExitConditionFunc adapts a Go func to an ExitCondition.
+type ExitConditionFunc func(ctx agent.InvocationContext, iteration Iteration) (Verdict, error)
+
+func (f ExitConditionFunc) Check(ctx agent.InvocationContext, iteration Iteration) (Verdict, error) {
+	return f(ctx, iteration)
+}
+
+// StatePredicate stops the loop once predicate holds over the session state.
+func StatePredicate(predicate func(state session.ReadonlyState) bool) ExitCondition {
+	return ExitConditionFunc(func(ctx agent.InvocationContext, _ Iteration) (Verdict, error) {
+		return Verdict{Stop: predicate(ctx.Session().State()), Reason: ExitReasonStatePredicate}, nil
+	})
+}
+
+// Converged stops the loop once an iteration produces the same output as the previous one.
+func Converged() ExitCondition {
+	return ExitConditionFunc(func(_ agent.InvocationContext, iteration Iteration) (Verdict, error) {
+		stop := iteration.Index > 1 && iteration.Output != "" &&
+			strings.TrimSpace(iteration.Output) == strings.TrimSpace(iteration.PreviousOutput)
+		return Verdict{Stop: stop, Reason: ExitReasonConverged}, nil
+	})
+}
+
+const defaultCriticInstruction = `You are a strict reviewer. Judge whether the latest output fully satisfies the user request.
+Answer with a JSON object {"pass": true|false, "feedback": "<what must still be improved, empty when passing>"} and nothing else.`
+
+type CriticConfig struct {
+	// Model reviews the output of every iteration.
+	Model model.LLM
+	// Instruction replaces the default review instruction. It must keep the JSON answer format.
+	Instruction string
+	// FeedbackKey is the state key receiving the feedback. Defaults to DefaultCriticFeedbackKey.
+	FeedbackKey string
+}
+
+type critic struct {
+	config CriticConfig
+}
+
+type criticVerdict struct {
+	Pass     bool   `json:"pass"`
+	Feedback string `json:"feedback"`
+}
+
+// Critic asks a model to review the output of each iteration, stopping the loop once it passes.
+// The feedback is stored in session state for the next iteration.
+func Critic(config CriticConfig) (ExitCondition, error) {
+	if config.Model == nil {
+		return nil, errors.New("critic requires a model")
+	}
+	if config.Instruction == "" {
+		config.Instruction = defaultCriticInstruction
+	}
+	if config.FeedbackKey == "" {
+		config.FeedbackKey = DefaultCriticFeedbackKey
+	}
+	return &critic{config: config}, nil
+}
+
+func (c *critic) Check(ctx agent.InvocationContext, iteration Iteration) (Verdict, error) {
+	prompt := fmt.Sprintf("User request:\n%s\n\nLatest output:\n%s", workflow.UserText(ctx), iteration.Output)
+	req := &model.LLMRequest{
+		Model:    c.config.Model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(prompt, genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(c.config.Instruction, genai.RoleUser),
+			Temperature:       genai.Ptr[float32](0),
+			ResponseMIMEType:  "application/json",
+			ResponseSchema: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"pass":     {Type: genai.TypeBoolean},
+					"feedback": {Type: genai.TypeString},
+				},
+				Required: []string{"pass"},
+			},
+		},
+	}
+	text, err := workflow.GenerateText(ctx, c.config.Model, req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("critic failed: %w", err)
+	}
+
+	var verdict criticVerdict
+	text = workflow.TrimCodeFence(text)
+	if err := json.Unmarshal([]byte(text), &verdict); err != nil {
+		return Verdict{}, fmt.Errorf("critic returned invalid verdict %q: %w", text, err)
+	}
+	return Verdict{
+		Stop:       verdict.Pass,
+		Reason:     ExitReasonCriticPassed,
+		StateDelta: map[string]any{c.config.FeedbackKey: verdict.Feedback},
+	}, nil
+}
@@ -15,19 +15,43 @@
 package parallelagent
 
 import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
 	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
 	"github.com/volcengine/veadk-go/prompts"
 	"google.golang.org/adk/agent"
 	googleADKParallelAgent "google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
 )
 
 // Config defines the configuration for a ParallelAgent.
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
-	//TODO: add tracers
+
+	// Aggregator merges the sub-agent outputs into a single final answer, emitted by the
+	// ParallelAgent once the branches joined. If nil, outputs stay interleaved in the session.
+	Aggregator Aggregator
+	// OutputKey stores the aggregated answer in session state when set.
+	OutputKey string
+
+	// BranchTimeout bounds the run of each sub-agent, so that one slow branch does not block
+	// the join. A timed-out branch is reported to the Aggregator with context.DeadlineExceeded;
+	// without an Aggregator, a failed or timed-out branch fails the ParallelAgent. Zero means no timeout.
+	BranchTimeout time.Duration
 }
 
+// New creates a ParallelAgent.
+//
+// ParallelAgent runs its sub-agents concurrently, each on its own branch. With an Aggregator it
+// also produces a single final answer, such as when asking several specialists the same question.
 func New(cfg Config) (agent.Agent, error) {
 
 	if cfg.AgentConfig.Name == "" {
@@ -36,8 +60,167 @@ func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Description == "" {
 		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
 	}
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypeParallel)}, cfg.AgentConfig.BeforeAgentCallbacks...)
+
+	if cfg.Aggregator == nil && cfg.BranchTimeout <= 0 {
+		return googleADKParallelAgent.New(googleADKParallelAgent.Config{
+			AgentConfig: cfg.AgentConfig,
+		})
+	}
+
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("ParallelAgent doesn't allow custom Run implementations")
+	}
+	p := &parallelAgent{aggregator: cfg.Aggregator, outputKey: cfg.OutputKey, branchTimeout: cfg.BranchTimeout}
+	cfg.AgentConfig.Run = p.Run
+	return agent.New(cfg.AgentConfig)
+}
+
+type parallelAgent struct {
+	aggregator    Aggregator
+	outputKey     string
+	branchTimeout time.Duration
+}
+
+type branchMessage struct {
+	index int
+	event *session.Event
+	done  bool
+	err   error
+}
+
+func (p *parallelAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		subAgents := ctx.Agent().SubAgents()
+		messages := make(chan branchMessage)
+		for i, sub := range subAgents {
+			go p.runBranch(ctx, runCtx, i, sub, messages)
+		}
 
-	return googleADKParallelAgent.New(googleADKParallelAgent.Config{
-		AgentConfig: cfg.AgentConfig,
-	})
+		outputs := make([]string, len(subAgents))
+		finished := make([]*BranchResult, len(subAgents))
+		shortCircuiter, _ := p.aggregator.(ShortCircuiter)
+	join:
+		for remaining := len(subAgents); remaining > 0; {
+			var msg branchMessage
+			select {
+			case msg = <-messages:
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			}
+
+			if !msg.done {
+				if !yield(msg.event, nil) {
+					return
+				}
+				if text := workflow.FinalText(msg.event); text != "" {
+					outputs[msg.index] = text
+				}
+				continue
+			}
+
+			remaining--
+			result := &BranchResult{Agent: subAgents[msg.index].Name(), Output: outputs[msg.index], Err: msg.err}
+			finished[msg.index] = result
+			if msg.err != nil {
+				// without an aggregator to handle it, a failed branch fails the agent, like the ADK ParallelAgent
+				if p.aggregator == nil {
+					yield(nil, fmt.Errorf("parallel agent %s branch %s failed: %w", ctx.Agent().Name(), result.Agent, msg.err))
+					return
+				}
+				log.Warn("parallel branch failed", "agent", ctx.Agent().Name(), "branch", result.Agent, "error", msg.err)
+			}
+			if shortCircuiter != nil && shortCircuiter.ShortCircuit(*result) {
+				break join
+			}
+		}
+		cancel()
+
+		if p.aggregator == nil {
+			return
+		}
+		results := make([]BranchResult, 0, len(finished))
+		for _, result := range finished {
+			if result != nil {
+				results = append(results, *result)
+			}
+		}
+		answer, err := p.aggregator.Aggregate(ctx, results)
+		if err != nil {
+			yield(nil, fmt.Errorf("parallel agent %s aggregation failed: %w", ctx.Agent().Name(), err))
+			return
+		}
+
+		event := session.NewEvent(ctx.InvocationID())
+		event.Author = ctx.Agent().Name()
+		event.Branch = ctx.Branch()
+		event.Content = genai.NewContentFromText(answer, genai.RoleModel)
+		if p.outputKey != "" {
+			event.Actions.StateDelta[p.outputKey] = answer
+		}
+		yield(event, nil)
+	}
+}
+
+// runBranch forwards the events of one sub-agent and reports how it finished. It reports the
+// branch as finished on timeout even if the sub-agent ignores cancellation.
+func (p *parallelAgent) runBranch(parent agent.InvocationContext, runCtx context.Context, index int, sub agent.Agent, messages chan<- branchMessage) {
+	branchCtx, cancel := runCtx, context.CancelFunc(func() {})
+	if p.branchTimeout > 0 {
+		branchCtx, cancel = context.WithTimeout(runCtx, p.branchTimeout)
+	}
+	defer cancel()
+
+	send := func(msg branchMessage) bool {
+		select {
+		case messages <- msg:
+			return true
+		case <-runCtx.Done():
+			return false
+		}
+	}
+
+	type item struct {
+		event *session.Event
+		err   error
+	}
+	items := make(chan item)
+	go func() {
+		defer close(items)
+		subCtx := workflow.NewBranchContext(parent, branchCtx, workflow.Branch(parent, sub.Name()))
+		for event, err := range sub.Run(subCtx) {
+			select {
+			case items <- item{event: event, err: err}:
+			case <-branchCtx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case it, ok := <-items:
+			if !ok {
+				send(branchMessage{index: index, done: true, err: branchCtx.Err()})
+				return
+			}
+			if it.err != nil {
+				send(branchMessage{index: index, done: true, err: it.err})
+				return
+			}
+			if !send(branchMessage{index: index, event: it.event}) {
+				return
+			}
+		case <-branchCtx.Done():
+			send(branchMessage{index: index, done: true, err: branchCtx.Err()})
+			return
+		}
+	}
 }
@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parallelagent
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/server/adka2a"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// specialist answers after delay, or fails when answer is empty.
+func specialist(t *testing.T, name, answer string, delay time.Duration) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					yield(nil, ctx.Err())
+					return
+				}
+				if answer == "" {
+					yield(nil, errors.New("specialist unavailable"))
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(answer, genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+type fakeLLM struct {
+	req *model.LLMRequest
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.req = req
+		yield(&model.LLMResponse{Content: genai.NewContentFromText("summary", genai.RoleModel)}, nil)
+	}
+}
+
+// run returns the final answer of the parallel agent and the names of the sub-agents that answered.
+func run(t *testing.T, cfg Config, subAgents ...agent.Agent) (string, []string, error) {
+	t.Helper()
+	cfg.AgentConfig.Name = "panel"
+	cfg.AgentConfig.SubAgents = subAgents
+	panel, err := New(cfg)
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: panel, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	var final string
+	var answered []string
+	for event, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("question", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return final, answered, err
+		}
+		if event.Author == "panel" {
+			final = event.Content.Parts[0].Text
+		} else {
+			answered = append(answered, event.Author)
+		}
+	}
+	return final, answered, nil
+}
+
+func TestAggregators(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		subAgents    func(t *testing.T) []agent.Agent
+		wantFinal    string
+		wantAnswered []string
+		wantErr      error
+	}{
+		{
+			name: "concatenate in sub-agent order",
+			cfg:  Config{Aggregator: Concatenate()},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					specialist(t, "lawyer", "sue", 20*time.Millisecond),
+					specialist(t, "doctor", "", 0),
+					specialist(t, "banker", "save", 0),
+				}
+			},
+			wantFinal:    "## lawyer\n\nsue\n\n## doctor\n\n(no answer: specialist unavailable)\n\n## banker\n\nsave",
+			wantAnswered: []string{"banker", "lawyer"},
+		},
+		{
+			name: "first success cancels the rest",
+			cfg:  Config{Aggregator: FirstSuccess()},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					specialist(t, "slow", "late", time.Hour),
+					specialist(t, "broken", "", 0),
+					specialist(t, "fast", "early", 10*time.Millisecond),
+				}
+			},
+			wantFinal:    "early",
+			wantAnswered: []string{"fast"},
+		},
+		{
+			name: "majority vote on key",
+			cfg:  Config{Aggregator: MajorityVote("verdict")},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					specialist(t, "a", `{"verdict": "reject", "note": "a"}`, 0),
+					specialist(t, "b", "```json\n{\"verdict\": \"accept\", \"note\": \"b\"}\n```", 10*time.Millisecond),
+					specialist(t, "c", `{"verdict": "accept", "note": "c"}`, 20*time.Millisecond),
+					specialist(t, "d", "not json", 0),
+				}
+			},
+			wantFinal:    "```json\n{\"verdict\": \"accept\", \"note\": \"b\"}\n```",
+			wantAnswered: []string{"a", "d", "b", "c"},
+		},
+		{
+			name: "branch timeout",
+			cfg:  Config{Aggregator: Concatenate(), BranchTimeout: 50 * time.Millisecond},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					specialist(t, "slow", "late", time.Hour),
+					specialist(t, "fast", "early", 0),
+				}
+			},
+			wantFinal:    "## slow\n\n(no answer: context deadline exceeded)\n\n## fast\n\nearly",
+			wantAnswered: []string{"fast"},
+		},
+		{
+			name: "branch timeout without aggregator",
+			cfg:  Config{BranchTimeout: 50 * time.Millisecond},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					specialist(t, "slow", "late", time.Hour),
+					specialist(t, "fast", "early", 0),
+				}
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "no result",
+			cfg:  Config{Aggregator: FirstSuccess()},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{specialist(t, "broken", "", 0)}
+			},
+			wantErr: ErrNoResult,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			final, answered, err := run(t, tt.cfg, tt.subAgents(t)...)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFinal, final)
+			assert.ElementsMatch(t, tt.wantAnswered, answered)
+		})
+	}
+}
+
+func TestLLMSummary(t *testing.T) {
+	llm := &fakeLLM{}
+	aggregator, err := LLMSummary(LLMSummaryConfig{Model: llm})
+	require.NoError(t, err)
+
+	final, _, err := run(t, Config{Aggregator: aggregator, OutputKey: "answer"},
+		specialist(t, "lawyer", "sue", 0),
+		specialist(t, "banker", "save", 0),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "summary", final)
+	prompt := llm.req.Contents[0].Parts[0].Text
+	assert.Contains(t, prompt, "User request:\nquestion")
+	assert.Contains(t, prompt, "## lawyer\n\nsue")
+	assert.Contains(t, prompt, "## banker\n\nsave")
+}
+
+func TestAgentType(t *testing.T) {
+	// without options, the ADK ParallelAgent is used as it is
+	panel, err := New(Config{AgentConfig: agent.Config{Name: "panel", SubAgents: []agent.Agent{specialist(t, "lawyer", "sue", 0)}}})
+	require.NoError(t, err)
+	skills := adka2a.BuildAgentSkills(panel)
+	require.NotEmpty(t, skills)
+	assert.Contains(t, skills[0].Tags, "parallel_workflow")
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parallelagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+var (
+	ErrNoResult = errors.New("no branch produced a result")
+	ErrNoVote   = errors.New("no branch produced a structured output to vote on")
+)
+
+// BranchResult is the outcome of one sub-agent.
+type BranchResult struct {
+	// Agent is the name of the sub-agent.
+	Agent string
+	// Output is the final text answer of the sub-agent.
+	Output string
+	// Err is set when the sub-agent failed or exceeded the branch timeout.
+	Err error
+}
+
+func (r BranchResult) succeeded() bool {
+	return r.Err == nil && r.Output != ""
+}
+
+// Aggregator merges the branch results into a single final answer. Results are passed in
+// sub-agent order and only contain branches that finished.
+type Aggregator interface {
+	Aggregate(ctx agent.InvocationContext, results []BranchResult) (string, error)
+}
+
+// ShortCircuiter is implemented by aggregators that can answer before every branch finished.
+// ShortCircuit is called as each branch finishes; returning true cancels the remaining branches.
+type ShortCircuiter interface {
+	ShortCircuit(result BranchResult) bool
+}
+
+// AggregatorFunc adapts a Go func to an Aggregator.
+type AggregatorFunc func(ctx agent.InvocationContext, results []BranchResult) (string, error)
+
+func (f AggregatorFunc) Aggregate(ctx agent.InvocationContext, results []BranchResult) (string, error) {
+	return f(ctx, results)
+}
+
+type concatenate struct{}
+
+// Concatenate joins the branch outputs under a markdown heading per sub-agent.
+func Concatenate() Aggregator {
+	return concatenate{}
+}
+
+func (concatenate) Aggregate(_ agent.InvocationContext, results []BranchResult) (string, error) {
+	return concatenateResults(results)
+}
+
+func concatenateResults(results []BranchResult) (string, error) {
+	sections := make([]string, 0, len(results))
+	succeeded := false
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			sections = append(sections, fmt.Sprintf("## %s\n\n(no answer: %v)", result.Agent, result.Err))
+		case result.Output != "":
+			sections = append(sections, fmt.Sprintf("## %s\n\n%s", result.Agent, result.Output))
+			succeeded = true
+		}
+	}
+	if !succeeded {
+		return "", ErrNoResult
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+type firstSuccess struct{}
+
+// FirstSuccess answers with the first branch that succeeds and cancels the others.
+func FirstSuccess() Aggregator {
+	return firstSuccess{}
+}
+
+func (firstSuccess) ShortCircuit(result BranchResult) bool {
+	return result.succeeded()
+}
+
+func (firstSuccess) Aggregate(_ agent.InvocationContext, results []BranchResult) (string, error) {
+	for _, result := range results {
+		if result.succeeded() {
+			return result.Output, nil
+		}
+	}
+	return "", ErrNoResult
+}
+
+type majorityVote struct {
+	key string
+}
+
+// MajorityVote answers with the output most branches agree on. Outputs are parsed as JSON and
+// compared on key, or as a whole when key is empty; outputs that are not JSON do not vote.
+// Ties go to the earliest sub-agent.
+func MajorityVote(key string) Aggregator {
+	return majorityVote{key: key}
+}
+
+func (m majorityVote) Aggregate(_ agent.InvocationContext, results []BranchResult) (string, error) {
+	counts := make(map[string]int)
+	first := make(map[string]string)
+	var order []string
+	for _, result := range results {
+		if !result.succeeded() {
+			continue
+		}
+		vote, ok := m.vote(result.Output)
+		if !ok {
+			continue
+		}
+		if counts[vote] == 0 {
+			order = append(order, vote)
+			first[vote] = result.Output
+		}
+		counts[vote]++
+	}
+	if len(order) == 0 {
+		return "", ErrNoVote
+	}
+	winner := order[0]
+	for _, vote := range order[1:] {
+		if counts[vote] > counts[winner] {
+			winner = vote
+		}
+	}
+	return first[winner], nil
+}
+
+// vote returns the canonical JSON of the compared value.
+func (m majorityVote) vote(output string) (string, bool) {
+	var value any
+	if err := json.Unmarshal([]byte(workflow.TrimCodeFence(output)), &value); err != nil {
+		return "", false
+	}
+	if m.key != "" {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = object[m.key]; !ok {
+			return "", false
+		}
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(canonical), true
+}
+
+const defaultSummaryInstruction = `Several specialists answered the same user request independently. Their answers follow, one per heading.
+Write a single final answer for the user: merge what they agree on, resolve or point out contradictions, and do not mention the specialists.`
+
+type LLMSummaryConfig struct {
+	// Model writes the summary.
+	Model model.LLM
+	// Instruction replaces the default synthesis instruction.
+	Instruction string
+}
+
+type llmSummary struct {
+	config LLMSummaryConfig
+}
+
+// LLMSummary asks a model to synthesize the branch outputs into one answer.
+func LLMSummary(config LLMSummaryConfig) (Aggregator, error) {
+	if config.Model == nil {
+		return nil, errors.New("llm summary requires a model")
+	}
+	if config.Instruction == "" {
+		config.Instruction = defaultSummaryInstruction
+	}
+	return &llmSummary{config: config}, nil
+}
+
+func (s *llmSummary) Aggregate(ctx agent.InvocationContext, results []BranchResult) (string, error) {
+	answers, err := concatenateResults(results)
+	if err != nil {
+		return "", err
+	}
+	prompt := fmt.Sprintf("User request:\n%s\n\nAnswers:\n\n%s", workflow.UserText(ctx), answers)
+	req := &model.LLMRequest{
+		Model:    s.config.Model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(prompt, genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(s.config.Instruction, genai.RoleUser),
+		},
+	}
+	text, err := workflow.GenerateText(ctx, s.config.Model, req)
+	if err != nil {
+		return "", fmt.Errorf("llm summary failed: %w", err)
+	}
+	return text, nil
+}
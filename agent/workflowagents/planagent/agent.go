@@ -0,0 +1,309 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/volcengine/veadk-go/agent/llmagent"
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/observability"
+	"github.com/volcengine/veadk-go/prompts"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	// StateKeyPlan holds the Plan, so that clients such as the web UI can display progress.
+	StateKeyPlan = "veadk.plan"
+
+	DefaultPlannerName = "planner"
+	DefaultMaxSteps    = 10
+	DefaultMaxReplans  = 5
+)
+
+var (
+	ErrNoExecutor = errors.New("plan agent requires an executor")
+	ErrSubAgents  = errors.New("plan agent derives its sub-agents from the planner and executor")
+)
+
+const defaultPlannerInstruction = `You are a planner. Break the user request into a short list of concrete steps, each one a self-contained task for an executor agent that has tools.
+Answer with a JSON object {"steps": ["<step>", ...], "final_answer": "<answer>"} and nothing else.
+When a current plan is given, steps marked done already ran: use their results to list only the steps still needed.
+Once the request is fully answered, return no steps and write the final answer for the user.`
+
+// plannerSchema enforces the planner answer.
+var plannerSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"steps":        {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		"final_answer": {Type: genai.TypeString},
+	},
+	Required: []string{"steps"},
+}
+
+type plannerAnswer struct {
+	Steps       []string `json:"steps"`
+	FinalAnswer string   `json:"final_answer"`
+}
+
+// Config defines the configuration for a vePlanAgent.
+type Config struct {
+	// Basic agent setup. SubAgents must be empty: they are the planner and the executor.
+	AgentConfig agent.Config
+
+	// Planner configures the LLM agent writing the plan. Its output schema and instruction are
+	// managed by the plan agent: Instruction, if set, replaces the default planning instruction
+	// and the current plan is appended to it. Name defaults to DefaultPlannerName.
+	Planner llmagent.Config
+
+	// Executor runs each step, typically an LLM agent with tools. It reads the step from the
+	// conversation, as a message of the plan agent.
+	Executor agent.Agent
+
+	// MaxSteps caps the number of executed steps. Defaults to DefaultMaxSteps.
+	MaxSteps int
+	// MaxReplans caps the number of re-plans; once reached, the remaining steps run as planned
+	// and the planner is called once more for the final answer. Defaults to DefaultMaxReplans;
+	// a negative value disables re-planning.
+	MaxReplans int
+}
+
+// New creates a PlanAgent.
+//
+// PlanAgent asks its planner for a list of steps, then runs them one at a time with its
+// executor, re-planning after each step from what the executor found. The plan is kept in
+// session state under StateKeyPlan.
+//
+// Use the PlanAgent for open-ended tasks whose steps depend on intermediate results, such as
+// research that needs several rounds of searches.
+func New(cfg Config) (agent.Agent, error) {
+	if cfg.Executor == nil {
+		return nil, ErrNoExecutor
+	}
+	if len(cfg.AgentConfig.SubAgents) > 0 {
+		return nil, ErrSubAgents
+	}
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("PlanAgent doesn't allow custom Run implementations")
+	}
+
+	if cfg.AgentConfig.Name == "" {
+		cfg.AgentConfig.Name = common.DEFAULT_PLANAGENT_NAME
+	}
+	if cfg.AgentConfig.Description == "" {
+		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultMaxSteps
+	}
+	if cfg.MaxReplans < 0 {
+		cfg.MaxReplans = 0
+	} else if cfg.MaxReplans == 0 {
+		cfg.MaxReplans = DefaultMaxReplans
+	}
+
+	planner, err := newPlanner(cfg.Planner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create planner: %w", err)
+	}
+
+	p := &planAgent{planner: planner, executor: cfg.Executor, maxSteps: cfg.MaxSteps, maxReplans: cfg.MaxReplans}
+	cfg.AgentConfig.SubAgents = []agent.Agent{planner, cfg.Executor}
+	cfg.AgentConfig.Run = p.Run
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypePlan)}, cfg.AgentConfig.BeforeAgentCallbacks...)
+	return agent.New(cfg.AgentConfig)
+}
+
+func newPlanner(cfg llmagent.Config) (agent.Agent, error) {
+	if cfg.Name == "" {
+		cfg.Name = DefaultPlannerName
+	}
+	instruction := cfg.Instruction
+	if instruction == "" && cfg.PromptManager != nil {
+		instruction = cfg.PromptManager.GetPrompt()
+	}
+	if instruction == "" {
+		instruction = defaultPlannerInstruction
+	}
+	cfg.InstructionProvider = func(ctx agent.ReadonlyContext) (string, error) {
+		plan, ok, err := PlanFromState(ctx.ReadonlyState())
+		if err != nil || !ok || len(plan.Steps) == 0 {
+			return instruction, err
+		}
+		return fmt.Sprintf("%s\n\nCurrent plan:\n%s", instruction, plan), nil
+	}
+	// Instruction must not be empty, or llmagent.New fills in the default prompt.
+	cfg.Instruction = instruction
+	cfg.OutputSchema = plannerSchema
+	cfg.DisallowTransferToParent = true
+	cfg.DisallowTransferToPeers = true
+	return llmagent.New(&cfg)
+}
+
+type planAgent struct {
+	planner    agent.Agent
+	executor   agent.Agent
+	maxSteps   int
+	maxReplans int
+}
+
+func (p *planAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		// reset the plan of the previous request, which the planner would otherwise read as the current plan
+		plan := &Plan{Status: PlanRunning}
+		if !yield(p.planEvent(ctx, plan, ""), nil) {
+			return
+		}
+		answer, ok := p.runPlanner(ctx, yield)
+		if !ok {
+			return
+		}
+		plan.replace(answer.Steps)
+		plan.FinalAnswer = answer.FinalAnswer
+
+		// stale is set when steps ran after the last planner answer, so its final answer is outdated
+		stale := false
+		for {
+			index := plan.next()
+			if index < 0 {
+				plan.Status = PlanCompleted
+				break
+			}
+			if plan.executed() >= p.maxSteps {
+				plan.Status = PlanMaxSteps
+				break
+			}
+
+			plan.Steps[index].Status = StepRunning
+			if !yield(p.planEvent(ctx, plan, fmt.Sprintf("Step %d: %s", index+1, plan.Steps[index].Description)), nil) {
+				return
+			}
+			output, failed, ok := p.runStep(ctx, index, yield)
+			if !ok {
+				return
+			}
+			if failed {
+				plan.Steps[index].Status = StepFailed
+				plan.Status = PlanFailed
+				yield(p.planEvent(ctx, plan, ""), nil)
+				return
+			}
+			plan.Steps[index].Status = StepDone
+			plan.Steps[index].Result = output
+			if !yield(p.planEvent(ctx, plan, ""), nil) {
+				return
+			}
+
+			if plan.Replans >= p.maxReplans {
+				stale = true
+				continue
+			}
+			answer, ok := p.runPlanner(ctx, yield)
+			if !ok {
+				return
+			}
+			plan.Replans++
+			plan.replace(answer.Steps)
+			plan.FinalAnswer = answer.FinalAnswer
+		}
+
+		if plan.Status == PlanCompleted && stale {
+			// re-planning is exhausted: only ask the planner for the final answer, ignoring new steps
+			answer, ok := p.runPlanner(ctx, yield)
+			if !ok {
+				return
+			}
+			plan.FinalAnswer = answer.FinalAnswer
+		}
+		if plan.Status == PlanCompleted && plan.FinalAnswer == "" {
+			plan.FinalAnswer = plan.lastResult()
+		}
+		yield(p.planEvent(ctx, plan, plan.FinalAnswer), nil)
+	}
+}
+
+// runPlanner runs the planner and parses its answer. It reports planner failures to yield.
+func (p *planAgent) runPlanner(ctx agent.InvocationContext, yield func(*session.Event, error) bool) (plannerAnswer, bool) {
+	var output string
+	for event, err := range p.planner.Run(ctx) {
+		if err != nil {
+			yield(nil, fmt.Errorf("plan agent %s planner failed: %w", ctx.Agent().Name(), err))
+			return plannerAnswer{}, false
+		}
+		if !yield(event, nil) {
+			return plannerAnswer{}, false
+		}
+		if text := workflow.FinalText(event); text != "" {
+			output = text
+		}
+	}
+
+	var answer plannerAnswer
+	if err := json.Unmarshal([]byte(workflow.TrimCodeFence(output)), &answer); err != nil {
+		yield(nil, fmt.Errorf("plan agent %s planner returned invalid plan %q: %w", ctx.Agent().Name(), output, err))
+		return plannerAnswer{}, false
+	}
+	return answer, true
+}
+
+// runStep runs the executor on one step, under a step span, and returns its last final text
+// answer. The executor error, if any, is passed to yield. ok is false when the caller stopped.
+func (p *planAgent) runStep(ctx agent.InvocationContext, index int, yield func(*session.Event, error) bool) (output string, failed, ok bool) {
+	end := observability.StartWorkflowStep(ctx, ctx.Branch(), fmt.Sprintf("%s %d", observability.SpanPlanStep, index+1),
+		attribute.String(observability.AttrWorkflowType, observability.WorkflowTypePlan),
+		attribute.Int(observability.AttrPlanStep, index+1))
+	var stepErr error
+	defer func() { end(stepErr) }()
+
+	for event, err := range p.executor.Run(ctx) {
+		if err != nil {
+			stepErr = err
+		}
+		if !yield(event, err) {
+			return output, stepErr != nil, false
+		}
+		if err != nil {
+			return output, true, true
+		}
+		if text := workflow.FinalText(event); text != "" {
+			output = text
+		}
+	}
+	return output, false, true
+}
+
+// planEvent persists the plan, with text as the message of the plan agent if not empty.
+func (p *planAgent) planEvent(ctx agent.InvocationContext, plan *Plan, text string) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	if text != "" {
+		event.Content = genai.NewContentFromText(text, genai.RoleModel)
+	}
+	event.Actions.StateDelta[StateKeyPlan] = plan.stateValue()
+
+	observability.SetAgentSpanAttributes(ctx,
+		attribute.Int(observability.AttrPlanReplans, plan.Replans),
+		attribute.String(observability.AttrPlanStatus, string(plan.Status)),
+	)
+	return event
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planagent
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/agent/llmagent"
+	"google.golang.org/adk/agent"
+	adkllmagent "google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type fakeLLM struct {
+	answers      []string
+	instructions []string
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		answer := m.answers[min(len(m.instructions), len(m.answers)-1)]
+		m.instructions = append(m.instructions, req.Config.SystemInstruction.Parts[0].Text)
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(answer, genai.RoleModel)}, nil)
+	}
+}
+
+// executor answers "result <n>" on its n-th run.
+func executor(t *testing.T) agent.Agent {
+	t.Helper()
+	runs := 0
+	a, err := agent.New(agent.Config{
+		Name: "executor",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				runs++
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText("result "+string(rune('0'+runs)), genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+// run returns the final plan and the texts of the plan agent messages.
+func run(t *testing.T, cfg Config) (*Plan, []string) {
+	t.Helper()
+	cfg.AgentConfig.Name = "researcher"
+	cfg.Executor = executor(t)
+	researcher, err := New(cfg)
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: researcher, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	var messages []string
+	for event, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("compare two databases", genai.RoleUser), agent.RunConfig{}) {
+		require.NoError(t, err)
+		if event.Author == "researcher" && event.Content != nil {
+			messages = append(messages, event.Content.Parts[0].Text)
+		}
+	}
+
+	got, err := sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	plan, ok, err := PlanFromState(got.Session.State())
+	require.NoError(t, err)
+	require.True(t, ok)
+	return plan, messages
+}
+
+func TestPlanAndReplan(t *testing.T) {
+	planner := &fakeLLM{answers: []string{
+		`{"steps": ["search A", "search B", "compare"]}`,
+		"```json\n{\"steps\": [\"search B in depth\", \"compare\"]}\n```",
+		`{"steps": ["compare"]}`,
+		`{"steps": [], "final_answer": "B wins"}`,
+	}}
+	plan, messages := run(t, Config{Planner: llmagent.Config{Config: adkllmagent.Config{Model: planner}}})
+
+	assert.Equal(t, &Plan{
+		Status: PlanCompleted,
+		Steps: []Step{
+			{Description: "search A", Status: StepDone, Result: "result 1"},
+			{Description: "search B in depth", Status: StepDone, Result: "result 2"},
+			{Description: "compare", Status: StepDone, Result: "result 3"},
+		},
+		Replans:     3,
+		FinalAnswer: "B wins",
+	}, plan)
+	assert.Equal(t, []string{"Step 1: search A", "Step 2: search B in depth", "Step 3: compare", "B wins"}, messages)
+
+	require.Len(t, planner.instructions, 4)
+	assert.Equal(t, defaultPlannerInstruction, planner.instructions[0])
+	assert.Contains(t, planner.instructions[1], "Current plan:\n1. [done] search A\n   Result: result 1\n2. [pending] search B\n3. [pending] compare")
+}
+
+func TestCaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantStatus PlanStatus
+		wantSteps  []StepStatus
+		wantCalls  int
+		wantAnswer string
+	}{
+		{
+			name:       "max steps",
+			cfg:        Config{MaxSteps: 2},
+			wantStatus: PlanMaxSteps,
+			wantSteps:  []StepStatus{StepDone, StepDone, StepPending},
+			wantCalls:  3,
+			wantAnswer: "summary",
+		},
+		{
+			name:       "max replans",
+			cfg:        Config{MaxReplans: 1},
+			wantStatus: PlanCompleted,
+			wantSteps:  []StepStatus{StepDone, StepDone, StepDone},
+			wantCalls:  3,
+			wantAnswer: "summary",
+		},
+		{
+			name:       "no replans",
+			cfg:        Config{MaxReplans: -1},
+			wantStatus: PlanCompleted,
+			wantSteps:  []StepStatus{StepDone, StepDone, StepDone},
+			wantCalls:  2,
+			wantAnswer: "result 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planner := &fakeLLM{answers: []string{`{"steps": ["one", "two", "three"]}`, `{"steps": ["two", "three"]}`, `{"steps": ["three"], "final_answer": "summary"}`}}
+			if tt.cfg.MaxReplans < 0 {
+				// the planner answers no final answer, so the last result is used
+				planner.answers = planner.answers[:2]
+			}
+			tt.cfg.Planner.Model = planner
+			plan, _ := run(t, tt.cfg)
+
+			assert.Equal(t, tt.wantStatus, plan.Status)
+			var statuses []StepStatus
+			for _, step := range plan.Steps {
+				statuses = append(statuses, step.Status)
+			}
+			assert.Equal(t, tt.wantSteps, statuses)
+			assert.Len(t, planner.instructions, tt.wantCalls)
+			assert.Equal(t, tt.wantAnswer, plan.FinalAnswer)
+		})
+	}
+}
+
+func TestPlanResetBetweenRequests(t *testing.T) {
+	planner := &fakeLLM{answers: []string{
+		`{"steps": ["search A"]}`,
+		`{"steps": [], "final_answer": "A"}`,
+		`{"steps": ["search B"]}`,
+		`{"steps": [], "final_answer": "B"}`,
+	}}
+	researcher, err := New(Config{
+		AgentConfig: agent.Config{Name: "researcher"},
+		Planner:     llmagent.Config{Config: adkllmagent.Config{Model: planner}},
+		Executor:    executor(t),
+	})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: researcher, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	for _, request := range []string{"find A", "find B"} {
+		for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText(request, genai.RoleUser), agent.RunConfig{}) {
+			require.NoError(t, err)
+		}
+	}
+
+	require.Len(t, planner.instructions, 4)
+	assert.Equal(t, defaultPlannerInstruction, planner.instructions[2], "the second request starts without the plan of the first")
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/session"
+)
+
+type StepStatus string
+
+const (
+	StepPending StepStatus = "pending"
+	StepRunning StepStatus = "running"
+	StepDone    StepStatus = "done"
+	StepFailed  StepStatus = "failed"
+)
+
+type PlanStatus string
+
+const (
+	PlanRunning   PlanStatus = "running"
+	PlanCompleted PlanStatus = "completed"
+	PlanMaxSteps  PlanStatus = "max_steps"
+	PlanFailed    PlanStatus = "failed"
+)
+
+// Step is one step of a plan.
+type Step struct {
+	Description string     `json:"description"`
+	Status      StepStatus `json:"status"`
+	// Result is the final text answer of the executor for this step.
+	Result string `json:"result,omitempty"`
+}
+
+// Plan is the progress of a plan agent, persisted in session state under StateKeyPlan.
+type Plan struct {
+	Status PlanStatus `json:"status"`
+	Steps  []Step     `json:"steps"`
+	// Replans counts the plans written after the initial one.
+	Replans     int    `json:"replans"`
+	FinalAnswer string `json:"final_answer,omitempty"`
+}
+
+// PlanFromState reads the plan persisted in session state. It returns false if there is none.
+func PlanFromState(state session.ReadonlyState) (*Plan, bool, error) {
+	value, err := state.Get(StateKeyPlan)
+	if err != nil || value == nil {
+		return nil, false, nil
+	}
+	// Round-trip through JSON: persistent session services hand back a generic map.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false, err
+	}
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, false, fmt.Errorf("invalid plan in state: %w", err)
+	}
+	return plan, true, nil
+}
+
+// stateValue converts the plan to a generic map, so that it is stored the same way by every
+// session service.
+func (p *Plan) stateValue() map[string]any {
+	data, _ := json.Marshal(p)
+	var value map[string]any
+	_ = json.Unmarshal(data, &value)
+	return value
+}
+
+// executed returns the number of steps that ran.
+func (p *Plan) executed() int {
+	n := 0
+	for _, step := range p.Steps {
+		if step.Status != StepPending {
+			n++
+		}
+	}
+	return n
+}
+
+// next returns the index of the first pending step, or -1.
+func (p *Plan) next() int {
+	for i, step := range p.Steps {
+		if step.Status == StepPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastResult returns the result of the last step that ran, or "".
+func (p *Plan) lastResult() string {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		if p.Steps[i].Status == StepDone {
+			return p.Steps[i].Result
+		}
+	}
+	return ""
+}
+
+// replace drops the pending steps in favor of descriptions.
+func (p *Plan) replace(descriptions []string) {
+	steps := p.Steps[:p.executed()]
+	for _, description := range descriptions {
+		if description = strings.TrimSpace(description); description != "" {
+			steps = append(steps, Step{Description: description, Status: StepPending})
+		}
+	}
+	p.Steps = steps
+}
+
+// String renders the plan for the planner.
+func (p *Plan) String() string {
+	var b strings.Builder
+	for i, step := range p.Steps {
+		fmt.Fprintf(&b, "%d. [%s] %s\n", i+1, step.Status, step.Description)
+		if step.Result != "" {
+			fmt.Fprintf(&b, "   Result: %s\n", step.Result)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
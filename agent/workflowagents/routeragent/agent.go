@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routeragent
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
+	"github.com/volcengine/veadk-go/prompts"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// StateKeyRoute holds the name of the sub-agent chosen by the last routing decision.
+	StateKeyRoute = "veadk.router.route"
+	// StateKeyRouteReason holds the reason given for the last routing decision, if any.
+	StateKeyRouteReason = "veadk.router.reason"
+
+	// MetadataKeyDecision is the CustomMetadata key of the decision event.
+	MetadataKeyDecision = "veadk_router_decision"
+)
+
+var (
+	ErrNoClassifier   = errors.New("router agent requires a classifier")
+	ErrNoSubAgents    = errors.New("router agent requires at least one sub-agent")
+	ErrNoRoute        = errors.New("classifier returned no route")
+	ErrUnknownRoute   = errors.New("route does not match any sub-agent")
+	ErrInvalidDefault = errors.New("default route does not match any sub-agent")
+)
+
+// Config defines the configuration for a veRouterAgent.
+type Config struct {
+	// Basic agent setup.
+	AgentConfig agent.Config
+
+	// Classifier picks the sub-agent to run.
+	Classifier Classifier
+
+	// DefaultRoute is the sub-agent used when the classifier fails or returns an unknown route.
+	// If empty, such failures end the run with an error.
+	DefaultRoute string
+}
+
+// New creates a RouterAgent.
+//
+// RouterAgent evaluates its classifier against the user input and session state, records the
+// decision as an event and a span attribute, then runs exactly one of its sub-agents.
+//
+// Use the RouterAgent when requests should be dispatched to a specialist, such as splitting
+// billing questions from technical support.
+func New(cfg Config) (agent.Agent, error) {
+	if cfg.Classifier == nil {
+		return nil, ErrNoClassifier
+	}
+	if len(cfg.AgentConfig.SubAgents) == 0 {
+		return nil, ErrNoSubAgents
+	}
+	if cfg.DefaultRoute != "" && findSubAgent(cfg.AgentConfig.SubAgents, cfg.DefaultRoute) == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDefault, cfg.DefaultRoute)
+	}
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("RouterAgent doesn't allow custom Run implementations")
+	}
+
+	if cfg.AgentConfig.Name == "" {
+		cfg.AgentConfig.Name = common.DEFAULT_ROUTERAGENT_NAME
+	}
+	if cfg.AgentConfig.Description == "" {
+		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
+	}
+
+	r := &routerAgent{classifier: cfg.Classifier, defaultRoute: cfg.DefaultRoute}
+	cfg.AgentConfig.Run = r.Run
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypeRouter)}, cfg.AgentConfig.BeforeAgentCallbacks...)
+	return agent.New(cfg.AgentConfig)
+}
+
+type routerAgent struct {
+	classifier   Classifier
+	defaultRoute string
+}
+
+func (r *routerAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		decision, target, err := r.route(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		observability.SetAgentSpanAttributes(ctx,
+			attribute.String(observability.AttrRouterRoute, decision.Route),
+			attribute.String(observability.AttrRouterReason, decision.Reason),
+		)
+		if !yield(decisionEvent(ctx, decision), nil) {
+			return
+		}
+
+		for event, err := range target.Run(ctx) {
+			if !yield(event, err) {
+				return
+			}
+		}
+	}
+}
+
+// route classifies the invocation and resolves the sub-agent, falling back to the default route.
+func (r *routerAgent) route(ctx agent.InvocationContext) (Decision, agent.Agent, error) {
+	subAgents := ctx.Agent().SubAgents()
+
+	decision, err := r.classifier.Classify(ctx)
+	if err == nil && decision.Route == "" {
+		err = ErrNoRoute
+	}
+	if err == nil {
+		if target := findSubAgent(subAgents, decision.Route); target != nil {
+			return decision, target, nil
+		}
+		err = fmt.Errorf("%w: %q", ErrUnknownRoute, decision.Route)
+	}
+
+	if r.defaultRoute == "" {
+		return Decision{}, nil, fmt.Errorf("router agent %s: %w", ctx.Agent().Name(), err)
+	}
+	log.Warn("router falls back to default route", "agent", ctx.Agent().Name(), "route", r.defaultRoute, "error", err)
+	return Decision{Route: r.defaultRoute, Reason: "default route: " + err.Error()},
+		findSubAgent(subAgents, r.defaultRoute), nil
+}
+
+func decisionEvent(ctx agent.InvocationContext, decision Decision) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.CustomMetadata = map[string]any{
+		MetadataKeyDecision: map[string]any{
+			"route":  decision.Route,
+			"reason": decision.Reason,
+		},
+	}
+	event.Actions.StateDelta[StateKeyRoute] = decision.Route
+	event.Actions.StateDelta[StateKeyRouteReason] = decision.Reason
+	return event
+}
+
+func findSubAgent(subAgents []agent.Agent, name string) agent.Agent {
+	for _, sub := range subAgents {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routeragent
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/common"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func echoAgent(t *testing.T, name string) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        name,
+		Description: name + " specialist",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(name, genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+type fakeLLM struct {
+	answer string
+	req    *model.LLMRequest
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.req = req
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.answer, genai.RoleModel)}, nil)
+	}
+}
+
+func runRouter(t *testing.T, cfg Config, input string, state map[string]any) ([]*session.Event, error) {
+	t.Helper()
+	cfg.AgentConfig.SubAgents = []agent.Agent{echoAgent(t, "billing"), echoAgent(t, "support")}
+	router, err := New(cfg)
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: router, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", State: state})
+	require.NoError(t, err)
+
+	var events []*session.Event
+	for event, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText(input, genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func TestRouterAgent(t *testing.T) {
+	rules, err := NewRuleClassifier([]Rule{
+		{Route: "billing", Keywords: []string{"invoice", "refund"}},
+		{Route: "support", Pattern: `(?i)\berror\s+\d+`},
+		{Route: "billing", StateKey: "tier", StateValue: "enterprise"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		input     string
+		state     map[string]any
+		wantRoute string
+		wantErr   error
+	}{
+		{name: "keyword", cfg: Config{Classifier: rules}, input: "Where is my INVOICE?", wantRoute: "billing"},
+		{name: "pattern", cfg: Config{Classifier: rules}, input: "I got error 500", wantRoute: "support"},
+		{name: "state", cfg: Config{Classifier: rules}, input: "hello", state: map[string]any{"tier": "enterprise"}, wantRoute: "billing"},
+		{name: "no match", cfg: Config{Classifier: rules}, input: "hello", wantErr: ErrNoRoute},
+		{name: "no match with default", cfg: Config{Classifier: rules, DefaultRoute: "support"}, input: "hello", wantRoute: "support"},
+		{
+			name: "unknown route",
+			cfg: Config{Classifier: ClassifierFunc(func(agent.InvocationContext) (Decision, error) {
+				return Decision{Route: "sales"}, nil
+			})},
+			input:   "hello",
+			wantErr: ErrUnknownRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := runRouter(t, tt.cfg, tt.input, tt.state)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, common.DEFAULT_ROUTERAGENT_NAME, events[0].Author)
+			assert.Equal(t, tt.wantRoute, events[0].Actions.StateDelta[StateKeyRoute])
+			assert.Equal(t, tt.wantRoute, events[1].Content.Parts[0].Text, "exactly the routed sub-agent runs")
+		})
+	}
+}
+
+func TestLLMClassifier(t *testing.T) {
+	llm := &fakeLLM{answer: "```json\n{\"route\": \"support\", \"reason\": \"technical issue\"}\n```"}
+	classifier, err := NewLLMClassifier(LLMClassifierConfig{Model: llm, StateKeys: []string{"tier"}})
+	require.NoError(t, err)
+
+	events, err := runRouter(t, Config{Classifier: classifier}, "the app crashes", map[string]any{"tier": "free"})
+	require.NoError(t, err)
+	assert.Equal(t, "technical issue", events[0].Actions.StateDelta[StateKeyRouteReason])
+	assert.Equal(t, "support", events[1].Content.Parts[0].Text)
+
+	assert.Equal(t, "application/json", llm.req.Config.ResponseMIMEType)
+	assert.Equal(t, []string{"billing", "support"}, llm.req.Config.ResponseSchema.Properties["route"].Enum)
+	assert.Contains(t, llm.req.Contents[0].Parts[0].Text, "tier: free")
+	assert.Contains(t, llm.req.Config.SystemInstruction.Parts[0].Text, "billing: billing specialist")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorIs(t, err, ErrNoClassifier)
+
+	classifier := ClassifierFunc(func(agent.InvocationContext) (Decision, error) { return Decision{}, nil })
+	_, err = New(Config{Classifier: classifier})
+	assert.ErrorIs(t, err, ErrNoSubAgents)
+
+	_, err = New(Config{
+		AgentConfig:  agent.Config{SubAgents: []agent.Agent{echoAgent(t, "billing")}},
+		Classifier:   classifier,
+		DefaultRoute: "sales",
+	})
+	assert.ErrorIs(t, err, ErrInvalidDefault)
+
+	_, err = NewRuleClassifier([]Rule{{Route: "billing", Pattern: "("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routeragent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/volcengine/veadk-go/agent/workflowagents/internal/workflow"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Decision is the outcome of a classifier.
+type Decision struct {
+	// Route is the name of the sub-agent to run.
+	Route string `json:"route"`
+	// Reason optionally explains the decision.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Classifier decides which sub-agent handles the invocation.
+type Classifier interface {
+	Classify(ctx agent.InvocationContext) (Decision, error)
+}
+
+// ClassifierFunc adapts a Go func to a Classifier.
+type ClassifierFunc func(ctx agent.InvocationContext) (Decision, error)
+
+func (f ClassifierFunc) Classify(ctx agent.InvocationContext) (Decision, error) {
+	return f(ctx)
+}
+
+// UserInput returns the text parts of the user content that started the invocation.
+func UserInput(ctx agent.InvocationContext) string {
+	return workflow.UserText(ctx)
+}
+
+// Rule routes to Route when all of its conditions hold. Unset conditions are ignored.
+type Rule struct {
+	Route string
+
+	// Keywords holds when the user input contains any of them, case-insensitively.
+	Keywords []string
+	// Pattern holds when the regular expression matches the user input.
+	Pattern string
+	// StateKey holds when the session state has the key, and, if StateValue is set, equals it.
+	StateKey   string
+	StateValue any
+}
+
+type compiledRule struct {
+	Rule
+	keywords []string
+	pattern  *regexp.Regexp
+}
+
+// RuleClassifier evaluates rules in order and returns the first match.
+type RuleClassifier struct {
+	rules []compiledRule
+}
+
+// NewRuleClassifier compiles a keyword/regex/state rule table.
+func NewRuleClassifier(rules []Rule) (*RuleClassifier, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Route == "" {
+			return nil, fmt.Errorf("rule %d: %w", i, ErrNoRoute)
+		}
+		c := compiledRule{Rule: rule}
+		for _, keyword := range rule.Keywords {
+			c.keywords = append(c.keywords, strings.ToLower(keyword))
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern: %w", i, err)
+			}
+			c.pattern = pattern
+		}
+		compiled = append(compiled, c)
+	}
+	return &RuleClassifier{rules: compiled}, nil
+}
+
+func (c *RuleClassifier) Classify(ctx agent.InvocationContext) (Decision, error) {
+	input := UserInput(ctx)
+	lowered := strings.ToLower(input)
+	for _, rule := range c.rules {
+		if rule.matches(ctx, input, lowered) {
+			return Decision{Route: rule.Route, Reason: "matched rule"}, nil
+		}
+	}
+	return Decision{}, ErrNoRoute
+}
+
+func (r *compiledRule) matches(ctx agent.InvocationContext, input, lowered string) bool {
+	if len(r.keywords) > 0 {
+		found := false
+		for _, keyword := range r.keywords {
+			if strings.Contains(lowered, keyword) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.pattern != nil && !r.pattern.MatchString(input) {
+		return false
+	}
+	if r.StateKey != "" {
+		value, err := ctx.Session().State().Get(r.StateKey)
+		if err != nil {
+			return false
+		}
+		if r.StateValue != nil && !reflect.DeepEqual(value, r.StateValue) {
+			return false
+		}
+	}
+	return true
+}
+
+const defaultLLMClassifierInstruction = `You are a request router. Choose the single best route for the user request below.
+Answer with a JSON object {"route": "<route name>", "reason": "<one short sentence>"} and nothing else.`
+
+type LLMClassifierConfig struct {
+	// Model is called once per invocation; a small, cheap model is usually enough.
+	Model model.LLM
+	// Instruction replaces the default routing instruction. Route descriptions are always appended.
+	Instruction string
+	// Routes maps route names to descriptions. Defaults to the sub-agents' names and descriptions.
+	Routes map[string]string
+	// StateKeys lists session state entries included in the prompt.
+	StateKeys []string
+}
+
+// LLMClassifier asks a model for a structured routing decision.
+type LLMClassifier struct {
+	config LLMClassifierConfig
+}
+
+func NewLLMClassifier(config LLMClassifierConfig) (*LLMClassifier, error) {
+	if config.Model == nil {
+		return nil, errors.New("llm classifier requires a model")
+	}
+	if config.Instruction == "" {
+		config.Instruction = defaultLLMClassifierInstruction
+	}
+	return &LLMClassifier{config: config}, nil
+}
+
+func (c *LLMClassifier) Classify(ctx agent.InvocationContext) (Decision, error) {
+	routes := c.routes(ctx)
+	names := make([]string, 0, len(routes))
+	var system strings.Builder
+	system.WriteString(c.config.Instruction)
+	system.WriteString("\n\nRoutes:\n")
+	for _, route := range routes {
+		names = append(names, route.name)
+		fmt.Fprintf(&system, "- %s: %s\n", route.name, route.description)
+	}
+
+	var prompt strings.Builder
+	if len(c.config.StateKeys) > 0 {
+		prompt.WriteString("Session state:\n")
+		for _, key := range c.config.StateKeys {
+			if value, err := ctx.Session().State().Get(key); err == nil {
+				fmt.Fprintf(&prompt, "- %s: %v\n", key, value)
+			}
+		}
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("User request:\n")
+	prompt.WriteString(UserInput(ctx))
+
+	req := &model.LLMRequest{
+		Model:    c.config.Model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(prompt.String(), genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(system.String(), genai.RoleUser),
+			Temperature:       genai.Ptr[float32](0),
+			ResponseMIMEType:  "application/json",
+			ResponseSchema: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"route":  {Type: genai.TypeString, Enum: names},
+					"reason": {Type: genai.TypeString},
+				},
+				Required: []string{"route"},
+			},
+		},
+	}
+
+	text, err := workflow.GenerateText(ctx, c.config.Model, req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("llm classifier failed: %w", err)
+	}
+	return parseDecision(text)
+}
+
+type routeDescription struct {
+	name        string
+	description string
+}
+
+func (c *LLMClassifier) routes(ctx agent.InvocationContext) []routeDescription {
+	var routes []routeDescription
+	if len(c.config.Routes) > 0 {
+		for _, sub := range ctx.Agent().SubAgents() {
+			if description, ok := c.config.Routes[sub.Name()]; ok {
+				routes = append(routes, routeDescription{name: sub.Name(), description: description})
+			}
+		}
+		return routes
+	}
+	for _, sub := range ctx.Agent().SubAgents() {
+		routes = append(routes, routeDescription{name: sub.Name(), description: sub.Description()})
+	}
+	return routes
+}
+
+// parseDecision decodes a model answer, tolerating markdown code fences around the JSON.
+func parseDecision(text string) (Decision, error) {
+	text = workflow.TrimCodeFence(text)
+
+	var decision Decision
+	if err := json.Unmarshal([]byte(text), &decision); err != nil {
+		return Decision{}, fmt.Errorf("llm classifier returned invalid decision %q: %w", text, err)
+	}
+	return decision, nil
+}
@@ -16,6 +16,7 @@ package sequentialagent
 
 import (
 	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/observability"
 	"github.com/volcengine/veadk-go/prompts"
 	"google.golang.org/adk/agent"
 	googleADKSequentialAgent "google.golang.org/adk/agent/workflowagents/sequentialagent"
@@ -25,8 +26,6 @@ import (
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
-
-	//TODO: add tracers
 }
 
 // New creates a SequentialAgent.
@@ -43,6 +42,7 @@ func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Description == "" {
 		cfg.AgentConfig.Description = prompts.DEFAULT_DESCRIPTION
 	}
+	cfg.AgentConfig.BeforeAgentCallbacks = append([]agent.BeforeAgentCallback{observability.WorkflowTypeCallback(observability.WorkflowTypeSequential)}, cfg.AgentConfig.BeforeAgentCallbacks...)
 
 	return googleADKSequentialAgent.New(googleADKSequentialAgent.Config{
 		AgentConfig: cfg.AgentConfig,
@@ -96,12 +96,21 @@ func (a *agentkitServerApp) SetupRouters(router *mux.Router, config *apps.RunCon
 	// Wrap it with CORS middleware
 	corsHandler := corsWithArgs(a.GetWebUrl())(apiHandler)
 
+	// setup session fork, rewind, export and import routers, before the ADK REST API takes over the prefix
+	apps.SetupSessionRouters(router, a.ApiConfig, config).Use(corsWithArgs(a.GetWebUrl()))
+	apps.SetupMemoryAdminRouters(router, a.ApiConfig, config)
+
 	router.Methods("GET", "POST", "DELETE", "OPTIONS").PathPrefix(fmt.Sprintf("%s/", a.ApiPathPrefix)).Handler(
 		http.StripPrefix(a.ApiPathPrefix, corsHandler),
 	)
 
 	log.Infof("       api:  you can access API using %s", a.GetAPIPath())
 	log.Infof("       api:      for instance: %s/list-apps", a.GetAPIPath())
+	if a.AdminToken != "" {
+		log.Infof("  sessions:  you can fork, rewind, export and import sessions using %s/apps/{app_name}/users/{user_id}/sessions/{session_id}/fork", a.GetAPIPath())
+	} else {
+		log.Infof("  sessions:  you can export sessions using %s/apps/{app_name}/users/{user_id}/sessions/{session_id}/export", a.GetAPIPath())
+	}
 
 	return nil
 }
@@ -71,6 +71,8 @@ type ApiConfig struct {
 	IdleTimeout     time.Duration
 	SEEWriteTimeout time.Duration
 	ApiPathPrefix   string
+	// AdminToken enables the admin routes, which require it as a bearer token.
+	AdminToken string
 }
 
 type BasicApp interface {
@@ -121,6 +123,11 @@ func (a *ApiConfig) SetApiPathPrefix(p string) *ApiConfig {
 	return a
 }
 
+func (a *ApiConfig) SetAdminToken(token string) *ApiConfig {
+	a.AdminToken = token
+	return a
+}
+
 func (a *ApiConfig) GetWebUrl() string {
 	return fmt.Sprintf("http://localhost:%d", a.Port)
 }
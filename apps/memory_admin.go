@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/volcengine/veadk-go/log"
+	ltm "github.com/volcengine/veadk-go/memory/long_term_memory_backends"
+)
+
+type AdminResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// SetupMemoryAdminRouters lets operators see and delete what the agent remembers about users:
+//
+//	GET    /admin/memories/users/{user_id}?page=1&page_size=20
+//	DELETE /admin/memories/users/{user_id}
+//	DELETE /admin/memories/{memory_id}
+//
+// The routes are only registered when ApiConfig.AdminToken is set and the memory service implements
+// long_term_memory_backends.MemoryManager.
+func SetupMemoryAdminRouters(router *mux.Router, apiConfig *ApiConfig, config *RunConfig) {
+	if apiConfig.AdminToken == "" || config.MemoryService == nil {
+		return
+	}
+	manager, ok := config.MemoryService.(ltm.MemoryManager)
+	if !ok {
+		log.Warnf("memory admin routes disabled: memory service %T cannot list or delete memories", config.MemoryService)
+		return
+	}
+
+	admin := router.PathPrefix("/admin/memories").Subrouter()
+	admin.Use(adminAuth(apiConfig.AdminToken))
+	admin.Path("/users/{user_id}").Methods(http.MethodGet).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := ltm.Page{}
+		page.Number, _ = strconv.Atoi(r.URL.Query().Get("page"))
+		page.Size, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+		memories, err := manager.ListMemories(r.Context(), mux.Vars(r)["user_id"], page)
+		if err != nil {
+			writeAdminResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		writeAdminResponse(w, http.StatusOK, "success", memories)
+	})
+	admin.Path("/users/{user_id}").Methods(http.MethodDelete).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := mux.Vars(r)["user_id"]
+		if err := manager.DeleteUserMemories(r.Context(), userID); err != nil {
+			writeAdminResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		log.Info("deleted user memories", "UserID", userID)
+		writeAdminResponse(w, http.StatusOK, "success", nil)
+	})
+	admin.Path("/{memory_id}").Methods(http.MethodDelete).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		memoryID := mux.Vars(r)["memory_id"]
+		if err := manager.DeleteMemory(r.Context(), memoryID); err != nil {
+			writeAdminResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		log.Info("deleted memory", "MemoryID", memoryID)
+		writeAdminResponse(w, http.StatusOK, "success", nil)
+	})
+
+	log.Infof("       admin:  you can manage user memories using %s/admin/memories/users/{user_id}", apiConfig.GetWebUrl())
+}
+
+func adminAuth(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				writeAdminResponse(w, http.StatusUnauthorized, "invalid admin token", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeAdminResponse(w http.ResponseWriter, code int, message string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(AdminResponse{Code: code, Message: message, Data: data})
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ltm "github.com/volcengine/veadk-go/memory/long_term_memory_backends"
+	"google.golang.org/adk/memory"
+)
+
+type fakeMemory struct {
+	memory.Service
+	page    ltm.Page
+	deleted []string
+}
+
+func (m *fakeMemory) ListMemories(ctx context.Context, userId string, page ltm.Page) ([]*ltm.MemItem, error) {
+	m.page = page
+	return []*ltm.MemItem{{ID: "mem1", Content: userId + " likes tea"}}, nil
+}
+
+func (m *fakeMemory) DeleteMemory(ctx context.Context, id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func (m *fakeMemory) DeleteUserMemories(ctx context.Context, userId string) error {
+	m.deleted = append(m.deleted, "user:"+userId)
+	return nil
+}
+
+func TestMemoryAdminRouters(t *testing.T) {
+	fake := &fakeMemory{}
+	router := mux.NewRouter()
+	SetupMemoryAdminRouters(router, DefaultApiConfig().SetAdminToken("secret"), &RunConfig{MemoryService: fake})
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/memories/users/alice", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodDelete, "/admin/memories/users/alice", "wrong").Code)
+	assert.Empty(t, fake.deleted)
+
+	rec := do(http.MethodGet, "/admin/memories/users/alice?page=2&page_size=5", "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Data []*ltm.MemItem `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "alice likes tea", resp.Data[0].Content)
+	assert.Equal(t, ltm.Page{Number: 2, Size: 5}, fake.page)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/admin/memories/mem1", "secret").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, "/admin/memories/users/alice", "secret").Code)
+	assert.Equal(t, []string{"mem1", "user:alice"}, fake.deleted)
+}
+
+func TestMemoryAdminRoutersDisabled(t *testing.T) {
+	router := mux.NewRouter()
+	SetupMemoryAdminRouters(router, DefaultApiConfig(), &RunConfig{MemoryService: &fakeMemory{}})
+	SetupMemoryAdminRouters(router, DefaultApiConfig().SetAdminToken("secret"), &RunConfig{MemoryService: memory.InMemoryService()})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/memories/users/alice", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
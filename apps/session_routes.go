@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/volcengine/veadk-go/memory/sessionutil"
+	"google.golang.org/adk/session"
+)
+
+type forkSessionRequest struct {
+	NumEvents    *int   `json:"num_events"`
+	NewSessionID string `json:"new_session_id"`
+}
+
+type rewindSessionRequest struct {
+	NumEvents *int `json:"num_events"`
+}
+
+// SetupSessionRouters adds fork, rewind, export and import routes next to the session routes of the ADK REST
+// API, answering with the transcript of the resulting session:
+//
+//	POST {prefix}/apps/{app_name}/users/{user_id}/sessions/{session_id}/fork    {"num_events": 4, "new_session_id": ""}
+//	POST {prefix}/apps/{app_name}/users/{user_id}/sessions/{session_id}/rewind  {"num_events": 4}
+//	GET  {prefix}/apps/{app_name}/users/{user_id}/sessions/{session_id}/export
+//	POST {prefix}/apps/{app_name}/users/{user_id}/sessions/{session_id}/import  <transcript>
+//
+// Fork, rewind and import write sessions, so they are only registered when ApiConfig.AdminToken is set, and
+// require it as a bearer token like the admin routes. It must be called before the ADK REST handler is mounted on
+// the prefix. The returned router holds the routes, to add middlewares.
+func SetupSessionRouters(router *mux.Router, apiConfig *ApiConfig, config *RunConfig) *mux.Router {
+	service := config.SessionService
+	sessions := router.PathPrefix(apiConfig.ApiPathPrefix + "/apps/{app_name}/users/{user_id}/sessions/{session_id}").Subrouter()
+
+	sessions.Path("/export").Methods(http.MethodGet).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		resp, err := service.Get(r.Context(), &session.GetRequest{
+			AppName:   vars["app_name"],
+			UserID:    vars["user_id"],
+			SessionID: vars["session_id"],
+		})
+		if err != nil {
+			writeTranscript(w, nil, err)
+			return
+		}
+		writeTranscript(w, resp.Session, nil)
+	})
+	if apiConfig.AdminToken == "" {
+		return sessions
+	}
+
+	rewrites := sessions.NewRoute().Subrouter()
+	rewrites.Use(adminAuth(apiConfig.AdminToken))
+	rewrites.Path("/fork").Methods(http.MethodPost).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req forkSessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.NumEvents == nil {
+			http.Error(w, "num_events is required", http.StatusBadRequest)
+			return
+		}
+		vars := mux.Vars(r)
+		fork, err := sessionutil.Fork(r.Context(), service, &sessionutil.ForkRequest{
+			AppName:      vars["app_name"],
+			UserID:       vars["user_id"],
+			SessionID:    vars["session_id"],
+			NumEvents:    *req.NumEvents,
+			NewSessionID: req.NewSessionID,
+		})
+		writeTranscript(w, fork, err)
+	})
+	rewrites.Path("/rewind").Methods(http.MethodPost).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rewindSessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.NumEvents == nil {
+			http.Error(w, "num_events is required", http.StatusBadRequest)
+			return
+		}
+		vars := mux.Vars(r)
+		rewound, err := sessionutil.Rewind(r.Context(), service, &sessionutil.RewindRequest{
+			AppName:   vars["app_name"],
+			UserID:    vars["user_id"],
+			SessionID: vars["session_id"],
+			NumEvents: *req.NumEvents,
+		})
+		writeTranscript(w, rewound, err)
+	})
+	rewrites.Path("/import").Methods(http.MethodPost).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var transcript sessionutil.Transcript
+		if err := json.NewDecoder(r.Body).Decode(&transcript); err != nil {
+			http.Error(w, "invalid transcript: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		vars := mux.Vars(r)
+		imported, err := sessionutil.Import(r.Context(), service, &sessionutil.ImportRequest{
+			Transcript: &transcript,
+			AppName:    vars["app_name"],
+			UserID:     vars["user_id"],
+			SessionID:  vars["session_id"],
+		})
+		writeTranscript(w, imported, err)
+	})
+	return sessions
+}
+
+func writeTranscript(w http.ResponseWriter, s session.Session, err error) {
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, sessionutil.ErrEventOutOfRange) || errors.Is(err, sessionutil.ErrUnsupportedTranscript) {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessionutil.NewTranscript(s))
+}
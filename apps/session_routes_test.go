@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apps
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/memory/sessionutil"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestSessionRouters(t *testing.T) {
+	service := session.InMemoryService()
+	created, err := service.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "alice", SessionID: "s1"})
+	require.NoError(t, err)
+	for i, text := range []string{"hello", "hi", "bye", "see you"} {
+		author := "user"
+		if i%2 == 1 {
+			author = "agent"
+		}
+		event := session.NewEvent("inv")
+		event.Author = author
+		event.Content = genai.NewContentFromText(text, genai.Role(author))
+		require.NoError(t, service.AppendEvent(t.Context(), created.Session, event))
+	}
+
+	router := mux.NewRouter()
+	SetupSessionRouters(router, DefaultApiConfig().SetAdminToken("secret"), &RunConfig{SessionService: service})
+
+	do := func(method, path, body string) (*httptest.ResponseRecorder, *sessionutil.Transcript) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			return rec, nil
+		}
+		var transcript sessionutil.Transcript
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &transcript))
+		return rec, &transcript
+	}
+	prefix := DefaultApiConfig().ApiPathPrefix + "/apps/app/users/alice/sessions"
+
+	rec, exported := do(http.MethodGet, prefix+"/s1/export", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, exported.Events, 4)
+
+	rec, forked := do(http.MethodPost, prefix+"/s1/fork", `{"num_events": 2, "new_session_id": "s2"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "s2", forked.SessionID)
+	assert.Len(t, forked.Events, 2)
+
+	rec, rewound := do(http.MethodPost, prefix+"/s1/rewind", `{"num_events": 1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "s1", rewound.SessionID)
+	assert.Len(t, rewound.Events, 1)
+
+	body, err := json.Marshal(exported)
+	require.NoError(t, err)
+	rec, imported := do(http.MethodPost, prefix+"/s3/import", string(body))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "s3", imported.SessionID)
+	assert.Len(t, imported.Events, 4)
+
+	rec, _ = do(http.MethodPost, prefix+"/s1/rewind", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = do(http.MethodPost, prefix+"/s1/fork", `{"num_events": 9}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = do(http.MethodPost, prefix+"/s4/import", `{"version": 99}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = do(http.MethodGet, prefix+"/missing/export", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, prefix+"/s1/rewind", strings.NewReader(`{"num_events": 0}`)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, prefix+"/s1/export", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "export does not need the admin token")
+}
+
+func TestSessionRoutersWithoutAdminToken(t *testing.T) {
+	router := mux.NewRouter()
+	SetupSessionRouters(router, DefaultApiConfig(), &RunConfig{SessionService: session.InMemoryService()})
+	prefix := DefaultApiConfig().ApiPathPrefix + "/apps/app/users/alice/sessions/s1"
+	for _, route := range []string{"/fork", "/rewind", "/import"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, prefix+route, strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusNotFound, rec.Code, route)
+	}
+}
@@ -89,6 +89,8 @@ func (a *agentkitSimpleApp) SetupRouters(router *mux.Router, config *apps.RunCon
 	log.Infof("       invoke:  you can invoke agent using %s/invoke", a.GetWebUrl())
 	log.Infof("       health:  you can get health status using: %s/health", a.GetWebUrl())
 
+	apps.SetupMemoryAdminRouters(router, a.ApiConfig, config)
+
 	return nil
 }
 
@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	PluginName = "veadk-budget"
+
+	DefaultExceededMessage = "The usage budget for this request has been exhausted, please try again later."
+	DefaultSoftLimitRatio  = 0.8
+
+	// StateKeyExceeded records which limit stopped the current invocation.
+	StateKeyExceeded = session.KeyPrefixTemp + "veadk.budget.exceeded"
+	// stateKeyInvocationID carries the runner invocation ID to model callbacks, whose contexts are
+	// re-created by every LLM agent with a fresh invocation ID.
+	stateKeyInvocationID = session.KeyPrefixTemp + "veadk.budget.invocation_id"
+	// metadataKeyExceeded marks the responses that short-circuit a model call with the limit reached.
+	metadataKeyExceeded = "veadk.budget.exceeded"
+
+	invocationTTL = 24 * time.Hour
+)
+
+var ErrInvalidLimit = errors.New("budget limits must not be negative")
+
+// Limit names a budget dimension.
+type Limit string
+
+const (
+	LimitInvocationTokens Limit = "invocation_tokens"
+	LimitInvocationCalls  Limit = "invocation_llm_calls"
+	LimitUserDailyTokens  Limit = "user_daily_tokens"
+)
+
+type Config struct {
+	// MaxTokensPerInvocation is the hard limit of total tokens in one invocation. Zero disables it.
+	MaxTokensPerInvocation int64
+	// MaxLLMCallsPerInvocation is the hard limit of model calls in one invocation. Zero disables it.
+	MaxLLMCallsPerInvocation int64
+	// DailyTokenQuotaPerUser is the hard limit of total tokens per app user and day. Zero disables it.
+	DailyTokenQuotaPerUser int64
+
+	// SoftLimitRatio is the fraction of a hard limit at which OnSoftLimit fires, once per invocation and limit.
+	SoftLimitRatio float64
+	OnSoftLimit    func(ctx agent.CallbackContext, limit Limit, used, max int64)
+
+	// ExceededMessage is returned as the model response when a hard limit is reached.
+	ExceededMessage string
+	// Store keeps the counters. Defaults to a MemoryStore.
+	Store Store
+	// Location decides where a day starts for daily quotas. Defaults to time.Local.
+	Location *time.Location
+}
+
+type budgetPlugin struct {
+	config Config
+	now    func() time.Time
+
+	// exhausted holds invocation IDs that hit a hard limit.
+	exhausted sync.Map
+	// escalated holds invocation IDs whose budget event escalated.
+	escalated sync.Map
+	// softNotified holds "<invocationID>/<limit>" already reported to OnSoftLimit.
+	softNotified sync.Map
+}
+
+// NewPlugin creates a plugin enforcing token and call budgets.
+//
+// When a hard limit is reached, BeforeModel short-circuits with ExceededMessage, and the first of these budget
+// events of the invocation escalates, so that LoopAgents (including those with MaxIterations == 0) stop instead of
+// spinning on short-circuited turns. Model and tool events are left as they are.
+func NewPlugin(config Config) (*plugin.Plugin, error) {
+	if config.MaxTokensPerInvocation < 0 || config.MaxLLMCallsPerInvocation < 0 || config.DailyTokenQuotaPerUser < 0 {
+		return nil, ErrInvalidLimit
+	}
+	if config.SoftLimitRatio <= 0 || config.SoftLimitRatio > 1 {
+		config.SoftLimitRatio = DefaultSoftLimitRatio
+	}
+	if config.ExceededMessage == "" {
+		config.ExceededMessage = DefaultExceededMessage
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+
+	p := &budgetPlugin{config: config, now: time.Now}
+	return plugin.New(plugin.Config{
+		Name:                PluginName,
+		BeforeModelCallback: p.beforeModel,
+		AfterModelCallback:  p.afterModel,
+		OnEventCallback:     p.onEvent,
+		BeforeRunCallback:   p.beforeRun,
+		AfterRunCallback:    p.afterRun,
+	})
+}
+
+func invocationKey(invocationID string) string {
+	return "invocation:" + invocationID
+}
+
+// invocationID returns the ID of the runner invocation the callback belongs to.
+func invocationID(ctx agent.CallbackContext) string {
+	if id, _ := ctx.State().Get(stateKeyInvocationID); id != nil {
+		if s, ok := id.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ctx.InvocationID()
+}
+
+func (p *budgetPlugin) dailyKey(appName, userID string) (string, time.Time) {
+	now := p.now().In(p.config.Location)
+	year, month, day := now.Date()
+	tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, p.config.Location)
+	return fmt.Sprintf("user:%s:%s:%s", appName, userID, now.Format(time.DateOnly)), tomorrow
+}
+
+func (p *budgetPlugin) beforeModel(ctx agent.CallbackContext, req *model.LLMRequest) (*model.LLMResponse, error) {
+	id := invocationID(ctx)
+	if limit, ok := p.exhausted.Load(id); ok {
+		return p.exceededResponse(limit.(Limit)), nil
+	}
+
+	limit, err := p.check(ctx, id)
+	if err != nil {
+		// Budgets fail open: an unavailable store must not take the agent down.
+		log.Warn("budget check failed", "error", err)
+		return nil, nil
+	}
+	if limit != "" {
+		log.Warn("budget exceeded", "limit", string(limit), "InvocationID", id, "UserID", ctx.UserID())
+		p.exhausted.Store(id, limit)
+		_ = ctx.State().Set(StateKeyExceeded, string(limit))
+		return p.exceededResponse(limit), nil
+	}
+
+	if _, err := p.config.Store.Add(ctx, invocationKey(id), Usage{Calls: 1}, p.now().Add(invocationTTL)); err != nil {
+		log.Warn("budget record call failed", "error", err)
+	}
+	return nil, nil
+}
+
+// check returns the first hard limit already reached, if any.
+func (p *budgetPlugin) check(ctx agent.CallbackContext, id string) (Limit, error) {
+	if p.config.MaxTokensPerInvocation > 0 || p.config.MaxLLMCallsPerInvocation > 0 {
+		usage, err := p.config.Store.Get(ctx, invocationKey(id))
+		if err != nil {
+			return "", err
+		}
+		if p.config.MaxLLMCallsPerInvocation > 0 && usage.Calls >= p.config.MaxLLMCallsPerInvocation {
+			return LimitInvocationCalls, nil
+		}
+		if p.config.MaxTokensPerInvocation > 0 && usage.Tokens >= p.config.MaxTokensPerInvocation {
+			return LimitInvocationTokens, nil
+		}
+	}
+	if p.config.DailyTokenQuotaPerUser > 0 {
+		key, _ := p.dailyKey(ctx.AppName(), ctx.UserID())
+		usage, err := p.config.Store.Get(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if usage.Tokens >= p.config.DailyTokenQuotaPerUser {
+			return LimitUserDailyTokens, nil
+		}
+	}
+	return "", nil
+}
+
+func (p *budgetPlugin) afterModel(ctx agent.CallbackContext, resp *model.LLMResponse, err error) (*model.LLMResponse, error) {
+	if err != nil || resp == nil || resp.Partial || resp.UsageMetadata == nil {
+		return nil, nil
+	}
+	tokens := int64(resp.UsageMetadata.TotalTokenCount)
+	if tokens == 0 {
+		tokens = int64(resp.UsageMetadata.PromptTokenCount) + int64(resp.UsageMetadata.CandidatesTokenCount)
+	}
+	if tokens == 0 {
+		return nil, nil
+	}
+
+	id := invocationID(ctx)
+	invocationUsage, addErr := p.config.Store.Add(ctx, invocationKey(id), Usage{Tokens: tokens}, p.now().Add(invocationTTL))
+	if addErr != nil {
+		log.Warn("budget record tokens failed", "error", addErr)
+	} else {
+		p.notifySoftLimit(ctx, id, LimitInvocationTokens, invocationUsage.Tokens, p.config.MaxTokensPerInvocation)
+		p.notifySoftLimit(ctx, id, LimitInvocationCalls, invocationUsage.Calls, p.config.MaxLLMCallsPerInvocation)
+	}
+
+	key, expireAt := p.dailyKey(ctx.AppName(), ctx.UserID())
+	dailyUsage, addErr := p.config.Store.Add(ctx, key, Usage{Tokens: tokens, Calls: 1}, expireAt)
+	if addErr != nil {
+		log.Warn("budget record daily tokens failed", "error", addErr)
+	} else {
+		p.notifySoftLimit(ctx, id, LimitUserDailyTokens, dailyUsage.Tokens, p.config.DailyTokenQuotaPerUser)
+	}
+	return nil, nil
+}
+
+func (p *budgetPlugin) notifySoftLimit(ctx agent.CallbackContext, id string, limit Limit, used, max int64) {
+	if max <= 0 || float64(used) < float64(max)*p.config.SoftLimitRatio {
+		return
+	}
+	if _, loaded := p.softNotified.LoadOrStore(id+"/"+string(limit), struct{}{}); loaded {
+		return
+	}
+	log.Warn("budget soft limit reached", "limit", string(limit), "used", used, "max", max, "UserID", ctx.UserID())
+	if p.config.OnSoftLimit != nil {
+		p.config.OnSoftLimit(ctx, limit, used, max)
+	}
+}
+
+// onEvent escalates the first budget event of an exhausted invocation. Events are shared pointers, so the flag is
+// seen by the enclosing LoopAgent right after it yields the event.
+func (p *budgetPlugin) onEvent(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+	if event == nil || event.CustomMetadata[metadataKeyExceeded] == nil {
+		return nil, nil
+	}
+	if _, loaded := p.escalated.LoadOrStore(ctx.InvocationID(), struct{}{}); !loaded {
+		event.Actions.Escalate = true
+	}
+	return nil, nil
+}
+
+func (p *budgetPlugin) beforeRun(ctx agent.InvocationContext) (*genai.Content, error) {
+	_ = ctx.Session().State().Set(stateKeyInvocationID, ctx.InvocationID())
+	return nil, nil
+}
+
+func (p *budgetPlugin) afterRun(ctx agent.InvocationContext) {
+	invocationID := ctx.InvocationID()
+	p.exhausted.Delete(invocationID)
+	p.escalated.Delete(invocationID)
+	for _, limit := range []Limit{LimitInvocationTokens, LimitInvocationCalls, LimitUserDailyTokens} {
+		p.softNotified.Delete(invocationID + "/" + string(limit))
+	}
+	if err := p.config.Store.Delete(context.Context(ctx), invocationKey(invocationID)); err != nil {
+		log.Warn("budget cleanup failed", "error", err)
+	}
+}
+
+func (p *budgetPlugin) exceededResponse(limit Limit) *model.LLMResponse {
+	return &model.LLMResponse{
+		CustomMetadata: map[string]any{metadataKeyExceeded: string(limit)},
+		Content: &genai.Content{
+			Role: "model",
+			Parts: []*genai.Part{
+				{Text: p.config.ExceededMessage},
+			},
+		},
+		Partial:      false,
+		FinishReason: "STOP",
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type fakeLLM struct {
+	calls  int
+	tokens int32
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.calls++
+		yield(&model.LLMResponse{
+			Content:       genai.NewContentFromText("again", genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{TotalTokenCount: m.tokens},
+		}, nil)
+	}
+}
+
+func runLoop(t *testing.T, llm model.LLM, p *plugin.Plugin, userID string, sessions session.Service) []*session.Event {
+	t.Helper()
+	worker, err := llmagent.New(llmagent.Config{Name: "worker", Model: llm})
+	require.NoError(t, err)
+	loop, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{Name: "loop", SubAgents: []agent.Agent{worker}},
+	})
+	require.NoError(t, err)
+
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          loop,
+		SessionService: sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{p}},
+	})
+	require.NoError(t, err)
+
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: userID})
+	require.NoError(t, err)
+
+	var events []*session.Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event, err := range r.Run(t.Context(), userID, created.Session.ID(), genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+			assert.NoError(t, err)
+			events = append(events, event)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("loop agent was not stopped by the budget plugin, events=%d", len(events))
+	}
+	return events
+}
+
+func TestPluginStopsUnboundedLoop(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		wantCalls int
+	}{
+		{
+			name:      "max calls per invocation",
+			config:    Config{MaxLLMCallsPerInvocation: 3},
+			wantCalls: 3,
+		},
+		{
+			name:      "max tokens per invocation",
+			config:    Config{MaxTokensPerInvocation: 25},
+			wantCalls: 3,
+		},
+		{
+			name:      "daily quota per user",
+			config:    Config{DailyTokenQuotaPerUser: 40},
+			wantCalls: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.ExceededMessage = "out of budget"
+			p, err := NewPlugin(tt.config)
+			require.NoError(t, err)
+
+			llm := &fakeLLM{tokens: 10}
+			events := runLoop(t, llm, p, "user", session.InMemoryService())
+
+			assert.Equal(t, tt.wantCalls, llm.calls)
+			last := events[len(events)-1]
+			assert.Equal(t, "out of budget", last.Content.Parts[0].Text)
+			assert.True(t, last.Actions.Escalate)
+			for _, event := range events[:len(events)-1] {
+				assert.False(t, event.Actions.Escalate, "only the budget event escalates")
+			}
+		})
+	}
+}
+
+func TestPluginDailyQuotaSpansInvocations(t *testing.T) {
+	store := NewMemoryStore()
+	p, err := NewPlugin(Config{DailyTokenQuotaPerUser: 20, MaxLLMCallsPerInvocation: 1, Store: store})
+	require.NoError(t, err)
+	sessions := session.InMemoryService()
+
+	llm := &fakeLLM{tokens: 10}
+	runLoop(t, llm, p, "alice", sessions)
+	runLoop(t, llm, p, "alice", sessions)
+	runLoop(t, llm, p, "alice", sessions)
+	assert.Equal(t, 2, llm.calls, "third invocation must be rejected by the daily quota")
+
+	runLoop(t, llm, p, "bob", sessions)
+	assert.Equal(t, 3, llm.calls, "quota is per user")
+}
+
+func TestPluginSoftLimit(t *testing.T) {
+	var notified []Limit
+	p, err := NewPlugin(Config{
+		MaxLLMCallsPerInvocation: 4,
+		SoftLimitRatio:           0.5,
+		OnSoftLimit: func(ctx agent.CallbackContext, limit Limit, used, max int64) {
+			notified = append(notified, limit)
+		},
+	})
+	require.NoError(t, err)
+
+	runLoop(t, &fakeLLM{tokens: 1}, p, "user", session.InMemoryService())
+	assert.Equal(t, []Limit{LimitInvocationCalls}, notified)
+}
+
+func TestPluginEscalatesOnce(t *testing.T) {
+	p, err := NewPlugin(Config{MaxLLMCallsPerInvocation: 1, ExceededMessage: "out of budget"})
+	require.NoError(t, err)
+	llm := &fakeLLM{tokens: 1}
+	newWorker := func(name string) agent.Agent {
+		worker, err := llmagent.New(llmagent.Config{Name: name, Model: llm})
+		require.NoError(t, err)
+		return worker
+	}
+	loop, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{Name: "loop", SubAgents: []agent.Agent{newWorker("worker")}},
+	})
+	require.NoError(t, err)
+	reporter, err := agent.New(agent.Config{
+		Name: "reporter",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "reporter"
+				event.Content = genai.NewContentFromText("report", genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	// pipeline runs its sub-agents in turn whether they escalate or not
+	pipeline, err := agent.New(agent.Config{
+		Name:      "pipeline",
+		SubAgents: []agent.Agent{loop, newWorker("summarizer"), reporter},
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				for _, sub := range ctx.Agent().SubAgents() {
+					for event, err := range sub.Run(ctx) {
+						if !yield(event, err) {
+							return
+						}
+					}
+				}
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          pipeline,
+		SessionService: sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{p}},
+	})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	var texts []string
+	var escalated []bool
+	for event, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{}) {
+		require.NoError(t, err)
+		texts = append(texts, event.Content.Parts[0].Text)
+		escalated = append(escalated, event.Actions.Escalate)
+	}
+	assert.Equal(t, []string{"again", "out of budget", "out of budget", "report"}, texts)
+	assert.Equal(t, []bool{false, true, false, false}, escalated, "only the first budget event escalates")
+}
+
+func TestNewPluginInvalidLimit(t *testing.T) {
+	_, err := NewPlugin(Config{MaxTokensPerInvocation: -1})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	usage, err := store.Add(ctx, "k", Usage{Tokens: 5, Calls: 1}, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Tokens: 5, Calls: 1}, usage)
+
+	usage, _ = store.Add(ctx, "k", Usage{Tokens: 5}, now.Add(time.Minute))
+	assert.Equal(t, Usage{Tokens: 10, Calls: 1}, usage)
+
+	now = now.Add(time.Minute)
+	usage, _ = store.Get(ctx, "k")
+	assert.Equal(t, Usage{}, usage, "expired entries are dropped")
+
+	_, _ = store.Add(ctx, "k", Usage{Tokens: 1}, now.Add(time.Minute))
+	assert.NoError(t, store.Delete(ctx, "k"))
+	usage, _ = store.Get(ctx, "k")
+	assert.Equal(t, Usage{}, usage)
+
+	// keys of past days are swept by later adds, even if never read again
+	_, _ = store.Add(ctx, "day1", Usage{Tokens: 1}, now.Add(time.Hour))
+	now = now.Add(2 * time.Hour)
+	_, _ = store.Add(ctx, "day2", Usage{Tokens: 1}, now.Add(time.Hour))
+	assert.NotContains(t, store.entries, "day1")
+	assert.Contains(t, store.entries, "day2")
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/log"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type budgetUsage struct {
+	Key       string    `gorm:"column:budget_key;primaryKey;size:512"`
+	Tokens    int64     `gorm:"not null;default:0"`
+	Calls     int64     `gorm:"not null;default:0"`
+	ExpireAt  time.Time `gorm:"index"`
+	UpdatedAt time.Time
+}
+
+func (budgetUsage) TableName() string {
+	return "veadk_budget_usage"
+}
+
+// PostgreSQLStore is a Store shared by all replicas connected to the same database.
+type PostgreSQLStore struct {
+	db *gorm.DB
+}
+
+// NewPostgreSQLStore connects with config, or with the global database.postgresql config when config is nil,
+// and migrates the usage table.
+func NewPostgreSQLStore(config *configs.CommonDatabaseConfig) (*PostgreSQLStore, error) {
+	if config == nil {
+		config = configs.GetGlobalConfig().Database.Postgresql
+	}
+	db, err := gorm.Open(
+		postgres.Open(config.PostgresqlURL()),
+		&gorm.Config{PrepareStmt: true, Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("open budget store failed: %w", err)
+	}
+	return NewPostgreSQLStoreWithDB(db)
+}
+
+// NewPostgreSQLStoreWithDB uses an existing gorm connection.
+func NewPostgreSQLStoreWithDB(db *gorm.DB) (*PostgreSQLStore, error) {
+	if err := db.AutoMigrate(&budgetUsage{}); err != nil {
+		return nil, fmt.Errorf("auto migrate budget store failed: %w", err)
+	}
+	return &PostgreSQLStore{db: db}, nil
+}
+
+func (s *PostgreSQLStore) Get(ctx context.Context, key string) (Usage, error) {
+	var row budgetUsage
+	err := s.db.WithContext(ctx).
+		Where("budget_key = ? AND expire_at > ?", key, time.Now()).
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Usage{}, nil
+		}
+		return Usage{}, err
+	}
+	return Usage{Tokens: row.Tokens, Calls: row.Calls}, nil
+}
+
+func (s *PostgreSQLStore) Add(ctx context.Context, key string, delta Usage, expireAt time.Time) (Usage, error) {
+	var usage Usage
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Expired rows start over from zero.
+		if err := tx.Where("budget_key = ? AND expire_at <= ?", key, now).Delete(&budgetUsage{}).Error; err != nil {
+			return err
+		}
+		row := budgetUsage{Key: key, Tokens: delta.Tokens, Calls: delta.Calls, ExpireAt: expireAt, UpdatedAt: now}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "budget_key"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"tokens":     gorm.Expr("veadk_budget_usage.tokens + ?", delta.Tokens),
+				"calls":      gorm.Expr("veadk_budget_usage.calls + ?", delta.Calls),
+				"expire_at":  expireAt,
+				"updated_at": now,
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("budget_key = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+		usage = Usage{Tokens: row.Tokens, Calls: row.Calls}
+		return nil
+	})
+	return usage, err
+}
+
+func (s *PostgreSQLStore) Delete(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("budget_key = ?", key).Delete(&budgetUsage{}).Error
+}
+
+// PurgeExpired removes expired counters. It is safe to call periodically from a background job.
+func (s *PostgreSQLStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expire_at <= ?", time.Now()).Delete(&budgetUsage{})
+	return result.RowsAffected, result.Error
+}
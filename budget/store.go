@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Usage is the consumption recorded under a budget key.
+type Usage struct {
+	Tokens int64
+	Calls  int64
+}
+
+// Store keeps usage counters for budget keys. Implementations must make Add atomic.
+type Store interface {
+	// Get returns the usage of key, or a zero Usage when the key is unknown or expired.
+	Get(ctx context.Context, key string) (Usage, error)
+	// Add increments the usage of key by delta, sets its expiry and returns the new usage.
+	Add(ctx context.Context, key string, delta Usage, expireAt time.Time) (Usage, error)
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+}
+
+// memorySweepInterval is how often MemoryStore.Add drops the expired entries of keys that are never read again,
+// like the daily keys of past days.
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	usage    Usage
+	expireAt time.Time
+}
+
+// MemoryStore is a process-local Store. Usage is lost on restart and not shared between replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	now       func() time.Time
+	nextSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.entry(key); e != nil {
+		return e.usage, nil
+	}
+	return Usage{}, nil
+}
+
+func (s *MemoryStore) Add(_ context.Context, key string, delta Usage, expireAt time.Time) (Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	e := s.entry(key)
+	if e == nil {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	e.usage.Tokens += delta.Tokens
+	e.usage.Calls += delta.Calls
+	e.expireAt = expireAt
+	return e.usage, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// entry returns the live entry for key, dropping it when expired. Must be called with s.mu held.
+func (s *MemoryStore) entry(key string) *memoryEntry {
+	e, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !s.now().Before(e.expireAt) {
+		delete(s.entries, key)
+		return nil
+	}
+	return e
+}
+
+// sweep drops every expired entry, at most once per memorySweepInterval. Must be called with s.mu held.
+func (s *MemoryStore) sweep() {
+	now := s.now()
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(memorySweepInterval)
+	for key, e := range s.entries {
+		if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+}
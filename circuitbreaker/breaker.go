@@ -0,0 +1,367 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
+)
+
+const (
+	DefaultName                = "default"
+	DefaultWindow              = 60 * time.Second
+	DefaultMinRequests         = 10
+	DefaultErrorRateThreshold  = 0.5
+	DefaultSlowCallRate        = 1.0
+	DefaultOpenTimeout         = 30 * time.Second
+	DefaultHalfOpenMaxRequests = 1
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// OpenError is returned when a call is rejected because the circuit of its endpoint is open
+// (or half-open with all probe slots taken). It unwraps to ErrCircuitOpen.
+type OpenError struct {
+	Name       string
+	Key        string
+	State      State
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %q is %s for %q, retry after %s", e.Name, e.State, e.Key, e.RetryAfter)
+}
+
+func (e *OpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// IsOpen reports whether err was caused by an open circuit breaker.
+func IsOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
+
+type State int32
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	// Name identifies the breaker in errors and metrics.
+	Name string
+	// Window is the period over which calls are counted while closed.
+	Window time.Duration
+	// MinRequests is the number of calls within a window before the breaker may trip.
+	MinRequests int
+	// ErrorRateThreshold trips the breaker when failures / calls reaches it (0, 1].
+	ErrorRateThreshold float64
+	// SlowCallThreshold marks a call as slow when it takes at least this long. Zero disables latency tracking.
+	SlowCallThreshold time.Duration
+	// SlowCallRateThreshold trips the breaker when slow calls / calls reaches it (0, 1].
+	SlowCallRateThreshold float64
+	// OpenTimeout is how long the breaker stays open before letting probes through.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of probes allowed in half-open state; all must succeed to close.
+	HalfOpenMaxRequests int
+	// IsFailure decides whether an error counts against the endpoint. Defaults to any non-nil error
+	// except context cancellation. A cancelled call that is not a failure is not counted at all.
+	IsFailure func(err error) bool
+	// OnStateChange is called after every transition, in addition to the exported metric. It is called
+	// without the breaker lock held, so it may use the breaker.
+	OnStateChange func(key string, from, to State)
+}
+
+type stateChange struct {
+	key      string
+	from, to State
+}
+
+type counts struct {
+	requests  int
+	failures  int
+	slowCalls int
+}
+
+type endpoint struct {
+	state       State
+	counts      counts
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int
+	successes   int
+	// probeStart is when the last half-open probe was admitted.
+	probeStart time.Time
+	// generation changes with every transition and counting window, so that outcomes of calls admitted
+	// before it are ignored.
+	generation uint64
+}
+
+// Breaker is a circuit breaker that keeps an independent state per endpoint key.
+type Breaker struct {
+	config    Config
+	mu        sync.Mutex
+	endpoints map[string]*endpoint
+	now       func() time.Time
+	// changes are the transitions made under mu, reported to OnStateChange by unlock.
+	changes []stateChange
+}
+
+func New(config Config) *Breaker {
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = DefaultMinRequests
+	}
+	if config.ErrorRateThreshold <= 0 || config.ErrorRateThreshold > 1 {
+		config.ErrorRateThreshold = DefaultErrorRateThreshold
+	}
+	if config.SlowCallRateThreshold <= 0 || config.SlowCallRateThreshold > 1 {
+		config.SlowCallRateThreshold = DefaultSlowCallRate
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = DefaultOpenTimeout
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = DefaultHalfOpenMaxRequests
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaultIsFailure
+	}
+	return &Breaker{
+		config:    config,
+		endpoints: make(map[string]*endpoint),
+		now:       time.Now,
+	}
+}
+
+func defaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+func (b *Breaker) Name() string {
+	return b.config.Name
+}
+
+// State returns the current state for key, advancing open -> half-open when the open timeout elapsed.
+func (b *Breaker) State(key string) State {
+	b.mu.Lock()
+	defer b.unlock()
+	ep := b.endpoint(key)
+	b.refresh(context.Background(), key, ep)
+	return ep.state
+}
+
+// Allow asks permission to make a call to key. On success the returned done func must be called
+// with the outcome of the call; calls after the first are ignored. A half-open probe whose outcome
+// is not reported within OpenTimeout is given up, so that a lost done does not keep the circuit
+// half-open. When the circuit is open an *OpenError is returned.
+func (b *Breaker) Allow(ctx context.Context, key string) (func(err error), error) {
+	b.mu.Lock()
+	ep := b.endpoint(key)
+	b.refresh(ctx, key, ep)
+
+	switch ep.state {
+	case StateOpen:
+		retryAfter := b.config.OpenTimeout - b.now().Sub(ep.openedAt)
+		b.unlock()
+		observability.RecordCircuitBreakerRejected(ctx, b.config.Name, key)
+		return nil, &OpenError{Name: b.config.Name, Key: key, State: StateOpen, RetryAfter: retryAfter}
+	case StateHalfOpen:
+		if ep.probes >= b.config.HalfOpenMaxRequests {
+			b.unlock()
+			observability.RecordCircuitBreakerRejected(ctx, b.config.Name, key)
+			return nil, &OpenError{Name: b.config.Name, Key: key, State: StateHalfOpen}
+		}
+		ep.probes++
+		ep.probeStart = b.now()
+	}
+	start := b.now()
+	generation := ep.generation
+	b.unlock()
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			b.record(ctx, key, generation, start, err)
+		})
+	}, nil
+}
+
+// Execute runs fn under the breaker for key.
+func (b *Breaker) Execute(ctx context.Context, key string, fn func() error) error {
+	done, err := b.Allow(ctx, key)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+// Reset forces the endpoint for key back to closed.
+func (b *Breaker) Reset(key string) {
+	b.mu.Lock()
+	defer b.unlock()
+	b.transition(context.Background(), key, b.endpoint(key), StateClosed)
+}
+
+// record counts the outcome of a call, unless the endpoint changed generation since the call was admitted.
+func (b *Breaker) record(ctx context.Context, key string, generation uint64, start time.Time, err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	ep := b.endpoint(key)
+	b.refresh(ctx, key, ep)
+	if ep.generation != generation {
+		return
+	}
+	failed := b.config.IsFailure(err)
+	if !failed && errors.Is(err, context.Canceled) {
+		// the caller gave up: the call says nothing about the endpoint, only its probe slot is freed
+		if ep.state == StateHalfOpen {
+			ep.probes--
+		}
+		return
+	}
+	slow := b.config.SlowCallThreshold > 0 && b.now().Sub(start) >= b.config.SlowCallThreshold
+
+	switch ep.state {
+	case StateHalfOpen:
+		if failed || slow {
+			b.transition(ctx, key, ep, StateOpen)
+			return
+		}
+		ep.successes++
+		if ep.successes >= b.config.HalfOpenMaxRequests {
+			b.transition(ctx, key, ep, StateClosed)
+		}
+	case StateClosed:
+		ep.counts.requests++
+		if failed {
+			ep.counts.failures++
+		}
+		if slow {
+			ep.counts.slowCalls++
+		}
+		if b.shouldTrip(ep.counts) {
+			b.transition(ctx, key, ep, StateOpen)
+		}
+	}
+}
+
+func (b *Breaker) shouldTrip(c counts) bool {
+	if c.requests < b.config.MinRequests {
+		return false
+	}
+	if float64(c.failures)/float64(c.requests) >= b.config.ErrorRateThreshold {
+		return true
+	}
+	return b.config.SlowCallThreshold > 0 &&
+		float64(c.slowCalls)/float64(c.requests) >= b.config.SlowCallRateThreshold
+}
+
+// endpoint must be called with b.mu held.
+func (b *Breaker) endpoint(key string) *endpoint {
+	ep, ok := b.endpoints[key]
+	if !ok {
+		ep = &endpoint{state: StateClosed, windowStart: b.now()}
+		b.endpoints[key] = ep
+	}
+	return ep
+}
+
+// refresh rolls the counting window and moves open endpoints to half-open. Must be called with b.mu held.
+func (b *Breaker) refresh(ctx context.Context, key string, ep *endpoint) {
+	now := b.now()
+	switch ep.state {
+	case StateClosed:
+		if now.Sub(ep.windowStart) >= b.config.Window {
+			ep.counts = counts{}
+			ep.windowStart = now
+			ep.generation++
+		}
+	case StateOpen:
+		if now.Sub(ep.openedAt) >= b.config.OpenTimeout {
+			b.transition(ctx, key, ep, StateHalfOpen)
+		}
+	case StateHalfOpen:
+		if ep.probes > ep.successes && now.Sub(ep.probeStart) >= b.config.OpenTimeout {
+			// probes admitted this long ago are lost: let new ones through, and ignore the old ones if they report
+			ep.probes = ep.successes
+			ep.generation++
+		}
+	}
+}
+
+// unlock releases b.mu, then reports the transitions made while it was held.
+func (b *Breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.config.OnStateChange(change.key, change.from, change.to)
+	}
+}
+
+// transition must be called with b.mu held.
+func (b *Breaker) transition(ctx context.Context, key string, ep *endpoint, to State) {
+	from := ep.state
+	if from == to {
+		return
+	}
+	now := b.now()
+	ep.state = to
+	ep.counts = counts{}
+	ep.windowStart = now
+	ep.probes = 0
+	ep.successes = 0
+	ep.generation++
+	if to == StateOpen {
+		ep.openedAt = now
+	}
+
+	log.Warn("circuit breaker state changed", "name", b.config.Name, "key", key, "from", from.String(), "to", to.String())
+	observability.RecordCircuitBreakerStateChange(ctx, b.config.Name, key, from.String(), to.String())
+	b.changes = append(b.changes, stateChange{key: key, from: from, to: to})
+}
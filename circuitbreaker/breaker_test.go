@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestBreaker(cfg Config) (*Breaker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	b := New(cfg)
+	b.now = clock.now
+	return b, clock
+}
+
+func call(b *Breaker, key string, err error) error {
+	return b.Execute(context.Background(), key, func() error { return err })
+}
+
+func TestBreakerTripsOnErrorRate(t *testing.T) {
+	b, _ := newTestBreaker(Config{MinRequests: 4, ErrorRateThreshold: 0.5})
+	boom := errors.New("boom")
+
+	assert.NoError(t, call(b, "a", nil))
+	assert.NoError(t, call(b, "a", nil))
+	assert.Error(t, call(b, "a", boom))
+	assert.Equal(t, StateClosed, b.State("a"))
+	assert.Error(t, call(b, "a", boom))
+	assert.Equal(t, StateOpen, b.State("a"))
+
+	err := call(b, "a", nil)
+	assert.True(t, IsOpen(err))
+	var openErr *OpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.Equal(t, "a", openErr.Key)
+
+	// other keys are unaffected
+	assert.Equal(t, StateClosed, b.State("b"))
+	assert.NoError(t, call(b, "b", nil))
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		probeErr error
+		want     State
+	}{
+		{name: "probe succeeds", probeErr: nil, want: StateClosed},
+		{name: "probe fails", probeErr: errors.New("boom"), want: StateOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transitions []State
+			b, clock := newTestBreaker(Config{
+				MinRequests: 1,
+				OpenTimeout: time.Second,
+				OnStateChange: func(key string, from, to State) {
+					transitions = append(transitions, to)
+				},
+			})
+			assert.Error(t, call(b, "a", errors.New("boom")))
+			assert.Equal(t, StateOpen, b.State("a"))
+
+			clock.advance(time.Second)
+			assert.Equal(t, StateHalfOpen, b.State("a"))
+
+			done, err := b.Allow(context.Background(), "a")
+			assert.NoError(t, err)
+			_, err = b.Allow(context.Background(), "a")
+			assert.True(t, IsOpen(err), "only one probe allowed")
+
+			done(tt.probeErr)
+			assert.Equal(t, tt.want, b.State("a"))
+			assert.Equal(t, []State{StateOpen, StateHalfOpen, tt.want}, transitions)
+		})
+	}
+}
+
+func TestBreakerIgnoresStaleOutcomes(t *testing.T) {
+	b, clock := newTestBreaker(Config{MinRequests: 1, OpenTimeout: time.Second})
+
+	// admitted while closed, finishes after the circuit opened and went half-open
+	late, err := b.Allow(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Error(t, call(b, "a", errors.New("boom")))
+	clock.advance(time.Second)
+	assert.Equal(t, StateHalfOpen, b.State("a"))
+
+	late(nil)
+	assert.Equal(t, StateHalfOpen, b.State("a"), "only the probe may close the circuit")
+
+	probe, err := b.Allow(context.Background(), "a")
+	assert.NoError(t, err)
+	probe(nil)
+	assert.Equal(t, StateClosed, b.State("a"))
+}
+
+func TestBreakerSlowCalls(t *testing.T) {
+	b, clock := newTestBreaker(Config{MinRequests: 2, SlowCallThreshold: time.Second, SlowCallRateThreshold: 0.5})
+
+	done, err := b.Allow(context.Background(), "a")
+	assert.NoError(t, err)
+	done(nil)
+
+	done, err = b.Allow(context.Background(), "a")
+	assert.NoError(t, err)
+	clock.advance(2 * time.Second)
+	done(nil)
+
+	assert.Equal(t, StateOpen, b.State("a"))
+}
+
+func TestBreakerWindowResets(t *testing.T) {
+	b, clock := newTestBreaker(Config{MinRequests: 2, Window: time.Minute})
+
+	assert.Error(t, call(b, "a", errors.New("boom")))
+	clock.advance(time.Minute)
+	assert.Error(t, call(b, "a", errors.New("boom")))
+	assert.Equal(t, StateClosed, b.State("a"))
+}
+
+func TestBreakerIgnoresCanceled(t *testing.T) {
+	b, _ := newTestBreaker(Config{MinRequests: 1})
+
+	assert.Error(t, call(b, "a", context.Canceled))
+	assert.Equal(t, StateClosed, b.State("a"))
+}
+
+func TestBreakerReleasesProbes(t *testing.T) {
+	open := func(t *testing.T) (*Breaker, *fakeClock) {
+		b, clock := newTestBreaker(Config{MinRequests: 1, OpenTimeout: time.Second})
+		assert.Error(t, call(b, "a", errors.New("boom")))
+		clock.advance(time.Second)
+		assert.Equal(t, StateHalfOpen, b.State("a"))
+		return b, clock
+	}
+
+	t.Run("cancelled probe", func(t *testing.T) {
+		b, _ := open(t)
+		done, err := b.Allow(context.Background(), "a")
+		assert.NoError(t, err)
+		done(context.Canceled)
+		done(nil)
+		assert.Equal(t, StateHalfOpen, b.State("a"), "a cancelled probe does not close the circuit")
+
+		probe, err := b.Allow(context.Background(), "a")
+		assert.NoError(t, err)
+		probe(nil)
+		assert.Equal(t, StateClosed, b.State("a"))
+	})
+
+	t.Run("lost probe", func(t *testing.T) {
+		b, clock := open(t)
+		lost, err := b.Allow(context.Background(), "a")
+		assert.NoError(t, err)
+		_, err = b.Allow(context.Background(), "a")
+		assert.True(t, IsOpen(err))
+
+		clock.advance(time.Second)
+		probe, err := b.Allow(context.Background(), "a")
+		assert.NoError(t, err, "the lost probe is given up after the open timeout")
+		lost(errors.New("boom"))
+		assert.Equal(t, StateHalfOpen, b.State("a"), "the lost probe reports too late")
+		probe(nil)
+		assert.Equal(t, StateClosed, b.State("a"))
+	})
+}
+
+func TestBreakerOnStateChangeMayUseBreaker(t *testing.T) {
+	var b *Breaker
+	var states []State
+	b, _ = newTestBreaker(Config{
+		MinRequests: 1,
+		OnStateChange: func(key string, from, to State) {
+			states = append(states, b.State(key))
+		},
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		_ = call(b, "a", errors.New("boom"))
+		b.Reset("a")
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStateChange deadlocked")
+	}
+	assert.Equal(t, []State{StateOpen, StateClosed}, states)
+}
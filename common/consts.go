@@ -10,6 +10,36 @@ const (
 	DATABASE_POSTGRESQL_DATABASE     = "DATABASE_POSTGRESQL_DATABASE"
 	DATABASE_POSTGRESQL_DBURL        = "DATABASE_POSTGRESQL_DBURL"
 	DATABASE_POSTGRESQL_GORMLOGLEVEL = "DATABASE_POSTGRESQL_GORMLOGLEVEL"
+
+	DATABASE_POSTGRESQL_MAX_OPEN_CONNS     = "DATABASE_POSTGRESQL_MAX_OPEN_CONNS"
+	DATABASE_POSTGRESQL_MAX_IDLE_CONNS     = "DATABASE_POSTGRESQL_MAX_IDLE_CONNS"
+	DATABASE_POSTGRESQL_CONN_MAX_LIFETIME  = "DATABASE_POSTGRESQL_CONN_MAX_LIFETIME"
+	DATABASE_POSTGRESQL_CONN_MAX_IDLE_TIME = "DATABASE_POSTGRESQL_CONN_MAX_IDLE_TIME"
+	DATABASE_POSTGRESQL_STATEMENT_TIMEOUT  = "DATABASE_POSTGRESQL_STATEMENT_TIMEOUT"
+	DATABASE_POSTGRESQL_REPLICA_URLS       = "DATABASE_POSTGRESQL_REPLICA_URLS"
+	DATABASE_POSTGRESQL_STRICT_MIGRATION   = "DATABASE_POSTGRESQL_STRICT_MIGRATION"
+
+	//DATABASE_SQLITE
+	DATABASE_SQLITE_PATH = "DATABASE_SQLITE_PATH"
+
+	//DATABASE_MYSQL
+	DATABASE_MYSQL_USER     = "DATABASE_MYSQL_USER"
+	DATABASE_MYSQL_PASSWORD = "DATABASE_MYSQL_PASSWORD"
+	DATABASE_MYSQL_HOST     = "DATABASE_MYSQL_HOST"
+	DATABASE_MYSQL_PORT     = "DATABASE_MYSQL_PORT"
+	DATABASE_MYSQL_DATABASE = "DATABASE_MYSQL_DATABASE"
+	DATABASE_MYSQL_DSN      = "DATABASE_MYSQL_DSN"
+	DATABASE_MYSQL_TLS      = "DATABASE_MYSQL_TLS"
+	DATABASE_MYSQL_CA_CERT  = "DATABASE_MYSQL_CA_CERT"
+
+	//DATABASE_REDIS
+	DATABASE_REDIS_URL      = "DATABASE_REDIS_URL"
+	DATABASE_REDIS_HOST     = "DATABASE_REDIS_HOST"
+	DATABASE_REDIS_PORT     = "DATABASE_REDIS_PORT"
+	DATABASE_REDIS_USERNAME = "DATABASE_REDIS_USERNAME"
+	DATABASE_REDIS_PASSWORD = "DATABASE_REDIS_PASSWORD"
+	DATABASE_REDIS_DB       = "DATABASE_REDIS_DB"
+	DATABASE_REDIS_TTL      = "DATABASE_REDIS_TTL"
 )
 
 // Model env key
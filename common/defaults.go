@@ -28,6 +28,10 @@ const (
 	// Video
 	DEFAULT_MODEL_VIDEO_NAME     = "doubao-seedance-1-0-pro-250528"
 	DEFAULT_MODEL_VIDEO_API_BASE = "https://ark.cn-beijing.volces.com/api/v3/"
+
+	// Embedding
+	DEFAULT_MODEL_EMBEDDING_NAME     = "doubao-embedding-text-240715"
+	DEFAULT_MODEL_EMBEDDING_API_BASE = "https://ark.cn-beijing.volces.com/api/v3/"
 )
 
 // LOGGING
@@ -40,6 +44,9 @@ const (
 	DEFAULT_LOOPAGENT_NAME       = "veLoopAgent"
 	DEFAULT_PARALLELAGENT_NAME   = "veParallelAgent"
 	DEFAULT_SEQUENTIALAGENT_NAME = "veSequentialAgent"
+	DEFAULT_ROUTERAGENT_NAME     = "veRouterAgent"
+	DEFAULT_GRAPHAGENT_NAME      = "veGraphAgent"
+	DEFAULT_PLANAGENT_NAME       = "vePlanAgent"
 )
 
 const DEFAULT_REGION = "cn-beijing"
@@ -52,6 +59,11 @@ const (
 	DEFAULT_DATABASE_VIKING_REGION  = "cn-beijing"
 )
 
+// SQLite
+const (
+	DEFAULT_DATABASE_SQLITE_PATH = "veadk_sessions.db"
+)
+
 // TOS
 const (
 	DEFAULT_DATABASE_TOS_REGION = "cn-beijing"
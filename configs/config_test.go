@@ -148,3 +148,36 @@ func TestObservabilityConfig_Priority(t *testing.T) {
 	}
 	assert.NotNil(t, config.OpenTelemetry.CozeLoop)
 }
+
+func TestObservabilityConfig_PricingMapping(t *testing.T) {
+	fd, _ := os.Create("config.yaml")
+	_, _ = fd.WriteString(`observability:
+  pricing:
+    doubao-seed-1-6:
+      input: 0.8
+      output: 2
+      cached_input: 0.16`)
+	_ = fd.Close()
+	_ = os.Setenv("OBSERVABILITY_PRICING_DOUBAO-SEED-1-6_REASONING", "8")
+	defer func() {
+		_ = os.Remove("config.yaml")
+		for _, key := range []string{"INPUT", "OUTPUT", "CACHED_INPUT", "REASONING"} {
+			_ = os.Unsetenv("OBSERVABILITY_PRICING_DOUBAO-SEED-1-6_" + key)
+		}
+	}()
+	_ = loadConfigFromProjectYaml()
+
+	config := &ObservabilityConfig{}
+	config.MapEnvToConfig()
+
+	pricing := config.Pricing["doubao-seed-1-6"]
+	assert.NotNil(t, pricing)
+	assert.Equal(t, 0.8, pricing.Input)
+	assert.Equal(t, 2.0, pricing.Output)
+	assert.Equal(t, 0.16, pricing.CachedInput)
+	assert.Equal(t, 8.0, pricing.Reasoning)
+
+	cloned := config.Clone()
+	cloned.Pricing["doubao-seed-1-6"].Input = 1
+	assert.Equal(t, 0.8, config.Pricing["doubao-seed-1-6"].Input)
+}
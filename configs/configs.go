@@ -85,6 +85,9 @@ func SetupVeADKConfig() error {
 		LOGGING:        &Logging{},
 		Database: &DatabaseConfig{
 			Postgresql: &CommonDatabaseConfig{},
+			Mysql:      &MysqlConfig{},
+			Sqlite:     &SqliteConfig{},
+			Redis:      &RedisConfig{},
 			Viking:     &VikingConfig{},
 			TOS:        &TosClientConf{},
 			Mem0:       &Mem0Config{},
@@ -166,6 +169,10 @@ func setYamlToEnv(data map[string]interface{}, prefix string) {
 			if os.Getenv(fullKey) == "" {
 				_ = os.Setenv(fullKey, strconv.Itoa(v))
 			}
+		case float64:
+			if os.Getenv(fullKey) == "" {
+				_ = os.Setenv(fullKey, strconv.FormatFloat(v, 'f', -1, 64))
+			}
 		case bool:
 			if os.Getenv(fullKey) == "" {
 				_ = os.Setenv(fullKey, strconv.FormatBool(v))
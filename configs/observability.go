@@ -56,6 +56,8 @@ const (
 // ObservabilityConfig groups specific configurations for different platforms.
 type ObservabilityConfig struct {
 	OpenTelemetry *OpenTelemetryConfig `yaml:"opentelemetry"`
+	// Pricing maps model names to their token prices and is used to compute the cost of LLM calls.
+	Pricing map[string]*ModelPricing `yaml:"pricing"`
 }
 
 type OpenTelemetryConfig struct {
@@ -248,6 +250,9 @@ func (c *ObservabilityConfig) MapEnvToConfig() {
 		}
 		*ot.EnableMetrics = v == "true"
 	}
+
+	// Pricing
+	c.Pricing = mapPricingEnvToConfig(c.Pricing)
 }
 
 func (c *ObservabilityConfig) Clone() *ObservabilityConfig {
@@ -256,6 +261,7 @@ func (c *ObservabilityConfig) Clone() *ObservabilityConfig {
 	}
 	return &ObservabilityConfig{
 		OpenTelemetry: c.OpenTelemetry.Clone(),
+		Pricing:       clonePricing(c.Pricing),
 	}
 }
 
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvObservabilityPricingPrefix is the prefix of pricing env vars, e.g.
+// OBSERVABILITY_PRICING_DOUBAO-SEED-1-6-250615_INPUT=0.8, which is what
+//
+//	observability:
+//	  pricing:
+//	    doubao-seed-1-6-250615:
+//	      input: 0.8
+//
+// in config.yaml is flattened to.
+const EnvObservabilityPricingPrefix = "OBSERVABILITY_PRICING_"
+
+// ModelPricing is the price of a model per one million tokens.
+// CachedInput and Reasoning fall back to Input and Output when unset.
+type ModelPricing struct {
+	Input       float64 `yaml:"input"`
+	Output      float64 `yaml:"output"`
+	CachedInput float64 `yaml:"cached_input"`
+	Reasoning   float64 `yaml:"reasoning"`
+}
+
+// pricingEnvFields is ordered so that longer suffixes are matched first.
+var pricingEnvFields = []struct {
+	suffix string
+	set    func(p *ModelPricing, v float64)
+}{
+	{"_CACHED_INPUT", func(p *ModelPricing, v float64) { p.CachedInput = v }},
+	{"_REASONING", func(p *ModelPricing, v float64) { p.Reasoning = v }},
+	{"_INPUT", func(p *ModelPricing, v float64) { p.Input = v }},
+	{"_OUTPUT", func(p *ModelPricing, v float64) { p.Output = v }},
+}
+
+// mapPricingEnvToConfig collects OBSERVABILITY_PRICING_<MODEL>_<FIELD> variables. Model names are lower-cased.
+func mapPricingEnvToConfig(pricing map[string]*ModelPricing) map[string]*ModelPricing {
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, EnvObservabilityPricingPrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, EnvObservabilityPricingPrefix)
+		for _, field := range pricingEnvFields {
+			modelName, found := strings.CutSuffix(rest, field.suffix)
+			if !found || modelName == "" {
+				continue
+			}
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				break
+			}
+			modelName = strings.ToLower(modelName)
+			if pricing == nil {
+				pricing = make(map[string]*ModelPricing)
+			}
+			if pricing[modelName] == nil {
+				pricing[modelName] = &ModelPricing{}
+			}
+			field.set(pricing[modelName], price)
+			break
+		}
+	}
+	return pricing
+}
+
+func clonePricing(pricing map[string]*ModelPricing) map[string]*ModelPricing {
+	if pricing == nil {
+		return nil
+	}
+	cloned := make(map[string]*ModelPricing, len(pricing))
+	for name, p := range pricing {
+		if p == nil {
+			continue
+		}
+		cp := *p
+		cloned[name] = &cp
+	}
+	return cloned
+}
@@ -16,20 +16,18 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	veagent "github.com/volcengine/veadk-go/agent/llmagent"
 	"github.com/volcengine/veadk-go/apps"
 	"github.com/volcengine/veadk-go/apps/agentkit_server_app"
 	"github.com/volcengine/veadk-go/log"
 	vem "github.com/volcengine/veadk-go/memory"
-	"github.com/volcengine/veadk-go/tool/builtin_tools"
-	"github.com/volcengine/veadk-go/utils"
+	"github.com/volcengine/veadk-go/memory/autosave"
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
-	"google.golang.org/adk/tool"
-	"google.golang.org/genai"
 )
 
 func main() {
@@ -44,28 +42,19 @@ func main() {
 		return
 	}
 
-	onBeforeAgent := func(ctx agent.CallbackContext) (*genai.Content, error) {
-		resp, err := sessionServer.Get(ctx, &session.GetRequest{AppName: ctx.AppName(), UserID: ctx.UserID(), SessionID: ctx.SessionID()})
-		if err != nil {
-			log.Errorf("Failed to get completed session: %v", err)
-			return nil, fmt.Errorf("failed to get completed session: %w", err)
-		}
-		if err := memoryServer.AddSession(ctx, resp.Session); err != nil {
-			log.Errorf("Failed to add session to memory: %v", err)
-			return nil, fmt.Errorf("failed to add session to memory: %w", err)
-		}
-
-		log.Infof("[Callback] Session %s added to memory.", ctx.SessionID())
-		return nil, nil
+	saver, err := autosave.New(memoryServer, autosave.Config{SessionService: sessionServer, EveryNTurns: 1})
+	if err != nil {
+		log.Errorf("autosave.New failed: %v", err)
+		return
 	}
 
 	a, err := veagent.New(&veagent.Config{
 		Config: llmagent.Config{
-			Name:                 "personal_assistant",
-			Instruction:          "You are a personal assistant with long-term memory capabilities. Before answering the user's questions, you must invoke the tool to retrieve memory information.",
-			Tools:                []tool.Tool{utils.Must(builtin_tools.LoadLongMemoryTool())},
-			BeforeAgentCallbacks: []agent.BeforeAgentCallback{onBeforeAgent},
+			Name:        "personal_assistant",
+			Instruction: "You are a personal assistant with long-term memory capabilities. Search past conversations when the memories you were given are not enough.",
 		},
+		LongTermMemory:     memoryServer,
+		LongTermMemoryMode: veagent.LongTermMemoryToolAndPreload,
 	})
 	if err != nil {
 		log.Errorf("NewLLMAgent failed: %v", err)
@@ -78,6 +67,7 @@ func main() {
 		AgentLoader:    agent.NewSingleLoader(a),
 		SessionService: sessionServer,
 		MemoryService:  memoryServer,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{saver.Plugin()}},
 	})
 	if err != nil {
 		log.Errorf("Run failed: %v", err)
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+
+	veagent "github.com/volcengine/veadk-go/agent/llmagent"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/tool/agenttool"
+	"github.com/volcengine/veadk-go/tool/builtin_tools/web_search"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+)
+
+func main() {
+	ctx := context.Background()
+
+	webSearch, err := web_search.NewWebSearchTool(&web_search.Config{})
+	if err != nil {
+		log.Errorf("NewWebSearchTool failed: %v", err)
+		return
+	}
+
+	researcher, err := veagent.New(&veagent.Config{
+		Config: llmagent.Config{
+			Name:        "researcher",
+			Description: "Searches the web and reports the facts relevant to a request.",
+			Instruction: "Search the web for the request and answer with the relevant facts and their sources.",
+			Tools:       []tool.Tool{webSearch},
+		},
+		ModelName:    common.DEFAULT_MODEL_AGENT_NAME,
+		ModelAPIBase: common.DEFAULT_MODEL_AGENT_API_BASE,
+		ModelAPIKey:  os.Getenv("MODEL_API_KEY"),
+	})
+	if err != nil {
+		log.Errorf("NewLLMAgent researcher failed: %v", err)
+		return
+	}
+
+	// The researcher runs in a child session of its own: its searches stay out of the writer's context.
+	researchTool, err := agenttool.New(researcher, &agenttool.Config{Isolated: true})
+	if err != nil {
+		log.Errorf("NewAgentTool failed: %v", err)
+		return
+	}
+
+	writer, err := veagent.New(&veagent.Config{
+		Config: llmagent.Config{
+			Name:        "writer",
+			Instruction: "Write short articles. Call the researcher for any fact you are not sure about.",
+			Tools:       []tool.Tool{researchTool},
+		},
+		ModelName:    common.DEFAULT_MODEL_AGENT_NAME,
+		ModelAPIBase: common.DEFAULT_MODEL_AGENT_API_BASE,
+		ModelAPIKey:  os.Getenv("MODEL_API_KEY"),
+	})
+	if err != nil {
+		log.Errorf("NewLLMAgent writer failed: %v", err)
+		return
+	}
+
+	config := &launcher.Config{
+		AgentLoader:    agent.NewSingleLoader(writer),
+		SessionService: session.InMemoryService(),
+	}
+
+	l := full.NewLauncher()
+	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+		log.Errorf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+		return
+	}
+}
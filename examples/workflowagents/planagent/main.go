@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	veagent "github.com/volcengine/veadk-go/agent/llmagent"
+	"github.com/volcengine/veadk-go/agent/workflowagents/planagent"
+	"github.com/volcengine/veadk-go/apps"
+	"github.com/volcengine/veadk-go/apps/agentkit_server_app"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/tool/builtin_tools/web_search"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/tool"
+)
+
+func main() {
+	ctx := context.Background()
+
+	webSearch, err := web_search.NewWebSearchTool(&web_search.Config{})
+	if err != nil {
+		log.Errorf("NewWebSearchTool failed: %v", err)
+		return
+	}
+
+	executor, err := veagent.New(&veagent.Config{
+		Config: llmagent.Config{
+			Name:        "executor",
+			Description: "Carries out one research step with web search.",
+			Instruction: "Carry out the latest step given by the planner, using web search when needed. Report what you found concisely.",
+			Tools:       []tool.Tool{webSearch},
+		},
+	})
+	if err != nil {
+		log.Errorf("NewLLMAgent executor failed: %v", err)
+		return
+	}
+
+	// The planner uses the default model and planning instruction; the plan is re-written after
+	// every step and kept in session state under planagent.StateKeyPlan.
+	rootAgent, err := planagent.New(planagent.Config{
+		AgentConfig: agent.Config{
+			Description: "Researches open questions step by step.",
+		},
+		Executor:   executor,
+		MaxSteps:   6,
+		MaxReplans: 3,
+	})
+	if err != nil {
+		log.Errorf("NewPlanAgent failed: %v", err)
+		return
+	}
+
+	app := agentkit_server_app.NewAgentkitServerApp(apps.DefaultApiConfig())
+
+	err = app.Run(ctx, &apps.RunConfig{
+		AgentLoader: agent.NewSingleLoader(rootAgent),
+	})
+	if err != nil {
+		log.Errorf("Run failed: %v", err)
+	}
+}
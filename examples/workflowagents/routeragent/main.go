@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	veagent "github.com/volcengine/veadk-go/agent/llmagent"
+	"github.com/volcengine/veadk-go/agent/workflowagents/routeragent"
+	"github.com/volcengine/veadk-go/apps"
+	"github.com/volcengine/veadk-go/apps/agentkit_server_app"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/log"
+	veModel "github.com/volcengine/veadk-go/model"
+	"github.com/volcengine/veadk-go/utils"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+)
+
+func main() {
+	ctx := context.Background()
+
+	billingAgent, err := veagent.New(&veagent.Config{
+		Config: llmagent.Config{
+			Name:        "billing_agent",
+			Description: "Answers questions about invoices, payments and refunds.",
+			Instruction: "You are a billing specialist. Answer the user's billing question politely.",
+		},
+	})
+	if err != nil {
+		log.Errorf("NewLLMAgent billingAgent failed: %v", err)
+		return
+	}
+
+	supportAgent, err := veagent.New(&veagent.Config{
+		Config: llmagent.Config{
+			Name:        "support_agent",
+			Description: "Troubleshoots technical problems, errors and crashes.",
+			Instruction: "You are a technical support engineer. Help the user fix their problem step by step.",
+		},
+	})
+	if err != nil {
+		log.Errorf("NewLLMAgent supportAgent failed: %v", err)
+		return
+	}
+
+	// Cheap keyword rules go first; everything else is classified by the model.
+	rules, err := routeragent.NewRuleClassifier([]routeragent.Rule{
+		{Route: "billing_agent", Keywords: []string{"invoice", "refund", "发票", "退款"}},
+	})
+	if err != nil {
+		log.Errorf("NewRuleClassifier failed: %v", err)
+		return
+	}
+
+	classifierModel, err := veModel.NewOpenAIModel(ctx,
+		utils.GetEnvWithDefault(common.MODEL_AGENT_NAME, configs.GetGlobalConfig().Model.Agent.Name, common.DEFAULT_MODEL_AGENT_NAME),
+		&veModel.ClientConfig{
+			APIKey:  utils.GetEnvWithDefault(common.MODEL_AGENT_API_KEY, configs.GetGlobalConfig().Model.Agent.ApiKey),
+			BaseURL: utils.GetEnvWithDefault(common.MODEL_AGENT_API_BASE, configs.GetGlobalConfig().Model.Agent.ApiBase, common.DEFAULT_MODEL_AGENT_API_BASE),
+		})
+	if err != nil {
+		log.Errorf("NewOpenAIModel failed: %v", err)
+		return
+	}
+	llmClassifier, err := routeragent.NewLLMClassifier(routeragent.LLMClassifierConfig{Model: classifierModel})
+	if err != nil {
+		log.Errorf("NewLLMClassifier failed: %v", err)
+		return
+	}
+
+	rootAgent, err := routeragent.New(routeragent.Config{
+		AgentConfig: agent.Config{
+			SubAgents:   []agent.Agent{billingAgent, supportAgent},
+			Description: "Dispatches customer requests to the right specialist.",
+		},
+		Classifier: routeragent.ClassifierFunc(func(ctx agent.InvocationContext) (routeragent.Decision, error) {
+			decision, err := rules.Classify(ctx)
+			if errors.Is(err, routeragent.ErrNoRoute) {
+				return llmClassifier.Classify(ctx)
+			}
+			return decision, err
+		}),
+		DefaultRoute: "support_agent",
+	})
+	if err != nil {
+		log.Errorf("NewRouterAgent failed: %v", err)
+		return
+	}
+
+	app := agentkit_server_app.NewAgentkitServerApp(apps.DefaultApiConfig())
+
+	err = app.Run(ctx, &apps.RunConfig{
+		AgentLoader: agent.NewSingleLoader(rootAgent),
+	})
+	if err != nil {
+		log.Errorf("Run failed: %v", err)
+	}
+}
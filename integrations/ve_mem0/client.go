@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -49,9 +51,10 @@ func NewMem0Client(server string, apiKey string, opts ...ClientOption) *Mem0Clie
 
 // AddMemoriesRequest represents the request body for adding memories
 type AddMemoriesRequest struct {
-	Messages  []Message `json:"messages"`
-	UserId    *string   `json:"user_id,omitempty"`
-	AsyncMode *bool     `json:"async_mode,omitempty"`
+	Messages  []Message              `json:"messages"`
+	UserId    *string                `json:"user_id,omitempty"`
+	AsyncMode *bool                  `json:"async_mode,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // Message represents a message in the memory
@@ -137,13 +140,80 @@ func (c *Mem0Client) Search(ctx context.Context, req SearchMemoriesRequest) (Sea
 	return response, nil
 }
 
-func (c *Mem0Client) doRequest(ctx context.Context, method, url string, body interface{}) ([]byte, error) {
-	b, err := json.Marshal(body)
+// ListMemoriesRequest represents the query for listing memories
+type ListMemoriesRequest struct {
+	UserId   string
+	Page     int
+	PageSize int
+}
+
+// ListMemoriesResponse represents the response for listing memories
+type ListMemoriesResponse struct {
+	Count   int          `json:"count"`
+	Results []MemoryItem `json:"results"`
+}
+
+// List lists the memories of a user, page by page
+func (c *Mem0Client) List(ctx context.Context, req ListMemoriesRequest) (ListMemoriesResponse, error) {
+	var response ListMemoriesResponse
+	query := url.Values{}
+	query.Set("user_id", req.UserId)
+	if req.Page > 0 {
+		query.Set("page", strconv.Itoa(req.Page))
+	}
+	if req.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(req.PageSize))
+	}
+
+	body, err := c.doRequest(ctx, http.MethodGet, c.baseURL+"/v1/memories/?"+query.Encode(), nil)
+	if err != nil {
+		return response, fmt.Errorf("mem0 list memories error: %w", err)
+	}
+
+	// without pagination, the API answers with a bare array
+	if len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '[' {
+		err = json.Unmarshal(body, &response.Results)
+		response.Count = len(response.Results)
+	} else {
+		err = json.Unmarshal(body, &response)
+	}
+	if err != nil {
+		return response, fmt.Errorf("mem0 list memories unmarshal body error: %w", err)
+	}
+	return response, nil
+}
+
+// Delete deletes a memory
+func (c *Mem0Client) Delete(ctx context.Context, memoryId string) error {
+	_, err := c.doRequest(ctx, http.MethodDelete, c.baseURL+"/v1/memories/"+url.PathEscape(memoryId)+"/", nil)
+	if err != nil {
+		return fmt.Errorf("mem0 delete memory error: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll deletes all memories of a user
+func (c *Mem0Client) DeleteAll(ctx context.Context, userId string) error {
+	query := url.Values{}
+	query.Set("user_id", userId)
+	_, err := c.doRequest(ctx, http.MethodDelete, c.baseURL+"/v1/memories/?"+query.Encode(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal body: %w", err)
+		return fmt.Errorf("mem0 delete user memories error: %w", err)
+	}
+	return nil
+}
+
+func (c *Mem0Client) doRequest(ctx context.Context, method, url string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		reader = bytes.NewReader(b)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
 		return nil, fmt.Errorf("build request error: %w", err)
 	}
@@ -158,7 +228,7 @@ func (c *Mem0Client) doRequest(ctx context.Context, method, url string, body int
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("do request error status=%d body=%s", resp.StatusCode, string(respBody))
 	}
@@ -102,3 +102,48 @@ func TestMem0Client_Search(t *testing.T) {
 	assert.Equal(t, "mem2", resp.Results[0].Id)
 	assert.Equal(t, "found something", resp.Results[0].Memory)
 }
+
+func TestMem0Client_List(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "paginated", body: `{"count": 1, "results": [{"id": "mem1", "memory": "likes tea"}]}`},
+		{name: "bare array", body: `[{"id": "mem1", "memory": "likes tea"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/memories/", r.URL.Path)
+				assert.Equal(t, "GET", r.Method)
+				assert.Equal(t, "user123", r.URL.Query().Get("user_id"))
+				assert.Equal(t, "2", r.URL.Query().Get("page"))
+				assert.Equal(t, "10", r.URL.Query().Get("page_size"))
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewMem0Client(server.URL, "test-api-key")
+			resp, err := client.List(context.Background(), ListMemoriesRequest{UserId: "user123", Page: 2, PageSize: 10})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, resp.Count)
+			assert.Equal(t, "mem1", resp.Results[0].Id)
+			assert.Equal(t, "likes tea", resp.Results[0].Memory)
+		})
+	}
+}
+
+func TestMem0Client_Delete(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		requests = append(requests, r.URL.RequestURI())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewMem0Client(server.URL, "test-api-key")
+	assert.NoError(t, client.Delete(context.Background(), "mem1"))
+	assert.NoError(t, client.DeleteAll(context.Background(), "user123"))
+	assert.Equal(t, []string{"/v1/memories/mem1/", "/v1/memories/?user_id=user123"}, requests)
+}
@@ -31,6 +31,8 @@ var (
 	CollectionInfoPath         = "/api/memory/collection/info"
 	CollectionSearchMemoryPath = "/api/memory/search"
 	AddSessionPath             = "/api/memory/messages/add"
+	DeleteEventPath            = "/api/memory/event/delete"
+	BatchDeleteEventPath       = "/api/memory/event/batch_delete"
 )
 
 type Client struct {
@@ -144,3 +146,41 @@ func (c *Client) AddSession(req *AddSessionRequest) (*ve_viking.CommonResponse,
 	}
 	return resp, nil
 }
+
+func (c *Client) DeleteEvent(req *DeleteEventRequest) (*ve_viking.CommonResponse, error) {
+	req.ResourceId = c.ResourceID
+	req.CollectionName = c.Index
+	req.ProjectName = c.Project
+	return c.post(DeleteEventPath, req)
+}
+
+// BatchDeleteEvent deletes the memories matching the filter, e.g. all memories of a user.
+func (c *Client) BatchDeleteEvent(req *BatchDeleteEventRequest) (*ve_viking.CommonResponse, error) {
+	req.ResourceId = c.ResourceID
+	req.CollectionName = c.Index
+	req.ProjectName = c.Project
+	return c.post(BatchDeleteEventPath, req)
+}
+
+func (c *Client) post(path string, body any) (*ve_viking.CommonResponse, error) {
+	respBody, err := ve_sign.VeRequest{
+		AK:      c.AK,
+		SK:      c.SK,
+		Method:  http.MethodPost,
+		Host:    KnowledgeBaseDomain,
+		Path:    path,
+		Service: VikingMemoryService,
+		Region:  c.Region,
+		Header:  ve_viking.BuildHeaders(c.ClientConfig),
+		Body:    body,
+	}.DoRequest()
+	if err != nil {
+		return nil, err
+	}
+	var resp *ve_viking.CommonResponse
+	err = ve_viking.ParseJsonUseNumber(respBody, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
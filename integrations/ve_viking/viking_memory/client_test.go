@@ -75,3 +75,31 @@ func TestVikingMemoryClient_AddSession(t *testing.T) {
 		assert.Equal(t, int(resp.Code), ve_viking.VikingKnowledgeBaseSuccessCode)
 	})
 }
+
+func TestVikingMemoryClient_DeleteEvent(t *testing.T) {
+	mockey.PatchConvey("TestVikingMemoryClient_DeleteEvent", t, func() {
+		mockey.Mock(ve_sign.VeRequest.DoRequest).Return([]byte(`{"code": 0}`), nil).Build()
+		mockey.Mock(ve_viking.NewConfig).Return(&ve_viking.ClientConfig{}, nil).Build()
+
+		client, err := New(&ve_viking.ClientConfig{Index: "test"})
+		assert.Nil(t, err)
+
+		resp, err := client.DeleteEvent(&DeleteEventRequest{EventId: "event1"})
+		assert.Nil(t, err)
+		assert.Equal(t, int(resp.Code), ve_viking.VikingKnowledgeBaseSuccessCode)
+	})
+}
+
+func TestVikingMemoryClient_BatchDeleteEvent(t *testing.T) {
+	mockey.PatchConvey("TestVikingMemoryClient_BatchDeleteEvent", t, func() {
+		mockey.Mock(ve_sign.VeRequest.DoRequest).Return([]byte(`{"code": 0}`), nil).Build()
+		mockey.Mock(ve_viking.NewConfig).Return(&ve_viking.ClientConfig{}, nil).Build()
+
+		client, err := New(&ve_viking.ClientConfig{Index: "test"})
+		assert.Nil(t, err)
+
+		resp, err := client.BatchDeleteEvent(&BatchDeleteEventRequest{Filter: Filter{UserId: []string{"user1"}}})
+		assert.Nil(t, err)
+		assert.Equal(t, int(resp.Code), ve_viking.VikingKnowledgeBaseSuccessCode)
+	})
+}
@@ -39,8 +39,12 @@ type CollectionSearchMemoryRequest struct {
 }
 
 type Filter struct {
-	UserId     []string `json:"user_id,omitempty"`
-	MemoryType []string `json:"memory_type,omitempty"`
+	UserId      []string `json:"user_id,omitempty"`
+	AssistantId []string `json:"assistant_id,omitempty"`
+	MemoryType  []string `json:"memory_type,omitempty"`
+	// StartTime and EndTime are unix milliseconds.
+	StartTime int64 `json:"start_time,omitempty"`
+	EndTime   int64 `json:"end_time,omitempty"`
 }
 
 type AddSessionRequest struct {
@@ -88,10 +92,28 @@ type CollectionSearchMemoryResponseData struct {
 }
 
 type CollectionSearchResponseItem struct {
-	MemoryInfo *MemoryInfo `json:"memory_info,omitempty"`
-	Time       int64       `json:"time,omitempty"`
+	Id          string      `json:"id,omitempty"`
+	MemoryType  string      `json:"memory_type,omitempty"`
+	AssistantId []string    `json:"assistant_id,omitempty"`
+	MemoryInfo  *MemoryInfo `json:"memory_info,omitempty"`
+	Score       float64     `json:"score,omitempty"`
+	Time        int64       `json:"time,omitempty"`
 }
 
 type MemoryInfo struct {
 	Summary string `json:"summary,omitempty"`
 }
+
+type DeleteEventRequest struct {
+	CollectionName string `json:"collection_name"`
+	ProjectName    string `json:"project_name"`
+	ResourceId     string `json:"resource_id,omitempty"`
+	EventId        string `json:"event_id"`
+}
+
+type BatchDeleteEventRequest struct {
+	CollectionName string `json:"collection_name"`
+	ProjectName    string `json:"project_name"`
+	ResourceId     string `json:"resource_id,omitempty"`
+	Filter         Filter `json:"filter"`
+}
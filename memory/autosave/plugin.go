@@ -0,0 +1,378 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autosave saves sessions to long-term memory in the background, so that memory.Service.AddSession
+// does not have to be called by hand.
+package autosave
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+const (
+	PluginName = "veadk-memory-autosave"
+
+	// StateKeyLastSavedEventID is the ID of the last session event saved to memory. Once a save succeeds, the
+	// Saver appends an event recording it, or, while a run of the session is in progress, lets the next event
+	// of the run record it.
+	StateKeyLastSavedEventID = "veadk.memory.last_saved_event_id"
+
+	DefaultMaxConcurrency = 4
+	DefaultSaveTimeout    = time.Minute
+)
+
+var (
+	ErrNoMemoryService  = errors.New("autosave requires a memory service")
+	ErrNoSessionService = errors.New("autosave requires a session service")
+)
+
+// Config selects when sessions are saved, besides Saver.EndSession which always saves.
+type Config struct {
+	// SessionService is the service of the runner. Sessions are read from it when saving.
+	SessionService session.Service
+
+	// EveryNTurns saves a session once it has N user turns not saved yet. Zero disables it.
+	EveryNTurns int
+	// IdleTimeout saves a session once no run happened in it for this long. Zero disables it.
+	IdleTimeout time.Duration
+
+	// MaxConcurrency bounds the saves running at once. Defaults to DefaultMaxConcurrency.
+	MaxConcurrency int
+	// SaveTimeout bounds a single save. Defaults to DefaultSaveTimeout.
+	SaveTimeout time.Duration
+	// FullSession passes the whole session to AddSession instead of the events not saved yet. Set it for
+	// memory services replacing the memories of a session on every call, like the ADK in-memory service;
+	// the veadk backends append, so they only need the new events.
+	FullSession bool
+}
+
+type sessionKey struct {
+	appName, userID, sessionID string
+}
+
+// job coalesces the save requests of a session while one of its saves is running.
+type job struct {
+	pending bool
+	force   bool
+}
+
+// Saver saves sessions to a memory service asynchronously. Each session is saved by at most one goroutine at
+// a time, and events saved before are not saved again.
+type Saver struct {
+	memory memory.Service
+	config Config
+	sem    chan struct{}
+
+	mu     sync.Mutex
+	jobs   map[sessionKey]*job
+	timers map[sessionKey]*time.Timer
+	// runs counts the runs in progress per session.
+	runs map[sessionKey]int
+	// saved holds the last saved event of the sessions where it is not recorded yet.
+	saved  map[sessionKey]string
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func New(service memory.Service, config Config) (*Saver, error) {
+	if service == nil {
+		return nil, ErrNoMemoryService
+	}
+	if config.SessionService == nil {
+		return nil, ErrNoSessionService
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = DefaultMaxConcurrency
+	}
+	if config.SaveTimeout <= 0 {
+		config.SaveTimeout = DefaultSaveTimeout
+	}
+	return &Saver{
+		memory: service,
+		config: config,
+		sem:    make(chan struct{}, config.MaxConcurrency),
+		jobs:   make(map[sessionKey]*job),
+		timers: make(map[sessionKey]*time.Timer),
+		runs:   make(map[sessionKey]int),
+		saved:  make(map[sessionKey]string),
+	}, nil
+}
+
+// Plugin returns the plugin triggering the saves after each run. Closing it closes the Saver.
+func (s *Saver) Plugin() *plugin.Plugin {
+	// no need to check the error as it is always nil.
+	p, _ := plugin.New(plugin.Config{
+		Name:              PluginName,
+		BeforeRunCallback: s.beforeRun,
+		OnEventCallback:   s.onEvent,
+		AfterRunCallback:  s.afterRun,
+		CloseFunc:         s.Close,
+	})
+	return p
+}
+
+// EndSession saves the unsaved events of a session, e.g. when the user closes the conversation.
+// It returns immediately.
+func (s *Saver) EndSession(appName, userID, sessionID string) {
+	key := sessionKey{appName: appName, userID: userID, sessionID: sessionID}
+	s.mu.Lock()
+	if timer, ok := s.timers[key]; ok {
+		timer.Stop()
+		delete(s.timers, key)
+	}
+	s.mu.Unlock()
+	s.enqueue(key, true)
+}
+
+// Close stops the idle timers and waits for the running saves. Sessions still idle are not saved.
+func (s *Saver) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for key, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, key)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Saver) beforeRun(ctx agent.InvocationContext) (*genai.Content, error) {
+	s.mu.Lock()
+	s.runs[newSessionKey(ctx.Session())]++
+	s.mu.Unlock()
+	return nil, nil
+}
+
+// onEvent records the last saved event in the state delta of the next event of the session. Saves completing
+// during a run do not append events themselves: on the database services, that would make the run fail as stale.
+func (s *Saver) onEvent(ctx agent.InvocationContext, event *session.Event) (*session.Event, error) {
+	if event == nil || event.Partial {
+		return nil, nil
+	}
+	key := newSessionKey(ctx.Session())
+	s.mu.Lock()
+	saved, ok := s.saved[key]
+	delete(s.saved, key)
+	s.mu.Unlock()
+	if ok {
+		if event.Actions.StateDelta == nil {
+			event.Actions.StateDelta = make(map[string]any)
+		}
+		event.Actions.StateDelta[StateKeyLastSavedEventID] = saved
+	}
+	return nil, nil
+}
+
+func (s *Saver) afterRun(ctx agent.InvocationContext) {
+	current := ctx.Session()
+	key := newSessionKey(current)
+
+	s.mu.Lock()
+	if s.runs[key] > 1 {
+		s.runs[key]--
+	} else {
+		delete(s.runs, key)
+	}
+	// a save completed after the last event of the run
+	_, unrecorded := s.saved[key]
+	s.mu.Unlock()
+
+	if s.config.IdleTimeout > 0 {
+		s.mu.Lock()
+		if timer, ok := s.timers[key]; ok {
+			timer.Reset(s.config.IdleTimeout)
+		} else if !s.closed {
+			s.timers[key] = time.AfterFunc(s.config.IdleTimeout, func() {
+				s.mu.Lock()
+				delete(s.timers, key)
+				s.mu.Unlock()
+				s.enqueue(key, true)
+			})
+		}
+		s.mu.Unlock()
+	}
+
+	if unrecorded || (s.config.EveryNTurns > 0 && userTurns(s.unsavedEvents(key, current)) >= s.config.EveryNTurns) {
+		s.enqueue(key, false)
+	}
+}
+
+// enqueue starts a save of the session unless one is running, in which case the running one saves again
+// when done. Unforced saves only happen once EveryNTurns is reached.
+func (s *Saver) enqueue(key sessionKey, force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if j, ok := s.jobs[key]; ok {
+		j.pending = true
+		j.force = j.force || force
+		return
+	}
+	s.jobs[key] = &job{}
+	s.wg.Add(1)
+	go s.run(key, force)
+}
+
+func (s *Saver) run(key sessionKey, force bool) {
+	defer s.wg.Done()
+	for {
+		s.sem <- struct{}{}
+		if err := s.save(key, force); err != nil {
+			log.Warn("autosave session to memory failed", "AppName", key.appName, "UserID", key.userID, "SessionID", key.sessionID, "error", err)
+		}
+		<-s.sem
+
+		s.mu.Lock()
+		j := s.jobs[key]
+		if !j.pending {
+			delete(s.jobs, key)
+			s.mu.Unlock()
+			return
+		}
+		force = j.force
+		j.pending, j.force = false, false
+		s.mu.Unlock()
+	}
+}
+
+func (s *Saver) save(key sessionKey, force bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.SaveTimeout)
+	defer cancel()
+
+	resp, err := s.config.SessionService.Get(ctx, &session.GetRequest{AppName: key.appName, UserID: key.userID, SessionID: key.sessionID})
+	if err != nil {
+		return err
+	}
+	current := resp.Session
+	events := s.unsavedEvents(key, current)
+	if len(events) > 0 && (force || userTurns(events) >= s.config.EveryNTurns) {
+		toSave := current
+		if !s.config.FullSession {
+			toSave = &sessionView{Session: current, events: events}
+		}
+		if err := s.memory.AddSession(ctx, toSave); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.saved[key] = events[len(events)-1].ID
+		s.mu.Unlock()
+	}
+	return s.record(ctx, key)
+}
+
+// record appends an event recording the last saved event of the session, unless a run of the session is in
+// progress, in which case its next event records it.
+func (s *Saver) record(ctx context.Context, key sessionKey) error {
+	s.mu.Lock()
+	saved, ok := s.saved[key]
+	if !ok || s.runs[key] > 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.saved, key)
+	s.mu.Unlock()
+
+	resp, err := s.config.SessionService.Get(ctx, &session.GetRequest{AppName: key.appName, UserID: key.userID, SessionID: key.sessionID})
+	if err == nil {
+		event := session.NewEvent("")
+		// the runner looks for the agent of every event author but the user's, to pick the agent to run
+		event.Author = "user"
+		event.Actions.StateDelta[StateKeyLastSavedEventID] = saved
+		err = s.config.SessionService.AppendEvent(ctx, resp.Session, event)
+	}
+	if err != nil {
+		// keep it for the next event of the session, unless a later save replaced it
+		s.mu.Lock()
+		if _, ok := s.saved[key]; !ok {
+			s.saved[key] = saved
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func newSessionKey(s session.Session) sessionKey {
+	return sessionKey{appName: s.AppName(), userID: s.UserID(), sessionID: s.ID()}
+}
+
+// unsavedEvents returns the events following the last saved event, kept by the Saver or in
+// StateKeyLastSavedEventID, whichever comes later. Events without content, like the ones recording the last
+// saved event, have nothing to save.
+func (s *Saver) unsavedEvents(key sessionKey, current session.Session) []*session.Event {
+	s.mu.Lock()
+	saved := s.saved[key]
+	s.mu.Unlock()
+	lastSaved, _ := current.State().Get(StateKeyLastSavedEventID)
+	var events []*session.Event
+	for event := range current.Events().All() {
+		if event.ID == saved || (lastSaved != nil && event.ID == lastSaved) {
+			events = events[:0]
+			continue
+		}
+		if event.Content != nil && !event.Partial {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func userTurns(events []*session.Event) int {
+	turns := 0
+	for _, event := range events {
+		if event.Author == "user" {
+			turns++
+		}
+	}
+	return turns
+}
+
+// sessionView exposes a subset of the events of a session.
+type sessionView struct {
+	session.Session
+	events eventList
+}
+
+func (v *sessionView) Events() session.Events {
+	return v.events
+}
+
+type eventList []*session.Event
+
+func (l eventList) All() iter.Seq[*session.Event] {
+	return slices.Values(l)
+}
+
+func (l eventList) Len() int {
+	return len(l)
+}
+
+func (l eventList) At(i int) *session.Event {
+	return l[i]
+}
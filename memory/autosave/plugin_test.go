@@ -0,0 +1,261 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autosave
+
+import (
+	"context"
+	"iter"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingMemory records the texts of the events passed to each AddSession call. If gate is set, saves
+// signal entered and wait for gate.
+type recordingMemory struct {
+	memory.Service
+	gate    chan struct{}
+	entered chan struct{}
+	mu      sync.Mutex
+	saves   [][]string
+}
+
+func (m *recordingMemory) AddSession(ctx context.Context, s session.Session) error {
+	if m.gate != nil {
+		m.entered <- struct{}{}
+		<-m.gate
+	}
+	var texts []string
+	for event := range s.Events().All() {
+		if event.Content != nil {
+			texts = append(texts, event.Content.Parts[0].Text)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saves = append(m.saves, texts)
+	return nil
+}
+
+func (m *recordingMemory) saved() [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]string(nil), m.saves...)
+}
+
+type fixture struct {
+	memory   *recordingMemory
+	saver    *Saver
+	runner   *runner.Runner
+	sessions session.Service
+	id       string
+}
+
+func newFixture(t *testing.T, cfg Config) *fixture {
+	t.Helper()
+	echo, err := agent.New(agent.Config{
+		Name: "echo",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Author = "echo"
+				event.Content = genai.NewContentFromText("re: "+ctx.UserContent().Parts[0].Text, genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+
+	f := &fixture{memory: &recordingMemory{}, sessions: session.InMemoryService()}
+	cfg.SessionService = f.sessions
+	f.saver, err = New(f.memory, cfg)
+	require.NoError(t, err)
+	f.runner, err = runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          echo,
+		SessionService: f.sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{f.saver.Plugin()}},
+	})
+	require.NoError(t, err)
+	created, err := f.sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	f.id = created.Session.ID()
+	return f
+}
+
+func (f *fixture) say(t *testing.T, text string) {
+	t.Helper()
+	for _, err := range f.runner.Run(t.Context(), "user", f.id, genai.NewContentFromText(text, genai.RoleUser), agent.RunConfig{}) {
+		require.NoError(t, err)
+	}
+}
+
+// wait lets the running saves finish.
+func (f *fixture) wait() {
+	f.saver.wg.Wait()
+}
+
+func TestEveryNTurns(t *testing.T) {
+	f := newFixture(t, Config{EveryNTurns: 2})
+
+	f.say(t, "one")
+	f.wait()
+	assert.Empty(t, f.memory.saved())
+
+	f.say(t, "two")
+	f.wait()
+	f.say(t, "three")
+	f.wait()
+	f.say(t, "four")
+	f.wait()
+	assert.Equal(t, [][]string{
+		{"one", "re: one", "two", "re: two"},
+		{"three", "re: three", "four", "re: four"},
+	}, f.memory.saved())
+
+	resp, err := f.sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: f.id})
+	require.NoError(t, err)
+	// each save is recorded by an event appended once it completes
+	lastSaved, err := resp.Session.State().Get(StateKeyLastSavedEventID)
+	require.NoError(t, err)
+	events := resp.Session.Events()
+	require.Equal(t, 10, events.Len())
+	assert.Equal(t, events.At(8).ID, lastSaved)
+	assert.Nil(t, events.At(4).Content)
+	assert.Nil(t, events.At(9).Content)
+}
+
+func TestSaveDuringRunOnDatabase(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "sessions.db")
+	sessions, err := database.NewSessionService(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(sessions))
+
+	mem := &recordingMemory{gate: make(chan struct{}), entered: make(chan struct{}, 2)}
+	saver, err := New(mem, Config{SessionService: sessions, EveryNTurns: 1})
+	require.NoError(t, err)
+	// slow answers in two events, letting the save of the previous turn finish in between
+	slow, err := agent.New(agent.Config{
+		Name: "slow",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				text := ctx.UserContent().Parts[0].Text
+				for i, part := range []string{"thinking about ", "re: "} {
+					if i == 1 && text == "two" {
+						close(mem.gate)
+						saver.wg.Wait()
+					}
+					event := session.NewEvent(ctx.InvocationID())
+					event.Content = genai.NewContentFromText(part+text, genai.RoleModel)
+					if !yield(event, nil) {
+						return
+					}
+				}
+			}
+		},
+	})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          slow,
+		SessionService: sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{saver.Plugin()}},
+	})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	for _, text := range []string{"one", "two"} {
+		for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText(text, genai.RoleUser), agent.RunConfig{}) {
+			require.NoError(t, err)
+		}
+		if text == "one" {
+			// the save of "one" has read the session and is now held until the next run is halfway
+			<-mem.entered
+		}
+	}
+	require.NoError(t, saver.Close())
+	assert.Equal(t, [][]string{
+		{"one", "thinking about one", "re: one"},
+		{"two", "thinking about two", "re: two"},
+	}, mem.saved())
+
+	resp, err := sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	events := resp.Session.Events()
+	// the save of "one" is recorded by the last event of "two", the save of "two" by an event of its own
+	require.Equal(t, 7, events.Len())
+	assert.Equal(t, events.At(2).ID, events.At(5).Actions.StateDelta[StateKeyLastSavedEventID])
+	lastSaved, err := resp.Session.State().Get(StateKeyLastSavedEventID)
+	require.NoError(t, err)
+	assert.Equal(t, events.At(5).ID, lastSaved)
+	assert.Empty(t, saver.saved)
+}
+
+func TestEndSessionAndIdleTimeout(t *testing.T) {
+	f := newFixture(t, Config{IdleTimeout: 50 * time.Millisecond})
+
+	f.say(t, "one")
+	f.saver.EndSession("app", "user", f.id)
+	f.wait()
+	assert.Equal(t, [][]string{{"one", "re: one"}}, f.memory.saved())
+
+	// Nothing new since the end of the session, even after a restart.
+	f.saver.EndSession("app", "user", f.id)
+	f.wait()
+	assert.Len(t, f.memory.saved(), 1)
+	assert.Empty(t, f.saver.saved)
+	restarted, err := New(f.memory, Config{SessionService: f.sessions})
+	require.NoError(t, err)
+	restarted.EndSession("app", "user", f.id)
+	restarted.wg.Wait()
+	assert.Len(t, f.memory.saved(), 1)
+
+	f.say(t, "two")
+	assert.Eventually(t, func() bool { return len(f.memory.saved()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	f.wait()
+	assert.Equal(t, []string{"two", "re: two"}, f.memory.saved()[1])
+
+	require.NoError(t, f.saver.Close())
+	f.say(t, "three")
+	f.saver.EndSession("app", "user", f.id)
+	assert.Len(t, f.memory.saved(), 2)
+}
+
+func TestFullSession(t *testing.T) {
+	f := newFixture(t, Config{EveryNTurns: 1, FullSession: true})
+
+	f.say(t, "one")
+	f.wait()
+	f.say(t, "two")
+	f.wait()
+	saved := f.memory.saved()
+	require.Len(t, saved, 2)
+	assert.Equal(t, []string{"one", "re: one", "two", "re: two"}, saved[1])
+}
@@ -0,0 +1,455 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consolidation merges the near-duplicate long-term memories that repeated conversations leave in a
+// backend, on demand or on a schedule.
+package consolidation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	ltm "github.com/volcengine/veadk-go/memory/long_term_memory_backends"
+	"github.com/volcengine/veadk-go/model"
+	adkmodel "google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+const (
+	DefaultSimilarityThreshold = 0.9
+	DefaultMaxMemories         = 1000
+	DefaultPageSize            = 100
+)
+
+var (
+	ErrNoBackend  = errors.New("consolidation requires a long-term memory backend")
+	ErrNoSchedule = errors.New("scheduled consolidation requires Interval and Users")
+	ErrStarted    = errors.New("consolidator already started")
+)
+
+type Config struct {
+	// Backend holds the memories to consolidate.
+	Backend ltm.LongTermMemoryBackend
+	// Embedder defaults to model.NewOpenAIEmbedder configured from the MODEL_EMBEDDING_* environment variables.
+	Embedder model.Embedder
+	// Model merges the clusters whose memories say different things. Without it, the newest memory of such
+	// clusters is kept.
+	Model adkmodel.LLM
+	// Instruction replaces the default merge instruction.
+	Instruction string
+
+	// SimilarityThreshold is the cosine similarity above which two memories are duplicates. Defaults to
+	// DefaultSimilarityThreshold.
+	SimilarityThreshold float64
+	// MaxMemories bounds the memories read per user. Defaults to DefaultMaxMemories.
+	MaxMemories int
+	// PageSize is the page size of ListMemories. Defaults to DefaultPageSize.
+	PageSize int
+	// RequestInterval is the minimum delay between two requests to the backend, the embedder or the model,
+	// so that a run does not compete with the agents for their rate limits. Zero means no delay.
+	RequestInterval time.Duration
+	// DryRun only reports the clusters, without rewriting any memory.
+	DryRun bool
+
+	// Interval is the period of the runs started by Start.
+	Interval time.Duration
+	// Users returns the users to consolidate on each scheduled run.
+	Users func(ctx context.Context) ([]string, error)
+	// OnReport receives the report of each user consolidated by a scheduled run. Defaults to logging it.
+	OnReport func(report *Report)
+}
+
+// Cluster is a group of near-duplicate memories of the same app and category.
+type Cluster struct {
+	AppName  string
+	Category ltm.MemoryCategory
+	// Memories are the duplicates, newest first.
+	Memories []*ltm.MemItem
+	// Merged is the text of the memory replacing them.
+	Merged string
+	// LLMMerged is set when Model wrote Merged, because the memories said different things.
+	LLMMerged bool
+}
+
+// Report describes a consolidation of the memories of a user.
+type Report struct {
+	UserID string
+	DryRun bool
+	// Scanned is the number of memories read.
+	Scanned int
+	// Clusters only contains the groups of at least two memories.
+	Clusters []*Cluster
+	// Removed is the number of memories deleted, or that would be deleted by a dry run.
+	Removed int
+}
+
+// Consolidator clusters the memories of a user by embedding similarity and rewrites each cluster as a single
+// memory.
+type Consolidator struct {
+	config Config
+
+	mu          sync.Mutex
+	lastRequest time.Time
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func New(config Config) (*Consolidator, error) {
+	if config.Backend == nil {
+		return nil, ErrNoBackend
+	}
+	if config.Embedder == nil {
+		embedder, err := model.NewOpenAIEmbedder("", nil)
+		if err != nil {
+			return nil, err
+		}
+		config.Embedder = embedder
+	}
+	if config.Instruction == "" {
+		config.Instruction = defaultMergeInstruction
+	}
+	if config.SimilarityThreshold <= 0 {
+		config.SimilarityThreshold = DefaultSimilarityThreshold
+	}
+	if config.MaxMemories <= 0 {
+		config.MaxMemories = DefaultMaxMemories
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = DefaultPageSize
+	}
+	if config.OnReport == nil {
+		config.OnReport = logReport
+	}
+	return &Consolidator{config: config}, nil
+}
+
+// Consolidate consolidates the memories of a user. The merged memory of a cluster is saved before its
+// duplicates are deleted, so a failure leaves duplicates behind rather than losing memories.
+func (c *Consolidator) Consolidate(ctx context.Context, userID string) (*Report, error) {
+	memories, err := c.list(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{UserID: userID, DryRun: c.config.DryRun, Scanned: len(memories)}
+	if len(memories) < 2 {
+		return report, nil
+	}
+
+	texts := make([]string, len(memories))
+	for i, m := range memories {
+		texts[i] = m.Text()
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	vectors, err := c.config.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed memories failed: %w", err)
+	}
+	if len(vectors) != len(memories) {
+		return nil, ltm.ErrEmbeddingSize
+	}
+
+	for _, cluster := range c.cluster(memories, vectors) {
+		if err := c.merge(ctx, cluster); err != nil {
+			return report, err
+		}
+		report.Clusters = append(report.Clusters, cluster)
+		report.Removed += c.removed(cluster)
+		if c.config.DryRun {
+			continue
+		}
+		if err := c.rewrite(ctx, userID, cluster); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// list reads the memories of a user, newest first.
+func (c *Consolidator) list(ctx context.Context, userID string) ([]*ltm.MemItem, error) {
+	var memories []*ltm.MemItem
+	for page := 1; len(memories) < c.config.MaxMemories; page++ {
+		if err := c.wait(ctx); err != nil {
+			return nil, err
+		}
+		items, err := c.config.Backend.ListMemories(ctx, userID, ltm.Page{Number: page, Size: c.config.PageSize})
+		if err != nil {
+			return nil, fmt.Errorf("list memories of %s failed: %w", userID, err)
+		}
+		memories = append(memories, items...)
+		if len(items) < c.config.PageSize {
+			break
+		}
+	}
+	if len(memories) > c.config.MaxMemories {
+		memories = memories[:c.config.MaxMemories]
+	}
+	sort.SliceStable(memories, func(i, j int) bool {
+		return memories[i].Timestamp.After(memories[j].Timestamp)
+	})
+	return memories, nil
+}
+
+// cluster groups each memory with the first cluster of the same app and category whose newest memory is
+// similar enough. Only the clusters of at least two memories are returned. Memories whose app the backend
+// cannot tell are left alone, as merging them could move them to another app.
+func (c *Consolidator) cluster(memories []*ltm.MemItem, vectors [][]float32) []*Cluster {
+	type group struct {
+		cluster *Cluster
+		vector  []float32
+	}
+	var groups []*group
+	for i, m := range memories {
+		appName, ok := m.Metadata["app_name"].(string)
+		if !ok {
+			continue
+		}
+		category := m.Category()
+		var found *group
+		for _, g := range groups {
+			if g.cluster.AppName == appName && g.cluster.Category == category &&
+				model.CosineSimilarity(g.vector, vectors[i]) >= c.config.SimilarityThreshold {
+				found = g
+				break
+			}
+		}
+		if found == nil {
+			found = &group{cluster: &Cluster{AppName: appName, Category: category}, vector: vectors[i]}
+			groups = append(groups, found)
+		}
+		found.cluster.Memories = append(found.cluster.Memories, m)
+	}
+
+	var clusters []*Cluster
+	for _, g := range groups {
+		if len(g.cluster.Memories) > 1 {
+			clusters = append(clusters, g.cluster)
+		}
+	}
+	return clusters
+}
+
+// merge sets the merged text of a cluster: the newest text when the memories say the same thing, else the
+// answer of the model.
+func (c *Consolidator) merge(ctx context.Context, cluster *Cluster) error {
+	newest := cluster.Memories[0].Text()
+	cluster.Merged = newest
+	if c.config.Model == nil || !conflicting(cluster) {
+		return nil
+	}
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	merged, err := c.generate(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if merged != "" {
+		cluster.Merged = merged
+		cluster.LLMMerged = true
+	}
+	return nil
+}
+
+// conflicting reports whether the memories of a cluster differ beyond case, spacing and punctuation.
+func conflicting(cluster *Cluster) bool {
+	first := normalize(cluster.Memories[0].Text())
+	for _, m := range cluster.Memories[1:] {
+		if normalize(m.Text()) != first {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(text string) string {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	return strings.TrimRight(text, ".!?。！？ ")
+}
+
+// removed returns the number of memories deleted by the rewrite of a cluster.
+func (c *Consolidator) removed(cluster *Cluster) int {
+	if cluster.LLMMerged {
+		return len(cluster.Memories)
+	}
+	return len(cluster.Memories) - 1
+}
+
+// rewrite saves the merged memory, unless it is the newest one, then deletes the others.
+func (c *Consolidator) rewrite(ctx context.Context, userID string, cluster *Cluster) error {
+	duplicates := cluster.Memories[1:]
+	if cluster.LLMMerged {
+		duplicates = cluster.Memories
+		content := cluster.Merged
+		if cluster.Category != "" {
+			encoded, err := json.Marshal(ltm.ExtractedMemory{Category: cluster.Category, Content: cluster.Merged})
+			if err != nil {
+				return err
+			}
+			content = string(encoded)
+		}
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		if err := c.config.Backend.SaveMemory(ctx, cluster.AppName, userID, []string{content}); err != nil {
+			return fmt.Errorf("save merged memory failed: %w", err)
+		}
+	}
+	for _, m := range duplicates {
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		if err := c.config.Backend.DeleteMemory(ctx, m.ID); err != nil {
+			return fmt.Errorf("delete duplicate memory %s failed: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+const defaultMergeInstruction = `You maintain the long-term memory of an assistant. The following memories about the same user overlap, newest first.
+Merge them into a single self-contained memory in their language, referring to the user as "the user". When they contradict each other, keep what the newest memory says.
+Answer with the merged memory only.`
+
+func (c *Consolidator) generate(ctx context.Context, cluster *Cluster) (string, error) {
+	var prompt strings.Builder
+	for _, m := range cluster.Memories {
+		fmt.Fprintf(&prompt, "- [%s] %s\n", m.Timestamp.Format(time.DateOnly), m.Text())
+	}
+	req := &adkmodel.LLMRequest{
+		Model:    c.config.Model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(prompt.String(), genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(c.config.Instruction, genai.RoleUser),
+			Temperature:       genai.Ptr[float32](0),
+		},
+	}
+	var text strings.Builder
+	for resp, err := range c.config.Model.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", fmt.Errorf("merge memories failed: %w", err)
+		}
+		if resp == nil || resp.Partial || resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if part != nil && !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// wait enforces RequestInterval between two requests.
+func (c *Consolidator) wait(ctx context.Context) error {
+	if c.config.RequestInterval <= 0 {
+		return ctx.Err()
+	}
+	c.mu.Lock()
+	next := c.lastRequest.Add(c.config.RequestInterval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.lastRequest = next
+	c.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Start consolidates the memories of Users every Interval in the background, until Close is called.
+func (c *Consolidator) Start() error {
+	if c.config.Interval <= 0 || c.config.Users == nil {
+		return ErrNoSchedule
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		return ErrStarted
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.RunOnce(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// RunOnce consolidates the memories of Users once. Failures are logged and do not stop the other users.
+func (c *Consolidator) RunOnce(ctx context.Context) {
+	users, err := c.config.Users(ctx)
+	if err != nil {
+		log.Warn("list users to consolidate failed", "error", err)
+		return
+	}
+	for _, userID := range users {
+		report, err := c.Consolidate(ctx, userID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn("consolidate memories failed", "user", userID, "error", err)
+		}
+		if report != nil {
+			c.config.OnReport(report)
+		}
+	}
+}
+
+// Close stops the scheduled runs and waits for the current one to return.
+func (c *Consolidator) Close() error {
+	c.mu.Lock()
+	cancel := c.cancel
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	c.wg.Wait()
+	return nil
+}
+
+func logReport(report *Report) {
+	action := "removed"
+	if report.DryRun {
+		action = "would remove"
+	}
+	log.Infof("Consolidated memories of user %s: %d scanned, %d clusters, %s %d",
+		report.UserID, report.Scanned, len(report.Clusters), action, report.Removed)
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consolidation
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ltm "github.com/volcengine/veadk-go/memory/long_term_memory_backends"
+	"github.com/volcengine/veadk-go/model"
+	adkmodel "google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// memoryBackend keeps the memories of a single user in memory.
+type memoryBackend struct {
+	mu    sync.Mutex
+	items []*ltm.MemItem
+	next  int
+}
+
+func (b *memoryBackend) add(appName, content string, age time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.next++
+	b.items = append(b.items, &ltm.MemItem{
+		ID:        fmt.Sprint(b.next),
+		Content:   content,
+		Timestamp: time.Now().Add(-age),
+		Metadata:  map[string]any{"app_name": appName},
+	})
+}
+
+func (b *memoryBackend) contents() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var contents []string
+	for _, item := range b.items {
+		contents = append(contents, item.Content)
+	}
+	return contents
+}
+
+func (b *memoryBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
+	for _, event := range eventList {
+		b.add(appName, event, 0)
+	}
+	return nil
+}
+
+func (b *memoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter ltm.SearchFilter) ([]*ltm.MemItem, error) {
+	return nil, nil
+}
+
+func (b *memoryBackend) ListMemories(ctx context.Context, userId string, page ltm.Page) ([]*ltm.MemItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	start := min((page.Number-1)*page.Size, len(b.items))
+	end := min(start+page.Size, len(b.items))
+	return append([]*ltm.MemItem(nil), b.items[start:end]...), nil
+}
+
+func (b *memoryBackend) DeleteMemory(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, item := range b.items {
+		if item.ID == id {
+			b.items = append(b.items[:i], b.items[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (b *memoryBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	return nil
+}
+
+type fakeLLM struct {
+	prompts []string
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *adkmodel.LLMRequest, stream bool) iter.Seq2[*adkmodel.LLMResponse, error] {
+	return func(yield func(*adkmodel.LLMResponse, error) bool) {
+		m.prompts = append(m.prompts, req.Contents[0].Parts[0].Text)
+		yield(&adkmodel.LLMResponse{Content: genai.NewContentFromText("The user lives in Berlin.", genai.RoleModel)}, nil)
+	}
+}
+
+func seed() *memoryBackend {
+	backend := &memoryBackend{}
+	backend.add("app", "I drink green tea every morning.", 3*time.Hour)
+	backend.add("app", "i drink green tea every morning", time.Hour)
+	backend.add("app", `{"category":"fact","content":"The user lives in Paris"}`, 48*time.Hour)
+	backend.add("app", `{"category":"fact","content":"The user now lives in Berlin"}`, 2*time.Hour)
+	backend.add("app", "I own a cat named Miso", time.Hour)
+	backend.add("other", "I drink green tea every morning", time.Hour)
+	return backend
+}
+
+func newConsolidator(t *testing.T, backend *memoryBackend, config Config) *Consolidator {
+	t.Helper()
+	config.Backend = backend
+	config.Embedder = model.NewHashEmbedder(256)
+	config.SimilarityThreshold = 0.6
+	config.PageSize = 2
+	c, err := New(config)
+	require.NoError(t, err)
+	return c
+}
+
+func TestConsolidate(t *testing.T) {
+	llm := &fakeLLM{}
+	backend := seed()
+	report, err := newConsolidator(t, backend, Config{Model: llm}).Consolidate(t.Context(), "user")
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Scanned)
+	assert.Equal(t, 3, report.Removed)
+	require.Len(t, report.Clusters, 2)
+	assert.Equal(t, "i drink green tea every morning", report.Clusters[0].Merged)
+	assert.False(t, report.Clusters[0].LLMMerged)
+	assert.Equal(t, ltm.CategoryFact, report.Clusters[1].Category)
+	assert.Equal(t, "The user lives in Berlin.", report.Clusters[1].Merged)
+	assert.True(t, report.Clusters[1].LLMMerged)
+
+	require.Len(t, llm.prompts, 1)
+	assert.Contains(t, llm.prompts[0], "The user now lives in Berlin\n- [")
+	assert.ElementsMatch(t, []string{
+		"i drink green tea every morning",
+		"I own a cat named Miso",
+		"I drink green tea every morning",
+		`{"category":"fact","content":"The user lives in Berlin."}`,
+	}, backend.contents())
+}
+
+func TestConsolidateWithoutModelKeepsNewest(t *testing.T) {
+	backend := seed()
+	report, err := newConsolidator(t, backend, Config{}).Consolidate(t.Context(), "user")
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Removed)
+	assert.Contains(t, backend.contents(), `{"category":"fact","content":"The user now lives in Berlin"}`)
+	assert.Len(t, backend.contents(), 4)
+}
+
+func TestConsolidateSkipsUnknownApps(t *testing.T) {
+	llm := &fakeLLM{}
+	backend := &memoryBackend{}
+	backend.add("", `{"category":"fact","content":"The user lives in Paris"}`, 48*time.Hour)
+	backend.add("", `{"category":"fact","content":"The user now lives in Berlin"}`, 2*time.Hour)
+	for _, item := range backend.items {
+		delete(item.Metadata, "app_name")
+	}
+	report, err := newConsolidator(t, backend, Config{Model: llm}).Consolidate(t.Context(), "user")
+	require.NoError(t, err)
+	assert.Empty(t, report.Clusters)
+	assert.Empty(t, llm.prompts)
+	assert.Len(t, backend.contents(), 2)
+}
+
+func TestConsolidateDryRun(t *testing.T) {
+	llm := &fakeLLM{}
+	backend := seed()
+	report, err := newConsolidator(t, backend, Config{Model: llm, DryRun: true}).Consolidate(t.Context(), "user")
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Removed)
+	assert.Len(t, report.Clusters, 2)
+	assert.Len(t, backend.contents(), 6)
+}
+
+func TestSchedule(t *testing.T) {
+	_, err := New(Config{})
+	assert.ErrorIs(t, err, ErrNoBackend)
+
+	backend := seed()
+	assert.ErrorIs(t, newConsolidator(t, backend, Config{}).Start(), ErrNoSchedule)
+
+	reports := make(chan *Report, 10)
+	c := newConsolidator(t, backend, Config{
+		Interval:        10 * time.Millisecond,
+		RequestInterval: time.Millisecond,
+		Users:           func(context.Context) ([]string, error) { return []string{"user"}, nil },
+		OnReport:        func(report *Report) { reports <- report },
+	})
+	require.NoError(t, c.Start())
+	assert.ErrorIs(t, c.Start(), ErrStarted)
+
+	first := <-reports
+	assert.Equal(t, 2, first.Removed)
+	second := <-reports
+	assert.Equal(t, 0, second.Removed)
+	require.NoError(t, c.Close())
+}
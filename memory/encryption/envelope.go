@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// ciphertextPrefix marks the encrypted values, so that values stored before encryption was enabled are still
+	// read as they are.
+	ciphertextPrefix = "veadk:enc:"
+	// formatVersion is the version of the ciphertext layout:
+	// version | len(keyID) | keyID | len(wrapped) uint16 | wrapped data key | nonce | sealed value.
+	formatVersion byte = 1
+	// maxDataKeyUses bounds the values encrypted with one data key, far below the 2^32 random nonces AES-GCM
+	// allows per key.
+	maxDataKeyUses = 1 << 24
+	// maxCachedDataKeys bounds the decrypted data keys kept in memory.
+	maxCachedDataKeys = 1024
+)
+
+var ErrInvalidCiphertext = errors.New("invalid session ciphertext")
+
+type dataKey struct {
+	keyID   string
+	wrapped []byte
+	aead    cipher.AEAD
+	uses    int
+}
+
+// envelope encrypts values with a data key, itself encrypted by the key provider and stored with every value.
+// A data key is used until the current key of the provider changes, so the provider is not called per value.
+type envelope struct {
+	keys KeyProvider
+
+	mu      sync.Mutex
+	current *dataKey
+	cache   map[string]cipher.AEAD
+}
+
+func newEnvelope(keys KeyProvider) *envelope {
+	return &envelope{keys: keys, cache: map[string]cipher.AEAD{}}
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix)
+}
+
+func (e *envelope) encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	header := make([]byte, 0, 4+len(key.keyID)+len(key.wrapped))
+	header = append(header, formatVersion, byte(len(key.keyID)))
+	header = append(header, key.keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(key.wrapped)))
+	header = append(header, key.wrapped...)
+	sealed := seal(key.aead, plaintext, header)
+	return ciphertextPrefix + base64.RawURLEncoding.EncodeToString(append(header, sealed...)), nil
+}
+
+func (e *envelope) decrypt(ctx context.Context, value string) ([]byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, ciphertextPrefix))
+	if err != nil || len(raw) < 2 {
+		return nil, ErrInvalidCiphertext
+	}
+	if raw[0] != formatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCiphertext, raw[0])
+	}
+	keyEnd := 2 + int(raw[1])
+	if len(raw) < keyEnd+2 {
+		return nil, ErrInvalidCiphertext
+	}
+	keyID := string(raw[2:keyEnd])
+	wrappedEnd := keyEnd + 2 + int(binary.BigEndian.Uint16(raw[keyEnd:]))
+	if len(raw) < wrappedEnd {
+		return nil, ErrInvalidCiphertext
+	}
+	aead, err := e.unwrap(ctx, keyID, raw[keyEnd+2:wrappedEnd])
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, raw[wrappedEnd:], raw[:wrappedEnd])
+}
+
+// dataKey returns the data key encrypting new values, creating one when the current key of the provider changed.
+func (e *envelope) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	keyID := e.keys.CurrentKeyID()
+	if e.current != nil && e.current.keyID == keyID && e.current.uses < maxDataKeyUses {
+		e.current.uses++
+		return e.current, nil
+	}
+	if len(keyID) > 255 {
+		return nil, fmt.Errorf("encryption key id %q is longer than 255 bytes", keyID)
+	}
+	plain := randomBytes(keySize)
+	wrapped, err := e.keys.EncryptDataKey(ctx, keyID, plain)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt data key failed: %w", err)
+	}
+	if len(wrapped) > 0xffff {
+		return nil, fmt.Errorf("encrypted data key is longer than %d bytes", 0xffff)
+	}
+	aead, err := newAEAD(plain)
+	if err != nil {
+		return nil, err
+	}
+	e.current = &dataKey{keyID: keyID, wrapped: wrapped, aead: aead, uses: 1}
+	e.store(keyID, wrapped, aead)
+	return e.current, nil
+}
+
+// unwrap returns the data key of a value, asking the provider to decrypt it unless it is cached.
+func (e *envelope) unwrap(ctx context.Context, keyID string, wrapped []byte) (cipher.AEAD, error) {
+	e.mu.Lock()
+	aead, ok := e.cache[cacheKey(keyID, wrapped)]
+	e.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+	plain, err := e.keys.DecryptDataKey(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt data key failed: %w", err)
+	}
+	aead, err = newAEAD(plain)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.store(keyID, wrapped, aead)
+	e.mu.Unlock()
+	return aead, nil
+}
+
+// store caches a data key. e.mu must be held.
+func (e *envelope) store(keyID string, wrapped []byte, aead cipher.AEAD) {
+	if len(e.cache) >= maxCachedDataKeys {
+		clear(e.cache)
+	}
+	e.cache[cacheKey(keyID, wrapped)] = aead
+}
+
+func cacheKey(keyID string, wrapped []byte) string {
+	return keyID + "\x00" + string(wrapped)
+}
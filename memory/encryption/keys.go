@@ -0,0 +1,209 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultKeyEnv is the environment variable read by NewEnvKeyProvider when no name is given.
+const DefaultKeyEnv = "VEADK_SESSION_ENCRYPTION_KEYS"
+
+var (
+	ErrNoKeys     = errors.New("no encryption keys")
+	ErrInvalidKey = errors.New("encryption keys must be 32 bytes, base64 encoded")
+	ErrUnknownKey = errors.New("unknown encryption key")
+)
+
+// KMS encrypts small secrets, the data keys, with master keys it never discloses, like a cloud key management
+// service.
+type KMS interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KeyProvider encrypts the data keys of the encrypted session service. The ID of the key encrypting a data key is
+// stored with every value, so rotating keys only requires changing CurrentKeyID while the old keys can still
+// decrypt.
+type KeyProvider interface {
+	// CurrentKeyID names the key encrypting new data keys.
+	CurrentKeyID() string
+	EncryptDataKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+type kmsKeyProvider struct {
+	kms   KMS
+	keyID string
+}
+
+// NewKMSKeyProvider encrypts the data keys with the keyID master key of a KMS.
+func NewKMSKeyProvider(kms KMS, keyID string) KeyProvider {
+	return &kmsKeyProvider{kms: kms, keyID: keyID}
+}
+
+func (p *kmsKeyProvider) CurrentKeyID() string {
+	return p.keyID
+}
+
+func (p *kmsKeyProvider) EncryptDataKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	return p.kms.Encrypt(ctx, keyID, dataKey)
+}
+
+func (p *kmsKeyProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	return p.kms.Decrypt(ctx, keyID, encrypted)
+}
+
+type localKMS struct {
+	keys map[string]cipher.AEAD
+}
+
+// NewLocalKMS is a KMS holding its master keys in memory, to stand in for a real one in development and tests,
+// and to back the key file and environment providers.
+func NewLocalKMS(keys map[string][]byte) (KMS, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	kms := &localKMS{keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		kms.keys[id] = aead
+	}
+	return kms, nil
+}
+
+func (k *localKMS) Encrypt(_ context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return seal(aead, plaintext, []byte(keyID)), nil
+}
+
+func (k *localKMS) Decrypt(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return open(aead, ciphertext, []byte(keyID))
+}
+
+// NewKeyFileProvider reads the master keys from a file with one "id:base64-key" line per key. The first key is
+// the current one; to rotate keys, add a new first line and keep the old keys below it. Empty lines and lines
+// starting with # are ignored.
+func NewKeyFileProvider(path string) (KeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file failed: %w", err)
+	}
+	return parseKeys(string(data))
+}
+
+// NewEnvKeyProvider reads the master keys from an environment variable, DefaultKeyEnv when name is empty, in the
+// format of NewKeyFileProvider with the keys separated by commas or newlines.
+func NewEnvKeyProvider(name string) (KeyProvider, error) {
+	if name == "" {
+		name = DefaultKeyEnv
+	}
+	return parseKeys(strings.ReplaceAll(os.Getenv(name), ",", "\n"))
+}
+
+func parseKeys(text string) (KeyProvider, error) {
+	keys := map[string][]byte{}
+	current := ""
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("%w: want id:base64-key", ErrInvalidKey)
+		}
+		id = strings.TrimSpace(id)
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, ErrInvalidKey)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("duplicate encryption key %s", id)
+		}
+		keys[id] = key
+		if current == "" {
+			current = id
+		}
+	}
+	kms, err := NewLocalKMS(keys)
+	if err != nil {
+		return nil, err
+	}
+	return NewKMSKeyProvider(kms, current), nil
+}
+
+// GenerateKey returns a new random master key, base64 encoded for a key file or environment variable.
+func GenerateKey() string {
+	return base64.StdEncoding.EncodeToString(randomBytes(keySize))
+}
+
+const keySize = 32
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, prepended to the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := randomBytes(aead.NonceSize())
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return plaintext, nil
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never fails
+	_, _ = rand.Read(b)
+	return b
+}
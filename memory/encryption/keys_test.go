@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFileProvider(t *testing.T) {
+	v1, v2 := GenerateKey(), GenerateKey()
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte("# rotated in May\nv2:"+v2+"\n\nv1:"+v1+"\n"), 0o600))
+	keys, err := NewKeyFileProvider(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", keys.CurrentKeyID())
+
+	for _, id := range []string{"v1", "v2"} {
+		wrapped, err := keys.EncryptDataKey(t.Context(), id, []byte("data key"))
+		require.NoError(t, err)
+		plain, err := keys.DecryptDataKey(t.Context(), id, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, "data key", string(plain))
+
+		_, err = keys.DecryptDataKey(t.Context(), map[string]string{"v1": "v2", "v2": "v1"}[id], wrapped)
+		assert.ErrorIs(t, err, ErrInvalidCiphertext, "a data key is bound to its key id")
+	}
+	_, err = keys.EncryptDataKey(t.Context(), "v3", []byte("data key"))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestEnvKeyProvider(t *testing.T) {
+	t.Setenv(DefaultKeyEnv, "k2:"+GenerateKey()+", k1:"+GenerateKey())
+	keys, err := NewEnvKeyProvider("")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keys.CurrentKeyID())
+
+	for name, value := range map[string]string{
+		"empty":      "",
+		"no id":      GenerateKey(),
+		"short key":  "k1:" + base64.StdEncoding.EncodeToString([]byte("too short")),
+		"not base64": "k1:" + strings.Repeat("!", 44),
+	} {
+		t.Setenv("TEST_SESSION_KEYS", value)
+		_, err := NewEnvKeyProvider("TEST_SESSION_KEYS")
+		assert.Error(t, err, name)
+	}
+	t.Setenv("TEST_SESSION_KEYS", "k1:"+GenerateKey()+",k1:"+GenerateKey())
+	_, err = NewEnvKeyProvider("TEST_SESSION_KEYS")
+	assert.ErrorContains(t, err, "duplicate")
+}
@@ -0,0 +1,416 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryption encrypts session events and state at rest. NewService wraps any session.Service, such as
+// the one returned by memory.NewShortTermMemoryService, so that its backend only ever stores ciphertext:
+//
+//	keys, err := encryption.NewEnvKeyProvider("")
+//	backend, err := memory.NewShortTermMemoryService(memory.BackendShortTermPostgreSQL, nil)
+//	sessionService, err := encryption.NewService(backend, keys)
+//
+// The content of the events and the values of the state are encrypted with AES-GCM. The state keys stay in clear,
+// since the backends route them by their app:, user: and temp: prefixes.
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+var (
+	ErrNoSessionService = errors.New("encryption requires a session service")
+	ErrNoKeyProvider    = errors.New("encryption requires a key provider")
+)
+
+// Service encrypts the sessions of the session.Service it wraps.
+type Service struct {
+	service  session.Service
+	envelope *envelope
+}
+
+// NewService encrypts the sessions stored by service with data keys protected by keys. Values stored before
+// encryption was enabled are read as they are. Decrypted state values are the JSON decoding of the original
+// values, e.g. numbers come back as float64, as with the database backends.
+func NewService(service session.Service, keys KeyProvider) (*Service, error) {
+	if service == nil {
+		return nil, ErrNoSessionService
+	}
+	if keys == nil {
+		return nil, ErrNoKeyProvider
+	}
+	return &Service{service: service, envelope: newEnvelope(keys)}, nil
+}
+
+// Unwrap returns the wrapped service, e.g. to purge users from a *short_term_memory_backends.PostgresqlService.
+func (s *Service) Unwrap() session.Service {
+	return s.service
+}
+
+// Close closes the wrapped service when it is an io.Closer.
+func (s *Service) Close() error {
+	if closer, ok := s.service.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *Service) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	state, err := s.encryptValues(ctx, req.State)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := *req
+	encrypted.State = state
+	resp, err := s.service.Create(ctx, &encrypted)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.wrap(ctx, resp.Session)
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: sess}, nil
+}
+
+func (s *Service) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	resp, err := s.service.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.wrap(ctx, resp.Session)
+	if err != nil {
+		return nil, err
+	}
+	return &session.GetResponse{Session: sess}, nil
+}
+
+func (s *Service) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	resp, err := s.service.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]session.Session, 0, len(resp.Sessions))
+	for _, inner := range resp.Sessions {
+		sess, err := s.wrap(ctx, inner)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+func (s *Service) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	return s.service.Delete(ctx, req)
+}
+
+// AppendEvent encrypts a copy of event; event itself is left in clear.
+func (s *Service) AppendEvent(ctx context.Context, cur session.Session, event *session.Event) error {
+	sess, ok := cur.(*encryptedSession)
+	if !ok {
+		return fmt.Errorf("session %T was not returned by the encrypted session service", cur)
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	encrypted, err := s.encryptEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	if err := s.service.AppendEvent(ctx, sess.inner, encrypted); err != nil {
+		return err
+	}
+	sess.remember(encrypted, event)
+	return nil
+}
+
+// wrap decrypts a session of the wrapped service, failing when any of its values cannot be decrypted.
+func (s *Service) wrap(ctx context.Context, inner session.Session) (*encryptedSession, error) {
+	sess := &encryptedSession{inner: inner, service: s, plain: map[*session.Event]*session.Event{}, values: map[string]any{}}
+	for key, value := range inner.State().All() {
+		if _, err := sess.value(ctx, value); err != nil {
+			return nil, fmt.Errorf("decrypt state %s of session %s failed: %w", key, inner.ID(), err)
+		}
+	}
+	for event := range inner.Events().All() {
+		plain, err := s.decryptEvent(ctx, event)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt event %s of session %s failed: %w", event.ID, inner.ID(), err)
+		}
+		sess.plain[event] = plain
+	}
+	return sess, nil
+}
+
+func (s *Service) encryptEvent(ctx context.Context, event *session.Event) (*session.Event, error) {
+	encrypted := *event
+	if event.Content != nil {
+		data, err := json.Marshal(event.Content)
+		if err != nil {
+			return nil, fmt.Errorf("encode event content failed: %w", err)
+		}
+		text, err := s.envelope.encrypt(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		encrypted.Content = &genai.Content{Role: event.Content.Role, Parts: []*genai.Part{{Text: text}}}
+	}
+	delta, err := s.encryptValues(ctx, event.Actions.StateDelta)
+	if err != nil {
+		return nil, err
+	}
+	encrypted.Actions.StateDelta = delta
+	return &encrypted, nil
+}
+
+// decryptEvent returns event itself when nothing in it is encrypted.
+func (s *Service) decryptEvent(ctx context.Context, event *session.Event) (*session.Event, error) {
+	text, encryptedContent := encryptedText(event.Content)
+	encryptedDelta := false
+	for _, value := range event.Actions.StateDelta {
+		if v, ok := value.(string); ok && isEncrypted(v) {
+			encryptedDelta = true
+			break
+		}
+	}
+	if !encryptedContent && !encryptedDelta {
+		return event, nil
+	}
+
+	plain := *event
+	if encryptedContent {
+		data, err := s.envelope.decrypt(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		var content genai.Content
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("decode event content failed: %w", err)
+		}
+		plain.Content = &content
+	}
+	if encryptedDelta {
+		delta := make(map[string]any, len(event.Actions.StateDelta))
+		for key, value := range event.Actions.StateDelta {
+			v, err := s.decryptValue(ctx, value)
+			if err != nil {
+				return nil, err
+			}
+			delta[key] = v
+		}
+		plain.Actions.StateDelta = delta
+	}
+	return &plain, nil
+}
+
+// encryptedText returns the ciphertext of a content encrypted by encryptEvent.
+func encryptedText(content *genai.Content) (string, bool) {
+	if content == nil || len(content.Parts) != 1 || content.Parts[0] == nil {
+		return "", false
+	}
+	text := content.Parts[0].Text
+	return text, isEncrypted(text)
+}
+
+func (s *Service) encryptValues(ctx context.Context, values map[string]any) (map[string]any, error) {
+	if values == nil {
+		return nil, nil
+	}
+	encrypted := make(map[string]any, len(values))
+	for key, value := range values {
+		v, err := s.encryptValue(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt state %s failed: %w", key, err)
+		}
+		encrypted[key] = v
+	}
+	return encrypted, nil
+}
+
+func (s *Service) encryptValue(ctx context.Context, value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return s.envelope.encrypt(ctx, data)
+}
+
+func (s *Service) decryptValue(ctx context.Context, value any) (any, error) {
+	text, ok := value.(string)
+	if !ok || !isEncrypted(text) {
+		return value, nil
+	}
+	data, err := s.envelope.decrypt(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	var plain any
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, fmt.Errorf("decode state value failed: %w", err)
+	}
+	return plain, nil
+}
+
+// encryptedSession shows the session of the wrapped service in clear.
+type encryptedSession struct {
+	inner   session.Session
+	service *Service
+
+	mu sync.Mutex
+	// plain maps the events of inner to their decryption.
+	plain map[*session.Event]*session.Event
+	// values maps the encrypted state values of inner to their decryption.
+	values map[string]any
+}
+
+func (s *encryptedSession) ID() string {
+	return s.inner.ID()
+}
+
+func (s *encryptedSession) AppName() string {
+	return s.inner.AppName()
+}
+
+func (s *encryptedSession) UserID() string {
+	return s.inner.UserID()
+}
+
+func (s *encryptedSession) State() session.State {
+	return &encryptedState{inner: s.inner.State(), session: s}
+}
+
+func (s *encryptedSession) Events() session.Events {
+	return &encryptedEvents{session: s}
+}
+
+func (s *encryptedSession) LastUpdateTime() time.Time {
+	return s.inner.LastUpdateTime()
+}
+
+func (s *encryptedSession) remember(encrypted, plain *session.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.plain[encrypted] = plain
+}
+
+// decrypted returns the decryption of an event of inner. Events appended by other writers are decrypted
+// on first use; the ones failing to decrypt are returned as they are.
+func (s *encryptedSession) decrypted(event *session.Event) *session.Event {
+	s.mu.Lock()
+	plain, ok := s.plain[event]
+	s.mu.Unlock()
+	if ok {
+		return plain
+	}
+	plain, err := s.service.decryptEvent(context.Background(), event)
+	if err != nil {
+		log.Warn("decrypt session event failed", "session", s.inner.ID(), "event", event.ID, "error", err)
+		return event
+	}
+	s.remember(event, plain)
+	return plain
+}
+
+// value returns the decryption of a state value of inner, decrypting each ciphertext once.
+func (s *encryptedSession) value(ctx context.Context, value any) (any, error) {
+	text, ok := value.(string)
+	if !ok || !isEncrypted(text) {
+		return value, nil
+	}
+	s.mu.Lock()
+	plain, ok := s.values[text]
+	s.mu.Unlock()
+	if ok {
+		return plain, nil
+	}
+	plain, err := s.service.decryptValue(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.values[text] = plain
+	s.mu.Unlock()
+	return plain, nil
+}
+
+type encryptedEvents struct {
+	session *encryptedSession
+}
+
+func (e *encryptedEvents) All() iter.Seq[*session.Event] {
+	return func(yield func(*session.Event) bool) {
+		for event := range e.session.inner.Events().All() {
+			if !yield(e.session.decrypted(event)) {
+				return
+			}
+		}
+	}
+}
+
+func (e *encryptedEvents) Len() int {
+	return e.session.inner.Events().Len()
+}
+
+func (e *encryptedEvents) At(i int) *session.Event {
+	return e.session.decrypted(e.session.inner.Events().At(i))
+}
+
+type encryptedState struct {
+	inner   session.State
+	session *encryptedSession
+}
+
+func (s *encryptedState) Get(key string) (any, error) {
+	value, err := s.inner.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.session.value(context.Background(), value)
+}
+
+func (s *encryptedState) Set(key string, value any) error {
+	encrypted, err := s.session.service.encryptValue(context.Background(), value)
+	if err != nil {
+		return err
+	}
+	return s.inner.Set(key, encrypted)
+}
+
+// All skips the values failing to decrypt.
+func (s *encryptedState) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for key, value := range s.inner.All() {
+			plain, err := s.session.value(context.Background(), value)
+			if err != nil {
+				log.Warn("decrypt session state failed", "key", key, "error", err)
+				continue
+			}
+			if !yield(key, plain) {
+				return
+			}
+		}
+	}
+}
+
+var _ session.Service = (*Service)(nil)
@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/memory"
+	"github.com/volcengine/veadk-go/memory/short_term_memory_backends"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+)
+
+// rotatingKeys is a local KMS whose current key can be changed, counting the data keys it encrypts and decrypts.
+type rotatingKeys struct {
+	KMS
+	current atomic.Value
+	wraps   atomic.Int32
+	unwraps atomic.Int32
+}
+
+func newRotatingKeys(t *testing.T, ids ...string) *rotatingKeys {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, id := range ids {
+		keys[id] = randomBytes(keySize)
+	}
+	kms, err := NewLocalKMS(keys)
+	require.NoError(t, err)
+	k := &rotatingKeys{KMS: kms}
+	k.current.Store(ids[0])
+	return k
+}
+
+func (k *rotatingKeys) CurrentKeyID() string {
+	return k.current.Load().(string)
+}
+
+func (k *rotatingKeys) EncryptDataKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	k.wraps.Add(1)
+	return k.Encrypt(ctx, keyID, dataKey)
+}
+
+func (k *rotatingKeys) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	k.unwraps.Add(1)
+	return k.Decrypt(ctx, keyID, encrypted)
+}
+
+func textEvent(author, text string, delta map[string]any) *session.Event {
+	event := session.NewEvent("invocation")
+	event.Author = author
+	event.Content = genai.NewContentFromText(text, genai.RoleUser)
+	maps.Copy(event.Actions.StateDelta, delta)
+	return event
+}
+
+func stateOf(s session.Session) map[string]any {
+	state := map[string]any{}
+	maps.Insert(state, s.State().All())
+	return state
+}
+
+func TestEnvelope(t *testing.T) {
+	keys := newRotatingKeys(t, "v1", "v2")
+	e := newEnvelope(keys)
+	first, err := e.encrypt(t.Context(), []byte("alice@example.com"))
+	require.NoError(t, err)
+	second, err := e.encrypt(t.Context(), []byte("alice@example.com"))
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(first))
+	assert.NotContains(t, first, "alice")
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, int32(1), keys.wraps.Load(), "the data key is reused")
+
+	keys.current.Store("v2")
+	rotated, err := e.encrypt(t.Context(), []byte("bob"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), keys.wraps.Load(), "a rotation creates a data key")
+
+	// a fresh envelope, as in another process, decrypts both key versions
+	fresh := newEnvelope(keys)
+	for value, want := range map[string]string{first: "alice@example.com", rotated: "bob"} {
+		plain, err := fresh.decrypt(t.Context(), value)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(plain))
+	}
+	_, err = fresh.decrypt(t.Context(), second)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), keys.unwraps.Load(), "decrypted data keys are cached")
+
+	tampered := []byte(first)
+	tampered[len(tampered)-3] ^= 'A' ^ 'B'
+	_, err = fresh.decrypt(t.Context(), string(tampered))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = fresh.decrypt(t.Context(), ciphertextPrefix+"AQ")
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestService(t *testing.T) {
+	ctx := t.Context()
+	backend := session.InMemoryService()
+	service, err := NewService(backend, newRotatingKeys(t, "v1"))
+	require.NoError(t, err)
+
+	created, err := service.Create(ctx, &session.CreateRequest{
+		AppName: "app", UserID: "user", SessionID: "s",
+		State: map[string]any{"email": "alice@example.com", "user:age": 42, "app:tips": []any{"a"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"email": "alice@example.com", "user:age": float64(42), "app:tips": []any{"a"}}, stateOf(created.Session))
+
+	event := textEvent("user", "my card is 4111 1111 1111 1111", map[string]any{"card": "4111", "temp:step": 1})
+	require.NoError(t, service.AppendEvent(ctx, created.Session, event))
+	assert.Equal(t, "my card is 4111 1111 1111 1111", event.Content.Parts[0].Text, "the appended event stays in clear")
+	require.Equal(t, 1, created.Session.Events().Len())
+	assert.Same(t, event, created.Session.Events().At(0))
+	card, err := created.Session.State().Get("card")
+	require.NoError(t, err)
+	assert.Equal(t, "4111", card)
+
+	// the backend only holds ciphertext
+	raw, err := backend.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	for key, value := range raw.Session.State().All() {
+		assert.True(t, isEncrypted(value.(string)), key)
+	}
+	rawEvent := raw.Session.Events().At(0)
+	assert.True(t, isEncrypted(rawEvent.Content.Parts[0].Text))
+	assert.Equal(t, "user", rawEvent.Content.Role)
+	assert.True(t, isEncrypted(rawEvent.Actions.StateDelta["card"].(string)))
+
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, "my card is 4111 1111 1111 1111", got.Session.Events().At(0).Content.Parts[0].Text)
+	assert.Equal(t, map[string]any{"card": "4111"}, got.Session.Events().At(0).Actions.StateDelta)
+	assert.Equal(t, "4111", stateOf(got.Session)["card"])
+	// state values are decrypted once per session, when it is read
+	tips, err := got.Session.State().Get("app:tips")
+	require.NoError(t, err)
+	assert.Same(t, &tips.([]any)[0], &stateOf(got.Session)["app:tips"].([]any)[0])
+
+	listed, err := service.List(ctx, &session.ListRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 1)
+	assert.Equal(t, "alice@example.com", stateOf(listed.Sessions[0])["email"])
+
+	// sessions stored before encryption was enabled are read as they are
+	_, err = backend.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "legacy", State: map[string]any{"plain": "yes"}})
+	require.NoError(t, err)
+	legacy, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "legacy"})
+	require.NoError(t, err)
+	assert.Equal(t, "yes", stateOf(legacy.Session)["plain"])
+
+	other, err := NewService(backend, newRotatingKeys(t, "v1"))
+	require.NoError(t, err)
+	_, err = other.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	assert.ErrorIs(t, err, ErrInvalidCiphertext, "another key cannot decrypt")
+
+	assert.ErrorContains(t, service.AppendEvent(ctx, raw.Session, textEvent("user", "hi", nil)), "not returned by the encrypted")
+}
+
+func TestServiceWrapsShortTermMemory(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	backend, err := memory.NewShortTermMemoryService(memory.BackendShortTermSQLite, &short_term_memory_backends.SqliteBackendConfig{
+		SqliteConfig: &configs.SqliteConfig{Path: path},
+	})
+	require.NoError(t, err)
+	t.Setenv(DefaultKeyEnv, "k1:"+GenerateKey())
+	keys, err := NewEnvKeyProvider("")
+	require.NoError(t, err)
+	service, err := NewService(backend, keys)
+	require.NoError(t, err)
+
+	echo, err := agent.New(agent.Config{
+		Name: "echo",
+		Run: func(ictx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				var texts []string
+				for event := range ictx.Session().Events().All() {
+					texts = append(texts, event.Content.Parts[0].Text)
+				}
+				event := session.NewEvent(ictx.InvocationID())
+				event.Author = "echo"
+				event.Content = genai.NewContentFromText(strings.Join(texts, "|"), genai.RoleModel)
+				event.Actions.StateDelta["user:secret"] = "s3cret"
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "app", Agent: echo, SessionService: service})
+	require.NoError(t, err)
+	created, err := service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+
+	var answers []string
+	for _, message := range []string{"hello", "again"} {
+		for event, err := range r.Run(ctx, "user", "s", genai.NewContentFromText(message, genai.RoleUser), agent.RunConfig{}) {
+			require.NoError(t, err)
+			answers = append(answers, event.Content.Parts[0].Text)
+		}
+	}
+	assert.Equal(t, []string{"hello", "hello|hello|again"}, answers)
+
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	assert.Equal(t, 4, got.Session.Events().Len())
+	assert.Equal(t, "s3cret", stateOf(got.Session)["user:secret"])
+
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	for _, query := range []string{"SELECT content FROM events", "SELECT state FROM user_states"} {
+		var values []string
+		require.NoError(t, db.Raw(query).Scan(&values).Error)
+		require.NotEmpty(t, values)
+		for _, value := range values {
+			assert.NotContains(t, value, "hello")
+			assert.NotContains(t, value, "s3cret")
+			assert.Contains(t, value, ciphertextPrefix)
+		}
+	}
+}
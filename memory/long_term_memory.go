@@ -24,10 +24,12 @@ import (
 type LongTermBackendType string
 
 const (
-	BackendLongTermLocal  LongTermBackendType = "local"
-	BackendLongTermViking LongTermBackendType = "viking"
-	BackendLongTermMem0   LongTermBackendType = "mem0"
-	DefaultTopK                               = 5
+	BackendLongTermLocal       LongTermBackendType = "local"
+	BackendLongTermLocalVector LongTermBackendType = "local_vector"
+	BackendLongTermPgVector    LongTermBackendType = "pgvector"
+	BackendLongTermViking      LongTermBackendType = "viking"
+	BackendLongTermMem0        LongTermBackendType = "mem0"
+	DefaultTopK                                    = 5
 )
 
 // NewLongTermMemoryService creates a new long term memory service.
@@ -47,6 +49,34 @@ func NewLongTermMemoryService(backend LongTermBackendType, config interface{}, t
 	switch backend {
 	case BackendLongTermLocal:
 		memoryService = memory.InMemoryService()
+	case BackendLongTermLocalVector:
+		var localConfig *long_term_memory_backends.LocalVectorMemoryConfig
+		if config != nil {
+			var ok bool
+			localConfig, ok = config.(*long_term_memory_backends.LocalVectorMemoryConfig)
+			if !ok {
+				return nil, fmt.Errorf("local_vector backend requires *LocalVectorMemoryConfig, got %T", config)
+			}
+		}
+		localBackend, err := long_term_memory_backends.NewLocalVectorMemoryBackend(localConfig)
+		if err != nil {
+			return nil, err
+		}
+		return long_term_memory_backends.LongTermMemoryFactory(localBackend, topK[0]), nil
+	case BackendLongTermPgVector:
+		var pgConfig *long_term_memory_backends.PgVectorMemoryConfig
+		if config != nil {
+			var ok bool
+			pgConfig, ok = config.(*long_term_memory_backends.PgVectorMemoryConfig)
+			if !ok {
+				return nil, fmt.Errorf("pgvector backend requires *PgVectorMemoryConfig, got %T", config)
+			}
+		}
+		pgBackend, err := long_term_memory_backends.NewPgVectorMemoryBackend(pgConfig)
+		if err != nil {
+			return nil, err
+		}
+		return long_term_memory_backends.LongTermMemoryFactory(pgBackend, topK[0]), nil
 	case BackendLongTermViking:
 		var vikingDBMemoryConfig *long_term_memory_backends.VikingDbMemoryConfig
 		if config == nil {
@@ -0,0 +1,238 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// StorageMode decides what AddSession stores through LongTermMemoryBackend.SaveMemory.
+type StorageMode string
+
+const (
+	// StorageRaw stores the user messages as they are. It is the default.
+	StorageRaw StorageMode = "raw"
+	// StorageExtracted stores the memories an Extractor distills from the session.
+	StorageExtracted StorageMode = "extracted"
+	// StorageBoth stores the user messages and the extracted memories.
+	StorageBoth StorageMode = "both"
+)
+
+// MemoryCategory types an extracted memory.
+type MemoryCategory string
+
+const (
+	// CategoryFact is a durable fact about the user or their world, e.g. "The user works at a bakery".
+	CategoryFact MemoryCategory = "fact"
+	// CategoryPreference is a like, dislike or way the user wants things done.
+	CategoryPreference MemoryCategory = "preference"
+	// CategoryEpisode summarizes what happened in the conversation.
+	CategoryEpisode MemoryCategory = "episode"
+)
+
+var ErrNoExtractor = errors.New("extracted storage requires an extractor")
+
+// ExtractedMemory is an atomic memory distilled from a session. It is saved as its JSON encoding.
+type ExtractedMemory struct {
+	Category MemoryCategory `json:"category"`
+	Content  string         `json:"content"`
+}
+
+// Extractor distills the memories worth keeping from a session.
+type Extractor interface {
+	Extract(ctx context.Context, s session.Session) ([]ExtractedMemory, error)
+}
+
+type Option func(*basicLongTermMemory)
+
+// WithStorageMode selects what is stored. StorageExtracted and StorageBoth require WithExtractor.
+func WithStorageMode(mode StorageMode) Option {
+	return func(b *basicLongTermMemory) {
+		b.storageMode = mode
+	}
+}
+
+func WithExtractor(extractor Extractor) Option {
+	return func(b *basicLongTermMemory) {
+		b.extractor = extractor
+	}
+}
+
+// WithSharedAcrossApps lets every app search the memories saved by the other apps of the same user, instead of
+// isolating the memories of each app.
+func WithSharedAcrossApps() Option {
+	return func(b *basicLongTermMemory) {
+		b.sharedApps = true
+	}
+}
+
+const defaultExtractionInstruction = `You maintain the long-term memory of an assistant. Read the conversation and extract what is worth remembering about the user in future conversations:
+- "fact": durable facts about the user, their work, family, belongings or plans.
+- "preference": what the user likes, dislikes, or how they want things done.
+- "episode": one short summary of what the conversation was about and how it ended.
+Each memory must be a single self-contained sentence in the language of the conversation, referring to the user as "the user". Skip small talk, questions the assistant merely answered, and anything already obvious from other memories.
+Answer with a JSON array of {"category": "fact"|"preference"|"episode", "content": "..."} objects and nothing else; answer [] when nothing is worth remembering.`
+
+type LLMExtractorConfig struct {
+	// Model extracts the memories.
+	Model model.LLM
+	// Instruction replaces the default extraction instruction. It must keep the JSON answer format.
+	Instruction string
+}
+
+type llmExtractor struct {
+	config LLMExtractorConfig
+}
+
+// NewLLMExtractor asks a model to distill a session into facts, preferences and an episodic summary.
+func NewLLMExtractor(config LLMExtractorConfig) (Extractor, error) {
+	if config.Model == nil {
+		return nil, errors.New("llm extractor requires a model")
+	}
+	if config.Instruction == "" {
+		config.Instruction = defaultExtractionInstruction
+	}
+	return &llmExtractor{config: config}, nil
+}
+
+func (e *llmExtractor) Extract(ctx context.Context, s session.Session) ([]ExtractedMemory, error) {
+	conversation := transcript(s)
+	if conversation == "" {
+		return nil, nil
+	}
+	req := &model.LLMRequest{
+		Model:    e.config.Model.Name(),
+		Contents: []*genai.Content{genai.NewContentFromText(conversation, genai.RoleUser)},
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText(e.config.Instruction, genai.RoleUser),
+			Temperature:       genai.Ptr[float32](0),
+			ResponseMIMEType:  "application/json",
+			ResponseSchema: &genai.Schema{
+				Type: genai.TypeArray,
+				Items: &genai.Schema{
+					Type: genai.TypeObject,
+					Properties: map[string]*genai.Schema{
+						"category": {Type: genai.TypeString, Enum: []string{string(CategoryFact), string(CategoryPreference), string(CategoryEpisode)}},
+						"content":  {Type: genai.TypeString},
+					},
+					Required: []string{"category", "content"},
+				},
+			},
+		},
+	}
+
+	var text strings.Builder
+	for resp, err := range e.config.Model.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return nil, fmt.Errorf("extract memories failed: %w", err)
+		}
+		if resp == nil || resp.Partial || resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if part != nil && !part.Thought {
+				text.WriteString(part.Text)
+			}
+		}
+	}
+
+	answer := strings.TrimSpace(text.String())
+	answer = strings.TrimPrefix(answer, "```json")
+	answer = strings.TrimPrefix(answer, "```")
+	answer = strings.TrimSpace(strings.TrimSuffix(answer, "```"))
+	var extracted []ExtractedMemory
+	if err := json.Unmarshal([]byte(answer), &extracted); err != nil {
+		return nil, fmt.Errorf("extractor returned invalid memories %q: %w", answer, err)
+	}
+
+	memories := make([]ExtractedMemory, 0, len(extracted))
+	for _, m := range extracted {
+		m.Content = strings.TrimSpace(m.Content)
+		switch m.Category {
+		case CategoryFact, CategoryPreference, CategoryEpisode:
+		default:
+			m.Category = CategoryFact
+		}
+		if m.Content != "" {
+			memories = append(memories, m)
+		}
+	}
+	return memories, nil
+}
+
+// transcript renders the text of the session, one "author: text" line per event.
+func transcript(s session.Session) string {
+	var lines []string
+	for event := range s.Events().All() {
+		if event.Partial || event.Content == nil {
+			continue
+		}
+		var texts []string
+		for _, part := range event.Content.Parts {
+			if part != nil && part.Text != "" && !part.Thought {
+				texts = append(texts, part.Text)
+			}
+		}
+		if len(texts) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", event.Author, strings.Join(texts, "\n")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseExtractedMemory decodes an item saved from an ExtractedMemory.
+func parseExtractedMemory(item string) (ExtractedMemory, bool) {
+	var m ExtractedMemory
+	if err := json.Unmarshal([]byte(item), &m); err != nil || m.Category == "" || m.Content == "" {
+		return ExtractedMemory{}, false
+	}
+	return m, true
+}
+
+// extractedCategory returns the category of an item saved by AddSession, or "" for raw events.
+func extractedCategory(item string) MemoryCategory {
+	m, _ := parseExtractedMemory(item)
+	return m.Category
+}
+
+// memoryText returns the text to embed for an item saved by AddSession.
+func memoryText(item string) string {
+	if m, ok := parseExtractedMemory(item); ok {
+		return m.Content
+	}
+	if len(item) > 0 && item[0] == '{' {
+		var content genai.Content
+		if err := json.Unmarshal([]byte(item), &content); err == nil {
+			var texts []string
+			for _, part := range content.Parts {
+				if part != nil && part.Text != "" {
+					texts = append(texts, part.Text)
+				}
+			}
+			if len(texts) > 0 {
+				return strings.Join(texts, "\n")
+			}
+		}
+	}
+	return item
+}
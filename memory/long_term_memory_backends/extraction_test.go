@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/memory"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+type fakeLLM struct {
+	answer string
+	err    error
+	req    *model.LLMRequest
+}
+
+func (m *fakeLLM) Name() string { return "fake" }
+
+func (m *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		m.req = req
+		if m.err != nil {
+			yield(nil, m.err)
+			return
+		}
+		yield(&model.LLMResponse{Content: genai.NewContentFromText(m.answer, genai.RoleModel)}, nil)
+	}
+}
+
+// recordingBackend keeps saved items and returns them all on search.
+type recordingBackend struct {
+	saved   []string
+	apps    []string
+	filters []SearchFilter
+}
+
+func (b *recordingBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
+	b.saved = append(b.saved, eventList...)
+	b.apps = append(b.apps, appName)
+	return nil
+}
+
+func (b *recordingBackend) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	return nil, nil
+}
+
+func (b *recordingBackend) DeleteMemory(ctx context.Context, id string) error {
+	return nil
+}
+
+func (b *recordingBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	return nil
+}
+
+func (b *recordingBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error) {
+	b.filters = append(b.filters, filter)
+	items := make([]*MemItem, 0, len(b.saved))
+	for _, item := range b.saved {
+		items = append(items, &MemItem{Content: item})
+	}
+	return items, nil
+}
+
+func conversation(t *testing.T) session.Session {
+	t.Helper()
+	sessions := session.InMemoryService()
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	for _, turn := range []struct{ author, text string }{
+		{"user", "I just moved to Hangzhou, any tea recommendations?"},
+		{"assistant", "Try Longjing, it is grown right there."},
+	} {
+		event := session.NewEvent("past")
+		event.Author = turn.author
+		role := genai.RoleUser
+		if turn.author != "user" {
+			role = genai.RoleModel
+		}
+		event.Content = genai.NewContentFromText(turn.text, genai.Role(role))
+		require.NoError(t, sessions.AppendEvent(t.Context(), created.Session, event))
+	}
+	return created.Session
+}
+
+func search(t *testing.T, service memory.Service) []string {
+	t.Helper()
+	resp, err := service.Search(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "tea"})
+	require.NoError(t, err)
+	var texts []string
+	for _, m := range resp.Memories {
+		texts = append(texts, m.Content.Parts[0].Text)
+	}
+	return texts
+}
+
+func TestStorageModes(t *testing.T) {
+	answer := "```json\n" + `[
+		{"category": "fact", "content": "The user lives in Hangzhou."},
+		{"category": "preference", "content": "The user likes tea."},
+		{"category": "episode", "content": " "}
+	]` + "\n```"
+	tests := []struct {
+		name string
+		mode StorageMode
+		llm  *fakeLLM
+		want []string
+	}{
+		{
+			name: "raw",
+			mode: StorageRaw,
+			want: []string{"I just moved to Hangzhou, any tea recommendations?"},
+		},
+		{
+			name: "extracted",
+			mode: StorageExtracted,
+			llm:  &fakeLLM{answer: answer},
+			want: []string{"[fact] The user lives in Hangzhou.", "[preference] The user likes tea."},
+		},
+		{
+			name: "both",
+			mode: StorageBoth,
+			llm:  &fakeLLM{answer: answer},
+			want: []string{"I just moved to Hangzhou, any tea recommendations?", "[fact] The user lives in Hangzhou.", "[preference] The user likes tea."},
+		},
+		{
+			name: "both keeps raw when extraction fails",
+			mode: StorageBoth,
+			llm:  &fakeLLM{err: errors.New("model unavailable")},
+			want: []string{"I just moved to Hangzhou, any tea recommendations?"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithStorageMode(tt.mode)}
+			if tt.llm != nil {
+				extractor, err := NewLLMExtractor(LLMExtractorConfig{Model: tt.llm})
+				require.NoError(t, err)
+				opts = append(opts, WithExtractor(extractor))
+			}
+			service := LongTermMemoryFactory(&recordingBackend{}, 5, opts...)
+			require.NoError(t, service.AddSession(t.Context(), conversation(t)))
+			assert.Equal(t, tt.want, search(t, service))
+			if tt.llm != nil {
+				assert.Equal(t, "user: I just moved to Hangzhou, any tea recommendations?\nassistant: Try Longjing, it is grown right there.",
+					tt.llm.req.Contents[0].Parts[0].Text)
+			}
+		})
+	}
+}
+
+func TestExtractedStorageErrors(t *testing.T) {
+	service := LongTermMemoryFactory(&recordingBackend{}, 5, WithStorageMode(StorageExtracted))
+	assert.ErrorIs(t, service.AddSession(t.Context(), conversation(t)), ErrNoExtractor)
+
+	extractor, err := NewLLMExtractor(LLMExtractorConfig{Model: &fakeLLM{answer: "not json"}})
+	require.NoError(t, err)
+	service = LongTermMemoryFactory(&recordingBackend{}, 5, WithStorageMode(StorageExtracted), WithExtractor(extractor))
+	assert.ErrorContains(t, service.AddSession(t.Context(), conversation(t)), "invalid memories")
+}
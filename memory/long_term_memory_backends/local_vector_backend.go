@@ -0,0 +1,271 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/model"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLocalMemoryPath = "veadk_memory.db"
+	DefaultRecencyWeight   = 0.2
+	DefaultRecencyHalfLife = 30 * 24 * time.Hour
+)
+
+var ErrEmbeddingSize = errors.New("embedder returned a wrong number of vectors")
+
+type LocalVectorMemoryConfig struct {
+	// Path is the SQLite database file. Defaults to DefaultLocalMemoryPath.
+	Path string
+	// Embedder defaults to model.NewOpenAIEmbedder configured from the MODEL_EMBEDDING_* environment variables.
+	// Use model.NewHashEmbedder to work offline.
+	Embedder model.Embedder
+	// RecencyWeight blends recency into the cosine similarity: score = (1-w)*similarity + w*recency.
+	// Defaults to DefaultRecencyWeight; a negative value ranks by similarity only.
+	RecencyWeight float64
+	// RecencyHalfLife is the age at which the recency of a memory halves. Defaults to DefaultRecencyHalfLife.
+	RecencyHalfLife time.Duration
+}
+
+// localMemory is a row of the local vector store.
+type localMemory struct {
+	ID        string `gorm:"primaryKey;size:36"`
+	AppName   string `gorm:"index;size:256"`
+	UserID    string `gorm:"index;size:256"`
+	Category  string `gorm:"size:32"`
+	Content   string
+	Embedding []byte
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (localMemory) TableName() string {
+	return "veadk_memories"
+}
+
+// LocalVectorMemoryBackend keeps memories and their embeddings in a single SQLite file and searches them by brute
+// force, which is fine for development and small deployments.
+type LocalVectorMemoryBackend struct {
+	config *LocalVectorMemoryConfig
+	db     *gorm.DB
+	now    func() time.Time
+}
+
+func NewLocalVectorMemoryBackend(config *LocalVectorMemoryConfig) (LongTermMemoryBackend, error) {
+	if config == nil {
+		config = &LocalVectorMemoryConfig{}
+	}
+	if config.Path == "" {
+		config.Path = DefaultLocalMemoryPath
+	}
+	if config.Embedder == nil {
+		embedder, err := model.NewOpenAIEmbedder("", nil)
+		if err != nil {
+			return nil, err
+		}
+		config.Embedder = embedder
+	}
+	if config.RecencyWeight == 0 {
+		config.RecencyWeight = DefaultRecencyWeight
+	}
+	if config.RecencyWeight < 0 {
+		config.RecencyWeight = 0
+	}
+	config.RecencyWeight = math.Min(config.RecencyWeight, 1)
+	if config.RecencyHalfLife <= 0 {
+		config.RecencyHalfLife = DefaultRecencyHalfLife
+	}
+
+	db, err := gorm.Open(
+		sqlite.Open(config.Path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"),
+		&gorm.Config{Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("open local memory store %s failed: %w", config.Path, err)
+	}
+	if err := db.AutoMigrate(&localMemory{}); err != nil {
+		return nil, fmt.Errorf("migrate local memory store failed: %w", err)
+	}
+	return &LocalVectorMemoryBackend{config: config, db: db, now: time.Now}, nil
+}
+
+func (l *LocalVectorMemoryBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
+	if len(eventList) == 0 {
+		return nil
+	}
+	texts := make([]string, len(eventList))
+	for i, event := range eventList {
+		texts[i] = memoryText(event)
+	}
+	vectors, err := l.config.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed memories failed: %w", err)
+	}
+	if len(vectors) != len(eventList) {
+		return ErrEmbeddingSize
+	}
+
+	now := l.now()
+	rows := make([]*localMemory, len(eventList))
+	for i, event := range eventList {
+		rows[i] = &localMemory{
+			ID:        uuid.NewString(),
+			AppName:   appName,
+			UserID:    userId,
+			Category:  string(extractedCategory(event)),
+			Content:   event,
+			Embedding: encodeVector(vectors[i]),
+			CreatedAt: now,
+		}
+	}
+	if err := l.db.WithContext(ctx).Create(rows).Error; err != nil {
+		return fmt.Errorf("save local memories failed: %w", err)
+	}
+	log.Infof("Successfully saved user %s %d events to local memory", userId, len(eventList))
+	return nil
+}
+
+func (l *LocalVectorMemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error) {
+	vectors, err := l.config.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query failed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, ErrEmbeddingSize
+	}
+
+	db := l.db.WithContext(ctx).Where("user_id = ?", userId)
+	if filter.AppName != "" {
+		db = db.Where("(app_name = ? OR app_name = '')", filter.AppName)
+	}
+	if !filter.Since.IsZero() {
+		db = db.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		db = db.Where("created_at <= ?", filter.Until)
+	}
+	if len(filter.Categories) > 0 {
+		db = db.Where("category IN ?", filter.Categories)
+	}
+	var rows []*localMemory
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("search local memories failed: %w", err)
+	}
+
+	type scored struct {
+		row   *localMemory
+		score float64
+	}
+	now := l.now()
+	results := make([]scored, 0, len(rows))
+	for _, row := range rows {
+		similarity := model.CosineSimilarity(vectors[0], decodeVector(row.Embedding))
+		recency := math.Exp2(-now.Sub(row.CreatedAt).Hours() / l.config.RecencyHalfLife.Hours())
+		results = append(results, scored{row: row, score: (1-l.config.RecencyWeight)*similarity + l.config.RecencyWeight*recency})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+
+	memResp := make([]*MemItem, 0, len(results))
+	for _, r := range results {
+		item := r.row.item()
+		item.Metadata["score"] = r.score
+		memResp = append(memResp, item)
+	}
+	return memResp, nil
+}
+
+func (l *LocalVectorMemoryBackend) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	page = page.normalize()
+	var rows []*localMemory
+	err := l.db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at DESC, id").
+		Offset((page.Number - 1) * page.Size).Limit(page.Size).Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list local memories failed: %w", err)
+	}
+	memResp := make([]*MemItem, 0, len(rows))
+	for _, row := range rows {
+		memResp = append(memResp, row.item())
+	}
+	return memResp, nil
+}
+
+func (l *LocalVectorMemoryBackend) DeleteMemory(ctx context.Context, id string) error {
+	if err := l.db.WithContext(ctx).Delete(&localMemory{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("delete local memory failed: %w", err)
+	}
+	return nil
+}
+
+func (l *LocalVectorMemoryBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	if err := l.db.WithContext(ctx).Delete(&localMemory{}, "user_id = ?", userId).Error; err != nil {
+		return fmt.Errorf("delete local user memories failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database file.
+func (l *LocalVectorMemoryBackend) Close() error {
+	db, err := l.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (row *localMemory) item() *MemItem {
+	item := &MemItem{
+		ID:        row.ID,
+		Content:   row.Content,
+		Timestamp: row.CreatedAt,
+		Metadata:  map[string]any{"app_name": row.AppName},
+	}
+	if row.Category != "" {
+		item.Metadata["category"] = row.Category
+	}
+	return item
+}
+
+func encodeVector(vector []float32) []byte {
+	b := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+	}
+	return b
+}
+
+func decodeVector(b []byte) []float32 {
+	vector := make([]float32, len(b)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/model"
+)
+
+func newLocalBackend(t *testing.T, config *LocalVectorMemoryConfig) *LocalVectorMemoryBackend {
+	t.Helper()
+	config.Path = filepath.Join(t.TempDir(), "memory.db")
+	if config.Embedder == nil {
+		config.Embedder = model.NewHashEmbedder(256)
+	}
+	backend, err := NewLocalVectorMemoryBackend(config)
+	require.NoError(t, err)
+	local := backend.(*LocalVectorMemoryBackend)
+	t.Cleanup(func() { _ = local.Close() })
+	return local
+}
+
+func TestLocalVectorMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	backend := newLocalBackend(t, &LocalVectorMemoryConfig{RecencyWeight: -1})
+
+	require.NoError(t, backend.SaveMemory(ctx, "app", "alice", []string{
+		`{"parts":[{"text":"I drink green tea every morning"}],"role":"user"}`,
+		`{"category":"fact","content":"The user owns a cat named Miso"}`,
+		"plain text about the weather",
+	}))
+	require.NoError(t, backend.SaveMemory(ctx, "app", "bob", []string{"bob also drinks green tea"}))
+
+	results, err := backend.SearchMemory(ctx, "alice", "which tea do I drink", 2, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0].Content, "green tea")
+	assert.NotEmpty(t, results[0].ID)
+	assert.Greater(t, results[0].Metadata["score"], results[1].Metadata["score"])
+
+	listed, err := backend.ListMemories(ctx, "alice", Page{Number: 1, Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+	listed, err = backend.ListMemories(ctx, "alice", Page{Number: 2, Size: 2})
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	require.NoError(t, backend.DeleteMemory(ctx, results[0].ID))
+	results, err = backend.SearchMemory(ctx, "alice", "green tea", 5, SearchFilter{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	for _, r := range results {
+		assert.NotContains(t, r.Content, "green tea")
+	}
+
+	require.NoError(t, backend.DeleteUserMemories(ctx, "alice"))
+	results, err = backend.SearchMemory(ctx, "alice", "green tea", 5, SearchFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	results, err = backend.SearchMemory(ctx, "bob", "green tea", 5, SearchFilter{})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestLocalVectorMemoryBackendRecency(t *testing.T) {
+	ctx := context.Background()
+	backend := newLocalBackend(t, &LocalVectorMemoryConfig{RecencyWeight: 0.5, RecencyHalfLife: 24 * time.Hour})
+
+	now := time.Now()
+	backend.now = func() time.Time { return now.Add(-30 * 24 * time.Hour) }
+	require.NoError(t, backend.SaveMemory(ctx, "app", "alice", []string{"I live in Paris"}))
+	backend.now = func() time.Time { return now }
+	require.NoError(t, backend.SaveMemory(ctx, "app", "alice", []string{"I moved to Berlin, the city I live in now"}))
+
+	results, err := backend.SearchMemory(ctx, "alice", "I live in Paris", 2, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "I moved to Berlin, the city I live in now", results[0].Content)
+}
+
+func TestLocalVectorMemoryBackendPersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.db")
+	backend, err := NewLocalVectorMemoryBackend(&LocalVectorMemoryConfig{Path: path, Embedder: model.NewHashEmbedder(64)})
+	require.NoError(t, err)
+	require.NoError(t, backend.SaveMemory(ctx, "app", "alice", []string{"I drink green tea"}))
+	require.NoError(t, backend.(*LocalVectorMemoryBackend).Close())
+
+	reopened, err := NewLocalVectorMemoryBackend(&LocalVectorMemoryConfig{Path: path, Embedder: model.NewHashEmbedder(64)})
+	require.NoError(t, err)
+	defer func() { _ = reopened.(*LocalVectorMemoryBackend).Close() }()
+	results, err := reopened.SearchMemory(ctx, "alice", "tea", 1, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "I drink green tea", results[0].Content)
+}
+
+func TestLocalVectorMemoryBackendFilter(t *testing.T) {
+	ctx := context.Background()
+	backend := newLocalBackend(t, &LocalVectorMemoryConfig{})
+
+	now := time.Now()
+	backend.now = func() time.Time { return now.Add(-48 * time.Hour) }
+	require.NoError(t, backend.SaveMemory(ctx, "travel", "alice", []string{"I flew to Tokyo"}))
+	backend.now = func() time.Time { return now }
+	require.NoError(t, backend.SaveMemory(ctx, "travel", "alice", []string{`{"category":"preference","content":"The user prefers window seats"}`}))
+	require.NoError(t, backend.SaveMemory(ctx, "cooking", "alice", []string{"I cook ramen"}))
+
+	contents := func(filter SearchFilter) []string {
+		results, err := backend.SearchMemory(ctx, "alice", "travel", 10, filter)
+		require.NoError(t, err)
+		var got []string
+		for _, r := range results {
+			got = append(got, memoryText(r.Content))
+		}
+		return got
+	}
+	assert.ElementsMatch(t, []string{"I flew to Tokyo", "The user prefers window seats"}, contents(SearchFilter{AppName: "travel"}))
+	assert.ElementsMatch(t, []string{"The user prefers window seats", "I cook ramen"}, contents(SearchFilter{Since: now.Add(-time.Hour)}))
+	assert.ElementsMatch(t, []string{"I flew to Tokyo"}, contents(SearchFilter{AppName: "travel", Until: now.Add(-time.Hour)}))
+	assert.ElementsMatch(t, []string{"The user prefers window seats"}, contents(SearchFilter{Categories: []MemoryCategory{CategoryPreference}}))
+	assert.Len(t, contents(SearchFilter{}), 3)
+
+	// memories saved without an app match every app
+	require.NoError(t, backend.SaveMemory(ctx, "", "alice", []string{"I live in Hangzhou"}))
+	assert.Contains(t, contents(SearchFilter{AppName: "cooking"}), "I live in Hangzhou")
+}
@@ -17,33 +17,137 @@ package long_term_memory_backends
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/volcengine/veadk-go/log"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
 	"google.golang.org/genai"
 )
 
+const DefaultPageSize = 20
+
 type MemItem struct {
-	Content   string
-	Timestamp time.Time
+	// ID is the stable identifier of the memory in its backend, used by MemoryManager.DeleteMemory.
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	// Metadata["app_name"] is the app that saved the memory, "" when saved without an app. It is unset when the
+	// backend cannot tell.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Text returns the text of the memory, decoding the raw events and extracted memories saved by AddSession.
+func (m *MemItem) Text() string {
+	return memoryText(m.Content)
+}
+
+// Category returns the category of an extracted memory, or "" for a raw event.
+func (m *MemItem) Category() MemoryCategory {
+	if c, ok := m.Metadata["category"].(string); ok && c != "" {
+		return MemoryCategory(c)
+	}
+	return extractedCategory(m.Content)
+}
+
+// Page selects a page of memories. Number starts at 1.
+type Page struct {
+	Number int
+	Size   int
+}
+
+func (p Page) normalize() Page {
+	if p.Number <= 0 {
+		p.Number = 1
+	}
+	if p.Size <= 0 {
+		p.Size = DefaultPageSize
+	}
+	return p
+}
+
+// MemoryManager lists and deletes the memories of users, to show them what the agent remembers and to comply
+// with deletion requests.
+type MemoryManager interface {
+	ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error)
+	DeleteMemory(ctx context.Context, id string) error
+	DeleteUserMemories(ctx context.Context, userId string) error
+}
+
+// SearchFilter narrows a memory search. Its zero value matches every memory of the user.
+type SearchFilter struct {
+	// AppName restricts the search to the memories saved by one app. Memories saved without an app, like
+	// those saved before memories were scoped to apps, match every app.
+	AppName string
+	// Since and Until bound the time the memories were saved.
+	Since time.Time
+	Until time.Time
+	// Categories restricts the search to extracted memories of these categories.
+	Categories []MemoryCategory
+}
+
+// Match reports whether a memory found by a backend satisfies the time range and categories of the filter.
+// Backends use it for the conditions they cannot push down.
+func (f SearchFilter) Match(item *MemItem) bool {
+	if !f.Since.IsZero() && item.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && item.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Categories) == 0 {
+		return true
+	}
+	category := item.Category()
+	for _, c := range f.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchApp reports whether a memory found by a backend was saved by the app of the filter, or without an app.
+// Backends use it when they cannot push the app down.
+func (f SearchFilter) MatchApp(item *MemItem) bool {
+	app, _ := item.Metadata["app_name"].(string)
+	return f.AppName == "" || app == "" || app == f.AppName
 }
 
 type LongTermMemoryBackend interface {
-	SaveMemory(ctx context.Context, userId string, eventList []string) error
-	SearchMemory(ctx context.Context, userId, query string, topK int) ([]*MemItem, error)
+	// SaveMemory saves memories of a user, tagged with the app they were learned in.
+	SaveMemory(ctx context.Context, appName, userId string, eventList []string) error
+	// SearchMemory returns the topK memories of a user matching query and filter. The results are not filtered
+	// again, so backends check with MatchApp and Match what they cannot push down.
+	SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error)
+	MemoryManager
 }
 
-func LongTermMemoryFactory(backend LongTermMemoryBackend, tokK int) memory.Service {
-	return &basicLongTermMemory{
-		backend: backend,
-		topK:    tokK,
+// FilteredSearcher is implemented by the memory services of LongTermMemoryFactory, to search with more
+// conditions than memory.SearchRequest carries.
+type FilteredSearcher interface {
+	SearchWithFilter(ctx context.Context, req *memory.SearchRequest, filter SearchFilter) (*memory.SearchResponse, error)
+}
+
+func LongTermMemoryFactory(backend LongTermMemoryBackend, tokK int, opts ...Option) memory.Service {
+	b := &basicLongTermMemory{
+		backend:     backend,
+		topK:        tokK,
+		storageMode: StorageRaw,
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
+	return b
 }
 
 type basicLongTermMemory struct {
-	backend LongTermMemoryBackend
-	topK    int
+	backend     LongTermMemoryBackend
+	topK        int
+	storageMode StorageMode
+	extractor   Extractor
+	sharedApps  bool
 }
 
 func (*basicLongTermMemory) filterAndConvertEvents(s session.Session) []string {
@@ -61,12 +165,45 @@ func (*basicLongTermMemory) filterAndConvertEvents(s session.Session) []string {
 
 func (b *basicLongTermMemory) AddSession(ctx context.Context, s session.Session) error {
 	userId := s.UserID()
-	events := b.filterAndConvertEvents(s)
-	return b.backend.SaveMemory(ctx, userId, events)
+	if b.storageMode == StorageRaw || b.storageMode == "" {
+		return b.backend.SaveMemory(ctx, s.AppName(), userId, b.filterAndConvertEvents(s))
+	}
+	if b.extractor == nil {
+		return ErrNoExtractor
+	}
+
+	var events []string
+	if b.storageMode == StorageBoth {
+		events = b.filterAndConvertEvents(s)
+	}
+	memories, err := b.extractor.Extract(ctx, s)
+	if err != nil {
+		if b.storageMode != StorageBoth {
+			return err
+		}
+		log.Warn("memory extraction failed, saving raw events only", "UserID", userId, "error", err)
+	}
+	for _, m := range memories {
+		bytes, _ := json.Marshal(m)
+		events = append(events, string(bytes))
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return b.backend.SaveMemory(ctx, s.AppName(), userId, events)
 }
 
+// Search only finds the memories of req.AppName, unless WithSharedAcrossApps is set.
 func (b *basicLongTermMemory) Search(ctx context.Context, req *memory.SearchRequest) (*memory.SearchResponse, error) {
-	result, err := b.backend.SearchMemory(ctx, req.UserID, req.Query, b.topK)
+	return b.SearchWithFilter(ctx, req, SearchFilter{})
+}
+
+// SearchWithFilter searches with filter, whose AppName defaults to req.AppName unless WithSharedAcrossApps is set.
+func (b *basicLongTermMemory) SearchWithFilter(ctx context.Context, req *memory.SearchRequest, filter SearchFilter) (*memory.SearchResponse, error) {
+	if filter.AppName == "" && !b.sharedApps {
+		filter.AppName = req.AppName
+	}
+	result, err := b.backend.SearchMemory(ctx, req.UserID, req.Query, b.topK, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +211,20 @@ func (b *basicLongTermMemory) Search(ctx context.Context, req *memory.SearchRequ
 		Memories: make([]memory.Entry, 0),
 	}
 	for _, item := range result {
-		if len(item.Content) > 0 && item.Content[0] == '{' {
+		if extracted, ok := parseExtractedMemory(item.Content); ok {
+			memResp.Memories = append(memResp.Memories, memory.Entry{
+				Content: &genai.Content{
+					Parts: []*genai.Part{
+						{
+							Text: fmt.Sprintf("[%s] %s", extracted.Category, extracted.Content),
+						},
+					},
+					Role: "user",
+				},
+				Author:    "user",
+				Timestamp: item.Timestamp,
+			})
+		} else if len(item.Content) > 0 && item.Content[0] == '{' {
 			var content genai.Content
 			_ = json.Unmarshal([]byte(item.Content), &content)
 			memResp.Memories = append(memResp.Memories, memory.Entry{
@@ -106,3 +256,15 @@ func (b *basicLongTermMemory) Search(ctx context.Context, req *memory.SearchRequ
 	}
 	return memResp, nil
 }
+
+func (b *basicLongTermMemory) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	return b.backend.ListMemories(ctx, userId, page.normalize())
+}
+
+func (b *basicLongTermMemory) DeleteMemory(ctx context.Context, id string) error {
+	return b.backend.DeleteMemory(ctx, id)
+}
+
+func (b *basicLongTermMemory) DeleteUserMemories(ctx context.Context, userId string) error {
+	return b.backend.DeleteUserMemories(ctx, userId)
+}
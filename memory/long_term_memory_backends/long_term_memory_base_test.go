@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/memory"
+)
+
+func TestSearchFilterMatch(t *testing.T) {
+	now := time.Now()
+	raw := &MemItem{Content: "I like tea", Timestamp: now}
+	fact := &MemItem{Content: `{"category":"fact","content":"The user lives in Hangzhou."}`, Timestamp: now}
+	tagged := &MemItem{Content: "The user likes tea.", Timestamp: now, Metadata: map[string]any{"category": "preference"}}
+
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   []*MemItem
+	}{
+		{name: "zero value", filter: SearchFilter{}, want: []*MemItem{raw, fact, tagged}},
+		{name: "since", filter: SearchFilter{Since: now.Add(time.Second)}, want: nil},
+		{name: "until", filter: SearchFilter{Until: now.Add(time.Second)}, want: []*MemItem{raw, fact, tagged}},
+		{name: "categories", filter: SearchFilter{Categories: []MemoryCategory{CategoryFact, CategoryPreference}}, want: []*MemItem{fact, tagged}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*MemItem
+			for _, item := range []*MemItem{raw, fact, tagged} {
+				if tt.filter.Match(item) {
+					got = append(got, item)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSearchFilterMatchApp(t *testing.T) {
+	app := &MemItem{Metadata: map[string]any{"app_name": "app"}}
+	other := &MemItem{Metadata: map[string]any{"app_name": "other"}}
+	legacy := &MemItem{Metadata: map[string]any{}}
+
+	assert.True(t, SearchFilter{}.MatchApp(other))
+	assert.True(t, SearchFilter{AppName: "app"}.MatchApp(app))
+	assert.False(t, SearchFilter{AppName: "app"}.MatchApp(other))
+	assert.True(t, SearchFilter{AppName: "app"}.MatchApp(legacy), "memories saved without an app match every app")
+}
+
+func TestSearchAppIsolation(t *testing.T) {
+	backend := &recordingBackend{}
+	service := LongTermMemoryFactory(backend, 5)
+	require.NoError(t, service.AddSession(t.Context(), conversation(t)))
+	assert.Equal(t, []string{"app"}, backend.apps)
+
+	search(t, service)
+	assert.Equal(t, SearchFilter{AppName: "app"}, backend.filters[0])
+
+	searcher := service.(FilteredSearcher)
+	since := time.Now().Add(-time.Hour)
+	_, err := searcher.SearchWithFilter(t.Context(), &memory.SearchRequest{AppName: "app", UserID: "user", Query: "tea"},
+		SearchFilter{AppName: "other", Since: since})
+	require.NoError(t, err)
+	assert.Equal(t, SearchFilter{AppName: "other", Since: since}, backend.filters[1])
+
+	shared := &recordingBackend{}
+	search(t, LongTermMemoryFactory(shared, 5, WithSharedAcrossApps()))
+	assert.Equal(t, SearchFilter{}, shared.filters[0])
+}
@@ -27,6 +27,9 @@ import (
 	"github.com/volcengine/veadk-go/utils"
 )
 
+// mem0OverFetch multiplies topK when results are filtered after the search, so that enough of them are left.
+const mem0OverFetch = 4
+
 var (
 	ErrApiKeyNotSet  = errors.New("API Key not set, auto fetching api key needs `ProjectId`")
 	ErrBaseUrlNotSet = errors.New("BaseUrl not set")
@@ -75,9 +78,13 @@ func NewMem0MemoryBackend(config *Mem0MemoryConfig) (LongTermMemoryBackend, erro
 	return backend, nil
 }
 
-func (mem *Mem0MemoryBackend) SaveMemory(ctx context.Context, userId string, eventList []string) error {
+func (mem *Mem0MemoryBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
 	asyncMode := true
 	for _, event := range eventList {
+		metadata := map[string]interface{}{"app_name": appName}
+		if category := extractedCategory(event); category != "" {
+			metadata["category"] = string(category)
+		}
 		_, err := mem.client.Add(ctx, mem0.AddMemoriesRequest{
 			Messages: []mem0.Message{
 				{
@@ -87,6 +94,7 @@ func (mem *Mem0MemoryBackend) SaveMemory(ctx context.Context, userId string, eve
 			},
 			UserId:    &userId,
 			AsyncMode: &asyncMode,
+			Metadata:  metadata,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to save memory to Mem0: %w", err)
@@ -96,26 +104,97 @@ func (mem *Mem0MemoryBackend) SaveMemory(ctx context.Context, userId string, eve
 	return nil
 }
 
-func (mem *Mem0MemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int) ([]*MemItem, error) {
+func (mem *Mem0MemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error) {
 	log.Infof("Searching Mem0 for query: %s, user: %s, top_k: %d", query, userId, topK)
 
 	var memResp []*MemItem
 
+	limit := topK
+	if filter.AppName != "" || len(filter.Categories) > 1 || !filter.Since.IsZero() || !filter.Until.IsZero() {
+		limit = topK * mem0OverFetch
+	}
 	result, err := mem.client.Search(ctx, mem0.SearchMemoriesRequest{
-		Query:  query,
-		UserId: &userId,
-		TopK:   &topK,
+		Query:   query,
+		UserId:  &userId,
+		TopK:    &limit,
+		Filters: mem0Filters(filter),
 	})
 	if err != nil {
 		return memResp, fmt.Errorf("failed to search memory from Mem0: %w", err)
 	}
 
 	for _, v := range result.Results {
-		memResp = append(memResp, &MemItem{
-			Content:   v.Memory,
-			Timestamp: v.CreatedAt,
-		})
+		item := mem0Item(v)
+		if filter.MatchApp(item) && filter.Match(item) {
+			memResp = append(memResp, item)
+		}
+		if len(memResp) == topK {
+			break
+		}
+	}
+
+	return memResp, nil
+}
+
+func (mem *Mem0MemoryBackend) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	page = page.normalize()
+	result, err := mem.client.List(ctx, mem0.ListMemoriesRequest{
+		UserId:   userId,
+		Page:     page.Number,
+		PageSize: page.Size,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories from Mem0: %w", err)
 	}
 
+	memResp := make([]*MemItem, 0, len(result.Results))
+	for _, v := range result.Results {
+		memResp = append(memResp, mem0Item(v))
+	}
 	return memResp, nil
 }
+
+func (mem *Mem0MemoryBackend) DeleteMemory(ctx context.Context, id string) error {
+	if err := mem.client.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete memory from Mem0: %w", err)
+	}
+	log.Infof("Successfully deleted memory %s from Mem0", id)
+	return nil
+}
+
+func (mem *Mem0MemoryBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	if err := mem.client.DeleteAll(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete user memories from Mem0: %w", err)
+	}
+	log.Infof("Successfully deleted all memories of user %s from Mem0", userId)
+	return nil
+}
+
+// mem0Filters matches the metadata saved by SaveMemory. Apps, time ranges and several categories are filtered
+// after the search, as the memories saved without an app must match every app.
+func mem0Filters(filter SearchFilter) map[string]interface{} {
+	filters := make(map[string]interface{})
+	if len(filter.Categories) == 1 {
+		filters["category"] = string(filter.Categories[0])
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+func mem0Item(v mem0.MemoryItem) *MemItem {
+	metadata := make(map[string]any, len(v.Metadata)+1)
+	for key, value := range v.Metadata {
+		metadata[key] = value
+	}
+	if v.Score != 0 {
+		metadata["score"] = v.Score
+	}
+	return &MemItem{
+		ID:        v.Id,
+		Content:   v.Memory,
+		Timestamp: v.CreatedAt,
+		Metadata:  metadata,
+	}
+}
@@ -108,7 +108,7 @@ func TestMem0MemoryBackend_SaveMemory(t *testing.T) {
 				return mem0.AddMemoriesResponse{}, nil
 			}).Build()
 
-			err := backend.SaveMemory(ctx, "test_user", eventList)
+			err := backend.SaveMemory(ctx, "test_app", "test_user", eventList)
 			assert.Nil(t, err)
 			assert.Equal(t, 2, callCount)
 		})
@@ -117,7 +117,7 @@ func TestMem0MemoryBackend_SaveMemory(t *testing.T) {
 			eventList := []string{"event1"}
 			mockey.Mock((*mem0.Mem0Client).Add).Return(mem0.AddMemoriesResponse{}, errors.New("add error")).Build()
 
-			err := backend.SaveMemory(ctx, "test_user", eventList)
+			err := backend.SaveMemory(ctx, "test_app", "test_user", eventList)
 			assert.NotNil(t, err)
 			assert.Contains(t, err.Error(), "failed to save memory to Mem0")
 		})
@@ -147,7 +147,7 @@ func TestMem0MemoryBackend_SearchMemory(t *testing.T) {
 				},
 			}, nil).Build()
 
-			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10)
+			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10, SearchFilter{})
 
 			assert.Nil(t, err)
 			assert.NotNil(t, results)
@@ -161,7 +161,7 @@ func TestMem0MemoryBackend_SearchMemory(t *testing.T) {
 				Results: []mem0.MemoryItem{},
 			}, nil).Build()
 
-			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10)
+			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10, SearchFilter{})
 
 			assert.Nil(t, err)
 			assert.Equal(t, 0, len(results))
@@ -170,7 +170,7 @@ func TestMem0MemoryBackend_SearchMemory(t *testing.T) {
 		mockey.PatchConvey("Failure", func() {
 			mockey.Mock((*mem0.Mem0Client).Search).Return(mem0.SearchMemoriesResponse{}, errors.New("search error")).Build()
 
-			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10)
+			results, err := backend.SearchMemory(ctx, "test_user", "test query", 10, SearchFilter{})
 
 			assert.Nil(t, results)
 			assert.NotNil(t, err)
@@ -178,3 +178,42 @@ func TestMem0MemoryBackend_SearchMemory(t *testing.T) {
 		})
 	})
 }
+
+func TestMem0MemoryBackend_ManageMemories(t *testing.T) {
+	backend := &Mem0MemoryBackend{
+		client: &mem0.Mem0Client{},
+		config: &Mem0MemoryConfig{},
+	}
+	ctx := context.Background()
+
+	mockey.PatchConvey("TestMem0MemoryBackend_ManageMemories", t, func() {
+		mockey.PatchConvey("List", func() {
+			var gotReq mem0.ListMemoriesRequest
+			mockey.Mock((*mem0.Mem0Client).List).To(func(ctx context.Context, req mem0.ListMemoriesRequest) (mem0.ListMemoriesResponse, error) {
+				gotReq = req
+				return mem0.ListMemoriesResponse{
+					Results: []mem0.MemoryItem{
+						{Id: "mem1", Memory: "memory 1", Metadata: map[string]interface{}{"category": "preference"}},
+					},
+				}, nil
+			}).Build()
+
+			results, err := backend.ListMemories(ctx, "test_user", Page{})
+			assert.Nil(t, err)
+			assert.Equal(t, mem0.ListMemoriesRequest{UserId: "test_user", Page: 1, PageSize: DefaultPageSize}, gotReq)
+			assert.Equal(t, 1, len(results))
+			assert.Equal(t, "mem1", results[0].ID)
+			assert.Equal(t, "preference", results[0].Metadata["category"])
+		})
+
+		mockey.PatchConvey("Delete", func() {
+			mockey.Mock((*mem0.Mem0Client).Delete).Return(nil).Build()
+			mockey.Mock((*mem0.Mem0Client).DeleteAll).Return(errors.New("delete error")).Build()
+
+			assert.Nil(t, backend.DeleteMemory(ctx, "mem1"))
+			err := backend.DeleteUserMemories(ctx, "test_user")
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "failed to delete user memories from Mem0")
+		})
+	})
+}
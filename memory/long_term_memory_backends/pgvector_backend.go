@@ -0,0 +1,327 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPgVectorTable = "veadk_memories"
+
+	// maxVectorDimensions is the largest vector pgvector can index with HNSW; larger embeddings are stored as
+	// half-precision vectors, which can be indexed up to 4000 dimensions.
+	maxVectorDimensions = 2000
+)
+
+var (
+	ErrNoDimensions     = errors.New("pgvector backend requires the embedding dimensions, set Dimensions or MODEL_EMBEDDING_DIM")
+	ErrInvalidTableName = errors.New("invalid pgvector table name")
+
+	tableNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,62}$`)
+)
+
+type PgVectorMemoryConfig struct {
+	// CommonDatabaseConfig defaults to configs.Database.Postgresql, the database of the short-term memory.
+	*configs.CommonDatabaseConfig
+	// DB reuses an open connection pool instead of opening one from CommonDatabaseConfig.
+	DB *gorm.DB
+	// AppName restricts ListMemories, DeleteMemory and DeleteUserMemories to the memories of one app. Empty means
+	// every app sharing the table.
+	AppName string
+	// Embedder defaults to model.NewOpenAIEmbedder configured from the MODEL_EMBEDDING_* environment variables.
+	Embedder model.Embedder
+	// Dimensions is the size of the embeddings. Defaults to MODEL_EMBEDDING_DIM.
+	Dimensions int
+	// Table defaults to DefaultPgVectorTable.
+	Table string
+}
+
+// PgVectorMemoryBackend keeps memories in PostgreSQL with the pgvector extension and searches them with an HNSW
+// cosine index. It requires pgvector 0.8.0 or later: searches use its iterative index scans, without which the
+// user filter applied after the scan leaves users with few memories among many with fewer than topK results.
+type PgVectorMemoryBackend struct {
+	config     *PgVectorMemoryConfig
+	db         *gorm.DB
+	vectorType string
+}
+
+func NewPgVectorMemoryBackend(config *PgVectorMemoryConfig) (LongTermMemoryBackend, error) {
+	if config == nil {
+		config = &PgVectorMemoryConfig{}
+	}
+	if config.Table == "" {
+		config.Table = DefaultPgVectorTable
+	}
+	if !tableNameRe.MatchString(config.Table) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTableName, config.Table)
+	}
+	if config.Dimensions <= 0 {
+		config.Dimensions, _ = strconv.Atoi(os.Getenv(common.MODEL_EMBEDDING_DIM))
+		if config.Dimensions <= 0 {
+			return nil, ErrNoDimensions
+		}
+	}
+	if config.Embedder == nil {
+		embedder, err := model.NewOpenAIEmbedder("", nil)
+		if err != nil {
+			return nil, err
+		}
+		config.Embedder = embedder
+	}
+
+	db := config.DB
+	if db == nil {
+		if config.CommonDatabaseConfig == nil {
+			config.CommonDatabaseConfig = configs.GetGlobalConfig().Database.Postgresql
+		}
+		var err error
+		db, err = gorm.Open(
+			postgres.Open(config.PostgresqlURL()),
+			&gorm.Config{Logger: log.NewGormLogger(slog.LevelError)},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("open pgvector memory store failed: %w", err)
+		}
+	}
+
+	backend := &PgVectorMemoryBackend{config: config, db: db, vectorType: vectorType(config.Dimensions)}
+	for _, statement := range backend.schema() {
+		if err := db.Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("migrate pgvector memory store failed: %w", err)
+		}
+	}
+	return backend, nil
+}
+
+func vectorType(dimensions int) string {
+	if dimensions > maxVectorDimensions {
+		return "halfvec"
+	}
+	return "vector"
+}
+
+// schema returns the idempotent statements creating the memories table and its indexes.
+func (p *PgVectorMemoryBackend) schema() []string {
+	table := p.config.Table
+	return []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id VARCHAR(36) PRIMARY KEY,
+	app_name VARCHAR(256) NOT NULL DEFAULT '',
+	user_id VARCHAR(256) NOT NULL,
+	category VARCHAR(32) NOT NULL DEFAULT '',
+	content TEXT NOT NULL,
+	embedding %s(%d) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`, table, p.vectorType, p.config.Dimensions),
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT ''", table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_app_user_idx ON %s (app_name, user_id, created_at DESC)", table, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_embedding_idx ON %s USING hnsw (embedding %s_cosine_ops)", table, table, p.vectorType),
+	}
+}
+
+// vectorLiteral formats a vector as a pgvector text literal.
+func vectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func (p *PgVectorMemoryBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
+	if len(eventList) == 0 {
+		return nil
+	}
+	texts := make([]string, len(eventList))
+	for i, event := range eventList {
+		texts[i] = memoryText(event)
+	}
+	vectors, err := p.config.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed memories failed: %w", err)
+	}
+	if len(vectors) != len(eventList) {
+		return ErrEmbeddingSize
+	}
+
+	now := time.Now()
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		statement := fmt.Sprintf("INSERT INTO %s (id, app_name, user_id, category, content, embedding, created_at) VALUES (?, ?, ?, ?, ?, ?::%s, ?)",
+			p.config.Table, p.vectorType)
+		for i, event := range eventList {
+			err := tx.Exec(statement, uuid.NewString(), appName, userId, string(extractedCategory(event)), event, vectorLiteral(vectors[i]), now).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("save pgvector memories failed: %w", err)
+	}
+	log.Infof("Successfully saved user %s %d events to pgvector", userId, len(eventList))
+	return nil
+}
+
+type pgMemoryRow struct {
+	ID        string
+	AppName   string
+	Category  string
+	Content   string
+	CreatedAt time.Time
+	Score     *float64
+}
+
+func (row *pgMemoryRow) item() *MemItem {
+	item := &MemItem{
+		ID:        row.ID,
+		Content:   row.Content,
+		Timestamp: row.CreatedAt,
+		Metadata:  map[string]any{"app_name": row.AppName},
+	}
+	if row.Category != "" {
+		item.Metadata["category"] = row.Category
+	}
+	if row.Score != nil {
+		item.Metadata["score"] = *row.Score
+	}
+	return item
+}
+
+func (p *PgVectorMemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error) {
+	vectors, err := p.config.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query failed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, ErrEmbeddingSize
+	}
+
+	where, args := pgFilter(userId, filter)
+	args["query"] = vectorLiteral(vectors[0])
+	args["limit"] = topK
+	var rows []*pgMemoryRow
+	// relaxed order scans the index until enough rows pass the filter, but may return them slightly out of
+	// order: they are sorted again, "+ 0" keeps the planner from assuming they already are
+	statement := fmt.Sprintf(`WITH candidates AS MATERIALIZED (
+	SELECT id, app_name, category, content, created_at, embedding <=> @query::%[2]s AS distance
+	FROM %[1]s WHERE %[3]s ORDER BY distance LIMIT @limit
+)
+SELECT id, app_name, category, content, created_at, 1 - distance AS score FROM candidates ORDER BY distance + 0`,
+		p.config.Table, p.vectorType, where)
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL hnsw.iterative_scan = relaxed_order").Error; err != nil {
+			return err
+		}
+		return tx.Raw(statement, args).Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search pgvector memories failed: %w", err)
+	}
+
+	memResp := make([]*MemItem, 0, len(rows))
+	for _, row := range rows {
+		memResp = append(memResp, row.item())
+	}
+	return memResp, nil
+}
+
+// pgFilter returns the WHERE clause of a search, with its named arguments.
+func pgFilter(userId string, filter SearchFilter) (string, map[string]any) {
+	conditions := []string{"user_id = @user"}
+	args := map[string]any{"user": userId}
+	if filter.AppName != "" {
+		conditions = append(conditions, "(app_name = @app OR app_name = '')")
+		args["app"] = filter.AppName
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= @since")
+		args["since"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at <= @until")
+		args["until"] = filter.Until
+	}
+	if len(filter.Categories) > 0 {
+		categories := make([]string, len(filter.Categories))
+		for i, c := range filter.Categories {
+			categories[i] = string(c)
+		}
+		conditions = append(conditions, "category IN @categories")
+		args["categories"] = categories
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// scoped restricts db to the app of the config, if any.
+func (p *PgVectorMemoryBackend) scoped(db *gorm.DB) *gorm.DB {
+	if p.config.AppName == "" {
+		return db
+	}
+	return db.Where("app_name = ?", p.config.AppName)
+}
+
+func (p *PgVectorMemoryBackend) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	page = page.normalize()
+	var rows []*pgMemoryRow
+	err := p.scoped(p.db.WithContext(ctx).Table(p.config.Table)).Select("id, app_name, category, content, created_at").
+		Where("user_id = ?", userId).Order("created_at DESC, id").
+		Offset((page.Number - 1) * page.Size).Limit(page.Size).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list pgvector memories failed: %w", err)
+	}
+	memResp := make([]*MemItem, 0, len(rows))
+	for _, row := range rows {
+		memResp = append(memResp, row.item())
+	}
+	return memResp, nil
+}
+
+func (p *PgVectorMemoryBackend) DeleteMemory(ctx context.Context, id string) error {
+	if err := p.scoped(p.db.WithContext(ctx).Table(p.config.Table)).Where("id = ?", id).Delete(nil).Error; err != nil {
+		return fmt.Errorf("delete pgvector memory failed: %w", err)
+	}
+	return nil
+}
+
+func (p *PgVectorMemoryBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	if err := p.scoped(p.db.WithContext(ctx).Table(p.config.Table)).Where("user_id = ?", userId).Delete(nil).Error; err != nil {
+		return fmt.Errorf("delete pgvector user memories failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package long_term_memory_backends
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNewPgVectorMemoryBackendValidation(t *testing.T) {
+	t.Setenv(common.MODEL_EMBEDDING_DIM, "")
+	embedder := model.NewHashEmbedder(8)
+
+	_, err := NewPgVectorMemoryBackend(&PgVectorMemoryConfig{Embedder: embedder})
+	assert.ErrorIs(t, err, ErrNoDimensions)
+
+	_, err = NewPgVectorMemoryBackend(&PgVectorMemoryConfig{Embedder: embedder, Dimensions: 8, Table: "memories; DROP TABLE users"})
+	assert.ErrorIs(t, err, ErrInvalidTableName)
+}
+
+func TestPgVectorSchema(t *testing.T) {
+	small := &PgVectorMemoryBackend{config: &PgVectorMemoryConfig{Table: "memories", Dimensions: 1024}, vectorType: vectorType(1024)}
+	schema := small.schema()
+	assert.Equal(t, "CREATE EXTENSION IF NOT EXISTS vector", schema[0])
+	assert.Contains(t, schema[1], "embedding vector(1024) NOT NULL")
+	assert.Equal(t, "ALTER TABLE memories ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT ''", schema[2])
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS memories_embedding_idx ON memories USING hnsw (embedding vector_cosine_ops)", schema[4])
+
+	large := &PgVectorMemoryBackend{config: &PgVectorMemoryConfig{Table: "memories", Dimensions: 2560}, vectorType: vectorType(2560)}
+	schema = large.schema()
+	assert.Contains(t, schema[1], "embedding halfvec(2560) NOT NULL")
+	assert.Contains(t, schema[4], "halfvec_cosine_ops")
+}
+
+func TestVectorLiteral(t *testing.T) {
+	assert.Equal(t, "[1,-0.5,0.1]", vectorLiteral([]float32{1, -0.5, 0.1}))
+	assert.Equal(t, "[]", vectorLiteral(nil))
+}
+
+func TestPgFilter(t *testing.T) {
+	where, args := pgFilter("alice", SearchFilter{})
+	assert.Equal(t, "user_id = @user", where)
+	assert.Equal(t, map[string]any{"user": "alice"}, args)
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = pgFilter("alice", SearchFilter{AppName: "app", Since: since, Categories: []MemoryCategory{CategoryFact}})
+	assert.Equal(t, "user_id = @user AND (app_name = @app OR app_name = '') AND created_at >= @since AND category IN @categories", where)
+	assert.Equal(t, map[string]any{"user": "alice", "app": "app", "since": since, "categories": []string{"fact"}}, args)
+}
+
+// TestPgVectorSearchAmongManyUsers needs PostgreSQL with pgvector 0.8.0 or later at DATABASE_POSTGRESQL_DBURL.
+func TestPgVectorSearchAmongManyUsers(t *testing.T) {
+	dbURL := os.Getenv(common.DATABASE_POSTGRESQL_DBURL)
+	if dbURL == "" {
+		t.Skip("missing DATABASE_POSTGRESQL_DBURL")
+	}
+	db, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	// a single connection, so that searches always go through the HNSW index
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.Exec("SET enable_seqscan = off").Error)
+
+	table := "veadk_memories_" + uuid.NewString()[:8]
+	backend, err := NewPgVectorMemoryBackend(&PgVectorMemoryConfig{DB: db, Embedder: model.NewHashEmbedder(64), Dimensions: 64, Table: table})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec("DROP TABLE " + table) })
+
+	ctx := t.Context()
+	// the other users' memories are all closer to the query than alice's
+	for i := range 50 {
+		var memories []string
+		for j := range 20 {
+			memories = append(memories, fmt.Sprintf("my favourite colour is teal, note %d", j))
+		}
+		require.NoError(t, backend.SaveMemory(ctx, "app", fmt.Sprintf("user-%d", i), memories))
+	}
+	require.NoError(t, backend.SaveMemory(ctx, "app", "alice", []string{
+		"my favourite colour is green",
+		"i walk the dog every morning",
+		"weekend plans: hiking",
+	}))
+
+	memories, err := backend.SearchMemory(ctx, "alice", "favourite colour teal", 3, SearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, memories, 3)
+	assert.Equal(t, "my favourite colour is green", memories[0].Content)
+	for i := 1; i < len(memories); i++ {
+		assert.GreaterOrEqual(t, memories[i-1].Metadata["score"], memories[i].Metadata["score"])
+	}
+}
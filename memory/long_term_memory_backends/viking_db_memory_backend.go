@@ -30,6 +30,9 @@ import (
 
 const (
 	DefaultIndex = "veadk"
+
+	// defaultAssistantId is the assistant memories are saved under when the app name is unknown.
+	defaultAssistantId = "assistant"
 )
 
 var ErrCollectionInfo = errors.New("collection info error")
@@ -101,7 +104,8 @@ func NewVikingDbMemoryBackend(config *VikingDbMemoryConfig) (LongTermMemoryBacke
 	return backend, nil
 }
 
-func (v *VikingDBMemoryBackend) SaveMemory(ctx context.Context, userId string, eventList []string) error {
+// SaveMemory saves the events with the app name as assistant, which SearchMemory filters on.
+func (v *VikingDBMemoryBackend) SaveMemory(ctx context.Context, appName, userId string, eventList []string) error {
 	req := &viking_memory.AddSessionRequest{}
 	uuid1, err := uuid.NewUUID()
 	if err != nil {
@@ -118,7 +122,10 @@ func (v *VikingDBMemoryBackend) SaveMemory(ctx context.Context, userId string, e
 	}
 
 	req.Metadata.DefaultUserId = userId
-	req.Metadata.DefaultAssistantId = "assistant"
+	req.Metadata.DefaultAssistantId = appName
+	if appName == "" {
+		req.Metadata.DefaultAssistantId = defaultAssistantId
+	}
 	req.Metadata.Time = time.Now().UnixMilli()
 
 	resp, err := v.client.AddSession(req)
@@ -133,17 +140,29 @@ func (v *VikingDBMemoryBackend) SaveMemory(ctx context.Context, userId string, e
 	return nil
 }
 
-func (v *VikingDBMemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int) ([]*MemItem, error) {
+// SearchMemory also finds the memories saved under defaultAssistantId, which match every app. Viking answers with
+// its own summaries of the saved events, which have no category, so filter.Categories is ignored.
+func (v *VikingDBMemoryBackend) SearchMemory(ctx context.Context, userId, query string, topK int, filter SearchFilter) ([]*MemItem, error) {
 	log.Infof("Searching viking for query: %s, user: %s, top_k: %d", query, userId, topK)
 	var memResp []*MemItem
 
+	vikingFilter := viking_memory.Filter{
+		UserId:     []string{userId},
+		MemoryType: v.config.MemoryTypes,
+	}
+	if filter.AppName != "" {
+		vikingFilter.AssistantId = []string{filter.AppName, defaultAssistantId}
+	}
+	if !filter.Since.IsZero() {
+		vikingFilter.StartTime = filter.Since.UnixMilli()
+	}
+	if !filter.Until.IsZero() {
+		vikingFilter.EndTime = filter.Until.UnixMilli()
+	}
 	vikingReq := &viking_memory.CollectionSearchMemoryRequest{
-		Filter: viking_memory.Filter{
-			UserId:     []string{userId},
-			MemoryType: v.config.MemoryTypes,
-		},
-		Query: query,
-		Limit: topK,
+		Filter: vikingFilter,
+		Query:  query,
+		Limit:  topK,
 	}
 
 	resp, err := v.client.CollectionSearchMemory(vikingReq)
@@ -155,14 +174,95 @@ func (v *VikingDBMemoryBackend) SearchMemory(ctx context.Context, userId, query
 		return nil, fmt.Errorf("search viking failed: %v", resp)
 	}
 
+	timeRange := SearchFilter{Since: filter.Since, Until: filter.Until}
 	if resp.Data != nil {
 		for _, v := range resp.Data.ResultList {
-			memResp = append(memResp, &MemItem{
-				Content:   v.MemoryInfo.Summary,
-				Timestamp: utils.ConvertTimeMillToTime(v.Time),
-			})
+			if item := vikingItem(v); timeRange.Match(item) {
+				memResp = append(memResp, item)
+			}
 		}
 	}
 
 	return memResp, nil
 }
+
+// ListMemories lists the memories of a user. The search API has no offset, so the pages before the requested
+// one are fetched as well.
+func (v *VikingDBMemoryBackend) ListMemories(ctx context.Context, userId string, page Page) ([]*MemItem, error) {
+	page = page.normalize()
+	resp, err := v.client.CollectionSearchMemory(&viking_memory.CollectionSearchMemoryRequest{
+		Filter: viking_memory.Filter{
+			UserId:     []string{userId},
+			MemoryType: v.config.MemoryTypes,
+		},
+		Limit: page.Number * page.Size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != ve_viking.VikingKnowledgeBaseSuccessCode {
+		return nil, fmt.Errorf("list viking memories failed: %v", resp)
+	}
+
+	memResp := make([]*MemItem, 0, page.Size)
+	if resp.Data != nil {
+		start := (page.Number - 1) * page.Size
+		for i := start; i < len(resp.Data.ResultList) && i < start+page.Size; i++ {
+			memResp = append(memResp, vikingItem(resp.Data.ResultList[i]))
+		}
+	}
+	return memResp, nil
+}
+
+func (v *VikingDBMemoryBackend) DeleteMemory(ctx context.Context, id string) error {
+	resp, err := v.client.DeleteEvent(&viking_memory.DeleteEventRequest{EventId: id})
+	if err != nil {
+		return err
+	}
+	if resp.Code != ve_viking.VikingKnowledgeBaseSuccessCode {
+		return fmt.Errorf("viking delete memory failed: %v", resp)
+	}
+	log.Infof("Successfully deleted memory %s from viking", id)
+	return nil
+}
+
+// DeleteUserMemories deletes the memories of every type, not only the configured MemoryTypes, so that
+// nothing is left of the user.
+func (v *VikingDBMemoryBackend) DeleteUserMemories(ctx context.Context, userId string) error {
+	resp, err := v.client.BatchDeleteEvent(&viking_memory.BatchDeleteEventRequest{
+		Filter: viking_memory.Filter{UserId: []string{userId}},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Code != ve_viking.VikingKnowledgeBaseSuccessCode {
+		return fmt.Errorf("viking delete user memories failed: %v", resp)
+	}
+	log.Infof("Successfully deleted all memories of user %s from viking", userId)
+	return nil
+}
+
+func vikingItem(v *viking_memory.CollectionSearchResponseItem) *MemItem {
+	item := &MemItem{
+		ID:        v.Id,
+		Timestamp: utils.ConvertTimeMillToTime(v.Time),
+		Metadata:  map[string]any{},
+	}
+	if v.MemoryInfo != nil {
+		item.Content = v.MemoryInfo.Summary
+	}
+	if v.MemoryType != "" {
+		item.Metadata["memory_type"] = v.MemoryType
+	}
+	if len(v.AssistantId) > 0 {
+		appName := v.AssistantId[0]
+		if appName == defaultAssistantId {
+			appName = ""
+		}
+		item.Metadata["app_name"] = appName
+	}
+	if v.Score != 0 {
+		item.Metadata["score"] = v.Score
+	}
+	return item
+}
@@ -80,7 +80,7 @@ func TestVikingDbMemoryBackend_SaveMemory(t *testing.T) {
 	ctx := context.Background()
 	mockey.PatchConvey("TestVikingDbMemoryBackend_SaveMemory", t, func() {
 		mockey.Mock((*viking_memory.Client).AddSession).Return(&ve_viking.CommonResponse{Code: ve_viking.VikingKnowledgeBaseSuccessCode}, nil).Build()
-		err := v.SaveMemory(ctx, "test", []string{"test1", "test2"})
+		err := v.SaveMemory(ctx, "test_app", "test", []string{"test1", "test2"})
 		assert.Nil(t, err)
 	})
 }
@@ -115,9 +115,14 @@ func TestVikingDbMemoryBackend_SearchMemory(t *testing.T) {
 				},
 			},
 		}, nil).Build()
-		resp, err := v.SearchMemory(ctx, "test", "test", 2)
+		resp, err := v.SearchMemory(ctx, "test", "test", 2, SearchFilter{})
 		assert.Nil(t, err)
 		assert.Equal(t, 2, len(resp))
+
+		// summaries have no category, so categories do not filter them out
+		filtered, err := v.SearchMemory(ctx, "test", "test", 2, SearchFilter{AppName: "app", Categories: []MemoryCategory{CategoryFact}})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(filtered))
 		for i, v := range resp {
 			assert.Equal(t, now, v.Timestamp)
 			if i == 0 {
@@ -128,3 +133,61 @@ func TestVikingDbMemoryBackend_SearchMemory(t *testing.T) {
 		}
 	})
 }
+
+func TestVikingItem(t *testing.T) {
+	item := vikingItem(&viking_memory.CollectionSearchResponseItem{Id: "m1", AssistantId: []string{"travel"}})
+	assert.Equal(t, "travel", item.Metadata["app_name"])
+
+	item = vikingItem(&viking_memory.CollectionSearchResponseItem{Id: "m2", AssistantId: []string{defaultAssistantId}})
+	assert.Equal(t, "", item.Metadata["app_name"])
+
+	item = vikingItem(&viking_memory.CollectionSearchResponseItem{Id: "m3"})
+	assert.NotContains(t, item.Metadata, "app_name")
+}
+
+func TestVikingDbMemoryBackend_ManageMemories(t *testing.T) {
+	v := &VikingDBMemoryBackend{
+		client: &viking_memory.Client{},
+		config: &VikingDbMemoryConfig{
+			Index:       DefaultIndex,
+			MemoryTypes: []string{"sys_event_v1"},
+		},
+	}
+	ctx := context.Background()
+	mockey.PatchConvey("TestVikingDbMemoryBackend_ManageMemories", t, func() {
+		mockey.PatchConvey("list second page", func() {
+			var limit int
+			mockey.Mock((*viking_memory.Client).CollectionSearchMemory).To(func(c *viking_memory.Client, req *viking_memory.CollectionSearchMemoryRequest) (*viking_memory.CollectionSearchMemoryResponse, error) {
+				limit = req.Limit
+				return &viking_memory.CollectionSearchMemoryResponse{
+					Code: ve_viking.VikingKnowledgeBaseSuccessCode,
+					Data: &viking_memory.CollectionSearchMemoryResponseData{
+						ResultList: []*viking_memory.CollectionSearchResponseItem{
+							{Id: "event1", MemoryInfo: &viking_memory.MemoryInfo{Summary: "test1"}},
+							{Id: "event2", MemoryInfo: &viking_memory.MemoryInfo{Summary: "test2"}},
+							{Id: "event3", MemoryType: "sys_event_v1", MemoryInfo: &viking_memory.MemoryInfo{Summary: "test3"}},
+						},
+					},
+				}, nil
+			}).Build()
+			resp, err := v.ListMemories(ctx, "test", Page{Number: 2, Size: 2})
+			assert.Nil(t, err)
+			assert.Equal(t, 4, limit)
+			assert.Equal(t, 1, len(resp))
+			assert.Equal(t, "event3", resp[0].ID)
+			assert.Equal(t, "sys_event_v1", resp[0].Metadata["memory_type"])
+		})
+
+		mockey.PatchConvey("delete", func() {
+			mockey.Mock((*viking_memory.Client).DeleteEvent).Return(&ve_viking.CommonResponse{Code: ve_viking.VikingKnowledgeBaseSuccessCode}, nil).Build()
+			var filter viking_memory.Filter
+			mockey.Mock((*viking_memory.Client).BatchDeleteEvent).To(func(c *viking_memory.Client, req *viking_memory.BatchDeleteEventRequest) (*ve_viking.CommonResponse, error) {
+				filter = req.Filter
+				return &ve_viking.CommonResponse{Code: 1}, nil
+			}).Build()
+			assert.Nil(t, v.DeleteMemory(ctx, "event1"))
+			assert.NotNil(t, v.DeleteUserMemories(ctx, "test"))
+			assert.Equal(t, viking_memory.Filter{UserId: []string{"test"}}, filter, "memories of every type are deleted")
+		})
+	})
+}
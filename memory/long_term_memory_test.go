@@ -49,6 +49,38 @@ func TestNewLongTermMemory(t *testing.T) {
 			config:  nil,
 			wantErr: false,
 		},
+		{
+			name:    "local vector backend",
+			backend: BackendLongTermLocalVector,
+			config:  &long_term_memory_backends.LocalVectorMemoryConfig{},
+			setupMock: func() {
+				mockey.Mock(long_term_memory_backends.NewLocalVectorMemoryBackend).Return(nil, nil).Build()
+				mockey.Mock(long_term_memory_backends.LongTermMemoryFactory).Return(&mockMemoryServiceImpl{}).Build()
+			},
+			wantErr: false,
+		},
+		{
+			name:    "local vector backend invalid config type",
+			backend: BackendLongTermLocalVector,
+			config:  "invalid",
+			wantErr: true,
+		},
+		{
+			name:    "pgvector backend",
+			backend: BackendLongTermPgVector,
+			config:  nil,
+			setupMock: func() {
+				mockey.Mock(long_term_memory_backends.NewPgVectorMemoryBackend).Return(nil, nil).Build()
+				mockey.Mock(long_term_memory_backends.LongTermMemoryFactory).Return(&mockMemoryServiceImpl{}).Build()
+			},
+			wantErr: false,
+		},
+		{
+			name:    "pgvector backend invalid config type",
+			backend: BackendLongTermPgVector,
+			config:  &long_term_memory_backends.LocalVectorMemoryConfig{},
+			wantErr: true,
+		},
 		{
 			name:    "viking backend default config",
 			backend: BackendLongTermViking,
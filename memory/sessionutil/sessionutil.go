@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sessionutil forks, rewinds, exports and imports sessions through any session.Service, for debugging
+// and "edit and retry" interfaces.
+//
+// The state of the sessions it creates is replayed from the state deltas of their events, on top of the state
+// the source session was created with. The services only keep the latest value of a key, so a key set when the
+// session was created and changed by an event later on is left out rather than guessed. Only session state is
+// copied: app: and user: state is shared with the other sessions and left as it is.
+//
+// Copied events keep their IDs and timestamps, except for the last one, which is restamped with the current time
+// so that the new session counts as just updated, and is not taken for an idle one by the session janitor.
+package sessionutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+var ErrEventOutOfRange = errors.New("event number out of range")
+
+type ForkRequest struct {
+	AppName   string
+	UserID    string
+	SessionID string
+	// NumEvents is the number of events copied to the fork, from the first one.
+	NumEvents int
+	// NewSessionID is the ID of the fork, generated by the service when empty.
+	NewSessionID string
+}
+
+// Fork copies the first NumEvents events of a session into a new session of the same user.
+func Fork(ctx context.Context, service session.Service, req *ForkRequest) (session.Session, error) {
+	source, err := service.Get(ctx, &session.GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID})
+	if err != nil {
+		return nil, err
+	}
+	events, err := head(source.Session, req.NumEvents)
+	if err != nil {
+		return nil, err
+	}
+	return create(ctx, service, req.AppName, req.UserID, req.NewSessionID, baseState(source.Session), events)
+}
+
+type RewindRequest struct {
+	AppName   string
+	UserID    string
+	SessionID string
+	// NumEvents is the number of events kept, from the first one.
+	NumEvents int
+}
+
+// Rewind drops the events of a session after the first NumEvents ones, and recomputes its state. The
+// session.Service interface cannot truncate a session, so the session is deleted and created again; if that
+// fails, the original session is restored.
+func Rewind(ctx context.Context, service session.Service, req *RewindRequest) (session.Session, error) {
+	source, err := service.Get(ctx, &session.GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID})
+	if err != nil {
+		return nil, err
+	}
+	events, err := head(source.Session, req.NumEvents)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == source.Session.Events().Len() {
+		return source.Session, nil
+	}
+
+	all, _ := head(source.Session, source.Session.Events().Len())
+	base := baseState(source.Session)
+	deleteRequest := &session.DeleteRequest{AppName: req.AppName, UserID: req.UserID, SessionID: req.SessionID}
+	if err := service.Delete(ctx, deleteRequest); err != nil {
+		return nil, err
+	}
+	rewound, err := create(ctx, service, req.AppName, req.UserID, req.SessionID, base, events)
+	if err == nil {
+		return rewound, nil
+	}
+	// restore the original session, without the context that may have expired
+	restoreCtx := context.WithoutCancel(ctx)
+	restoreErr := service.Delete(restoreCtx, deleteRequest)
+	if restoreErr == nil {
+		_, restoreErr = create(restoreCtx, service, req.AppName, req.UserID, req.SessionID, base, all)
+	}
+	if restoreErr != nil {
+		return nil, errors.Join(fmt.Errorf("rewind session failed: %w", err), fmt.Errorf("restore session failed: %w", restoreErr))
+	}
+	return nil, fmt.Errorf("rewind session failed: %w", err)
+}
+
+// head returns the first n events of a session.
+func head(s session.Session, n int) ([]*session.Event, error) {
+	if n < 0 || n > s.Events().Len() {
+		return nil, fmt.Errorf("%w: %d, the session has %d events", ErrEventOutOfRange, n, s.Events().Len())
+	}
+	events := make([]*session.Event, 0, n)
+	for i := range n {
+		events = append(events, s.Events().At(i))
+	}
+	return events, nil
+}
+
+// baseState returns the session state that no event changed, i.e. the state the session was created with.
+func baseState(s session.Session) map[string]any {
+	return untouched(sessionState(s), s.Events().All())
+}
+
+// untouched returns the entries of state that no event changed.
+func untouched(state map[string]any, events iter.Seq[*session.Event]) map[string]any {
+	base := make(map[string]any, len(state))
+	for key, value := range state {
+		base[key] = value
+	}
+	for event := range events {
+		for key := range event.Actions.StateDelta {
+			delete(base, key)
+		}
+	}
+	return base
+}
+
+// sessionState returns the session scoped state of a session.
+func sessionState(s session.Session) map[string]any {
+	state := map[string]any{}
+	for key, value := range s.State().All() {
+		if isSessionKey(key) {
+			state[key] = value
+		}
+	}
+	return state
+}
+
+func isSessionKey(key string) bool {
+	return !strings.HasPrefix(key, session.KeyPrefixApp) &&
+		!strings.HasPrefix(key, session.KeyPrefixUser) &&
+		!strings.HasPrefix(key, session.KeyPrefixTemp)
+}
+
+// create creates a session with state, and appends copies of events whose state deltas are restricted to the
+// session state. The last copied event is restamped with the current time.
+func create(ctx context.Context, service session.Service, appName, userID, sessionID string, state map[string]any, events []*session.Event) (session.Session, error) {
+	created, err := service.Create(ctx, &session.CreateRequest{AppName: appName, UserID: userID, SessionID: sessionID, State: state})
+	if err != nil {
+		return nil, err
+	}
+	last := -1
+	for i, event := range events {
+		if !event.Partial {
+			last = i
+		}
+	}
+	for i, event := range events {
+		if event.Partial {
+			continue
+		}
+		copied := *event
+		if now := time.Now(); i == last && copied.Timestamp.Before(now) {
+			copied.Timestamp = now
+		}
+		copied.Actions.StateDelta = make(map[string]any, len(event.Actions.StateDelta))
+		for key, value := range event.Actions.StateDelta {
+			if isSessionKey(key) {
+				copied.Actions.StateDelta[key] = value
+			}
+		}
+		if err := service.AppendEvent(ctx, created.Session, &copied); err != nil {
+			return nil, fmt.Errorf("append event %s failed: %w", event.ID, err)
+		}
+	}
+	return created.Session, nil
+}
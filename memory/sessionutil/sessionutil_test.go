@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionutil
+
+import (
+	"encoding/json"
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newServices(t *testing.T) map[string]session.Service {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "sessions.db") + "?_pragma=foreign_keys(1)"
+	db, err := database.NewSessionService(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(db))
+	return map[string]session.Service{"inmemory": session.InMemoryService(), "database": db}
+}
+
+// newConversation creates a session of four events, the third one a partial event the services drop.
+func newConversation(t *testing.T, service session.Service) session.Session {
+	t.Helper()
+	created, err := service.Create(t.Context(), &session.CreateRequest{
+		AppName: "app", UserID: "user", SessionID: "s",
+		State: map[string]any{"lang": "en", "topic": "tea", "user:name": "Ann"},
+	})
+	require.NoError(t, err)
+	for i, e := range []struct {
+		author, text string
+		delta        map[string]any
+	}{
+		{"user", "hi", map[string]any{"step": 1}},
+		{"model", "hello", map[string]any{"step": 2, "user:name": "Bob"}},
+		{"user", "tell me about coffee", map[string]any{"step": 3, "topic": "coffee"}},
+		{"model", "coffee is", map[string]any{"step": 4}},
+	} {
+		event := session.NewEvent("invocation")
+		event.Author = e.author
+		event.Content = genai.NewContentFromText(e.text, genai.RoleUser)
+		maps.Copy(event.Actions.StateDelta, e.delta)
+		require.NoError(t, service.AppendEvent(t.Context(), created.Session, event), i)
+	}
+	return created.Session
+}
+
+func texts(s session.Session) []string {
+	var texts []string
+	for event := range s.Events().All() {
+		texts = append(texts, event.Content.Parts[0].Text)
+	}
+	return texts
+}
+
+func stateOf(s session.Session) map[string]any {
+	state := map[string]any{}
+	maps.Insert(state, s.State().All())
+	return state
+}
+
+// number normalizes the numbers of the database service, which decodes state from JSON.
+func number(v any) any {
+	if n, ok := v.(int); ok {
+		return float64(n)
+	}
+	return v
+}
+
+func TestFork(t *testing.T) {
+	for name, service := range newServices(t) {
+		t.Run(name, func(t *testing.T) {
+			source := newConversation(t, service)
+			fork, err := Fork(t.Context(), service, &ForkRequest{AppName: "app", UserID: "user", SessionID: "s", NumEvents: 2, NewSessionID: "fork"})
+			require.NoError(t, err)
+			assert.Equal(t, "fork", fork.ID())
+			assert.Equal(t, []string{"hi", "hello"}, texts(fork))
+			state := stateOf(fork)
+			assert.Equal(t, "en", state["lang"])
+			assert.Equal(t, float64(2), number(state["step"]))
+			assert.NotContains(t, state, "topic", "changed after the fork point")
+			assert.Equal(t, "Bob", state["user:name"], "user state is not rewound")
+
+			got, err := service.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "fork"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"hi", "hello"}, texts(got.Session))
+			got, err = service.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+			require.NoError(t, err)
+			assert.Equal(t, texts(source), texts(got.Session), "the source is left as it is")
+
+			_, err = Fork(t.Context(), service, &ForkRequest{AppName: "app", UserID: "user", SessionID: "s", NumEvents: 9})
+			assert.ErrorIs(t, err, ErrEventOutOfRange)
+		})
+	}
+}
+
+func TestRewind(t *testing.T) {
+	for name, service := range newServices(t) {
+		t.Run(name, func(t *testing.T) {
+			newConversation(t, service)
+			rewound, err := Rewind(t.Context(), service, &RewindRequest{AppName: "app", UserID: "user", SessionID: "s", NumEvents: 3})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"hi", "hello", "tell me about coffee"}, texts(rewound))
+			assert.Equal(t, "coffee", stateOf(rewound)["topic"])
+			assert.Equal(t, float64(3), number(stateOf(rewound)["step"]))
+
+			got, err := service.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"hi", "hello", "tell me about coffee"}, texts(got.Session))
+
+			// the rewound session can be continued
+			event := session.NewEvent("retry")
+			event.Author = "model"
+			event.Content = genai.NewContentFromText("coffee was", genai.RoleModel)
+			require.NoError(t, service.AppendEvent(t.Context(), got.Session, event))
+
+			_, err = Rewind(t.Context(), service, &RewindRequest{AppName: "app", UserID: "user", SessionID: "s", NumEvents: -1})
+			assert.ErrorIs(t, err, ErrEventOutOfRange)
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	services := newServices(t)
+	source := newConversation(t, services["inmemory"])
+
+	transcript, err := Export(t.Context(), services["inmemory"], &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, TranscriptVersion, transcript.Version)
+	assert.Equal(t, map[string]any{"lang": "en", "topic": "coffee", "step": 4}, transcript.State)
+	require.Len(t, transcript.Events, 4)
+	data, err := json.Marshal(transcript)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"state_delta":{"step":2,"user:name":"Bob"}`)
+
+	var decoded Transcript
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	imported, err := Import(t.Context(), services["database"], &ImportRequest{Transcript: &decoded, UserID: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "s", imported.ID())
+	assert.Equal(t, "other", imported.UserID())
+
+	got, err := services["database"].Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "other", SessionID: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, texts(source), texts(got.Session))
+	assert.Equal(t, map[string]any{"lang": "en", "topic": "coffee", "step": float64(4)}, stateOf(got.Session),
+		"user state is not imported")
+	for i, event := range transcript.Events {
+		assert.Equal(t, event.ID, got.Session.Events().At(i).ID)
+		if i < len(transcript.Events)-1 {
+			assert.WithinDuration(t, event.Timestamp, got.Session.Events().At(i).Timestamp, time.Microsecond)
+		} else {
+			assert.False(t, got.Session.Events().At(i).Timestamp.Before(event.Timestamp.Truncate(time.Microsecond)))
+		}
+	}
+
+	decoded.Version = 2
+	_, err = Import(t.Context(), services["database"], &ImportRequest{Transcript: &decoded, SessionID: "v2"})
+	assert.ErrorIs(t, err, ErrUnsupportedTranscript)
+}
+
+func TestCopiesAreRestamped(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	for name, service := range newServices(t) {
+		t.Run(name, func(t *testing.T) {
+			created, err := service.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "old"})
+			require.NoError(t, err)
+			for i, text := range []string{"hi", "hello"} {
+				event := session.NewEvent("invocation")
+				event.Author = "user"
+				event.Content = genai.NewContentFromText(text, genai.RoleUser)
+				event.Timestamp = old.Add(time.Duration(i) * time.Minute)
+				require.NoError(t, service.AppendEvent(t.Context(), created.Session, event))
+			}
+
+			_, err = Fork(t.Context(), service, &ForkRequest{AppName: "app", UserID: "user", SessionID: "old", NumEvents: 2, NewSessionID: "fork"})
+			require.NoError(t, err)
+			got, err := service.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: "fork"})
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), got.Session.LastUpdateTime(), time.Minute, "the fork is not idle")
+			assert.WithinDuration(t, old, got.Session.Events().At(0).Timestamp, time.Microsecond)
+			assert.WithinDuration(t, time.Now(), got.Session.Events().At(1).Timestamp, time.Minute)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+	"google.golang.org/genai"
+)
+
+// TranscriptVersion is the version of the transcripts written by Export.
+const TranscriptVersion = 1
+
+var ErrUnsupportedTranscript = errors.New("unsupported transcript version")
+
+// Transcript is a portable JSON form of a session, to move it between session services.
+type Transcript struct {
+	Version        int       `json:"version"`
+	AppName        string    `json:"app_name"`
+	UserID         string    `json:"user_id"`
+	SessionID      string    `json:"session_id"`
+	LastUpdateTime time.Time `json:"last_update_time"`
+	// State is the session scoped state; app: and user: state is not exported.
+	State  map[string]any     `json:"state"`
+	Events []*TranscriptEvent `json:"events"`
+}
+
+type TranscriptEvent struct {
+	ID                 string    `json:"id"`
+	InvocationID       string    `json:"invocation_id,omitempty"`
+	Author             string    `json:"author"`
+	Branch             string    `json:"branch,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+	LongRunningToolIDs []string  `json:"long_running_tool_ids,omitempty"`
+
+	Content           *genai.Content                              `json:"content,omitempty"`
+	CitationMetadata  *genai.CitationMetadata                     `json:"citation_metadata,omitempty"`
+	GroundingMetadata *genai.GroundingMetadata                    `json:"grounding_metadata,omitempty"`
+	UsageMetadata     *genai.GenerateContentResponseUsageMetadata `json:"usage_metadata,omitempty"`
+	CustomMetadata    map[string]any                              `json:"custom_metadata,omitempty"`
+	TurnComplete      bool                                        `json:"turn_complete,omitempty"`
+	Interrupted       bool                                        `json:"interrupted,omitempty"`
+	ErrorCode         string                                      `json:"error_code,omitempty"`
+	ErrorMessage      string                                      `json:"error_message,omitempty"`
+	FinishReason      genai.FinishReason                          `json:"finish_reason,omitempty"`
+
+	StateDelta                 map[string]any                               `json:"state_delta,omitempty"`
+	ArtifactDelta              map[string]int64                             `json:"artifact_delta,omitempty"`
+	RequestedToolConfirmations map[string]toolconfirmation.ToolConfirmation `json:"requested_tool_confirmations,omitempty"`
+	SkipSummarization          bool                                         `json:"skip_summarization,omitempty"`
+	TransferToAgent            string                                       `json:"transfer_to_agent,omitempty"`
+	Escalate                   bool                                         `json:"escalate,omitempty"`
+}
+
+// Export returns the transcript of a session. Partial events are left out.
+func Export(ctx context.Context, service session.Service, req *session.GetRequest) (*Transcript, error) {
+	resp, err := service.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return NewTranscript(resp.Session), nil
+}
+
+// NewTranscript returns the transcript of a session. Partial events are left out.
+func NewTranscript(s session.Session) *Transcript {
+	transcript := &Transcript{
+		Version:        TranscriptVersion,
+		AppName:        s.AppName(),
+		UserID:         s.UserID(),
+		SessionID:      s.ID(),
+		LastUpdateTime: s.LastUpdateTime(),
+		State:          sessionState(s),
+		Events:         make([]*TranscriptEvent, 0, s.Events().Len()),
+	}
+	for event := range s.Events().All() {
+		if event.Partial {
+			continue
+		}
+		transcript.Events = append(transcript.Events, &TranscriptEvent{
+			ID:                         event.ID,
+			InvocationID:               event.InvocationID,
+			Author:                     event.Author,
+			Branch:                     event.Branch,
+			Timestamp:                  event.Timestamp,
+			LongRunningToolIDs:         event.LongRunningToolIDs,
+			Content:                    event.Content,
+			CitationMetadata:           event.CitationMetadata,
+			GroundingMetadata:          event.GroundingMetadata,
+			UsageMetadata:              event.UsageMetadata,
+			CustomMetadata:             event.CustomMetadata,
+			TurnComplete:               event.TurnComplete,
+			Interrupted:                event.Interrupted,
+			ErrorCode:                  event.ErrorCode,
+			ErrorMessage:               event.ErrorMessage,
+			FinishReason:               event.FinishReason,
+			StateDelta:                 event.Actions.StateDelta,
+			ArtifactDelta:              event.Actions.ArtifactDelta,
+			RequestedToolConfirmations: event.Actions.RequestedToolConfirmations,
+			SkipSummarization:          event.Actions.SkipSummarization,
+			TransferToAgent:            event.Actions.TransferToAgent,
+			Escalate:                   event.Actions.Escalate,
+		})
+	}
+	return transcript
+}
+
+func (e *TranscriptEvent) event() *session.Event {
+	return &session.Event{
+		LLMResponse: model.LLMResponse{
+			Content:           e.Content,
+			CitationMetadata:  e.CitationMetadata,
+			GroundingMetadata: e.GroundingMetadata,
+			UsageMetadata:     e.UsageMetadata,
+			CustomMetadata:    e.CustomMetadata,
+			TurnComplete:      e.TurnComplete,
+			Interrupted:       e.Interrupted,
+			ErrorCode:         e.ErrorCode,
+			ErrorMessage:      e.ErrorMessage,
+			FinishReason:      e.FinishReason,
+		},
+		ID:                 e.ID,
+		Timestamp:          e.Timestamp,
+		InvocationID:       e.InvocationID,
+		Branch:             e.Branch,
+		Author:             e.Author,
+		LongRunningToolIDs: e.LongRunningToolIDs,
+		Actions: session.EventActions{
+			StateDelta:                 e.StateDelta,
+			ArtifactDelta:              e.ArtifactDelta,
+			RequestedToolConfirmations: e.RequestedToolConfirmations,
+			SkipSummarization:          e.SkipSummarization,
+			TransferToAgent:            e.TransferToAgent,
+			Escalate:                   e.Escalate,
+		},
+	}
+}
+
+type ImportRequest struct {
+	Transcript *Transcript
+	// AppName, UserID and SessionID default to the ones of the transcript.
+	AppName   string
+	UserID    string
+	SessionID string
+}
+
+// Import creates a session from a transcript. Artifacts referenced by the events are not imported.
+func Import(ctx context.Context, service session.Service, req *ImportRequest) (session.Session, error) {
+	transcript := req.Transcript
+	if transcript == nil {
+		return nil, fmt.Errorf("transcript is nil")
+	}
+	if transcript.Version != TranscriptVersion {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedTranscript, transcript.Version)
+	}
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" {
+		appName = transcript.AppName
+	}
+	if userID == "" {
+		userID = transcript.UserID
+	}
+	if sessionID == "" {
+		sessionID = transcript.SessionID
+	}
+
+	events := make([]*session.Event, 0, len(transcript.Events))
+	for _, e := range transcript.Events {
+		events = append(events, e.event())
+	}
+	state := map[string]any{}
+	for key, value := range transcript.State {
+		if isSessionKey(key) {
+			state[key] = value
+		}
+	}
+	return create(ctx, service, appName, userID, sessionID, untouched(state, slices.Values(events)), events)
+}
@@ -27,6 +27,9 @@ type ShortTermBackendType string
 const (
 	BackendShortTermLocal      ShortTermBackendType = "local"
 	BackendShortTermPostgreSQL ShortTermBackendType = "postgresql"
+	BackendShortTermMySQL      ShortTermBackendType = "mysql"
+	BackendShortTermSQLite     ShortTermBackendType = "sqlite"
+	BackendShortTermRedis      ShortTermBackendType = "redis"
 )
 
 // NewShortTermMemoryService creates a new short term memory service.
@@ -59,6 +62,48 @@ func NewShortTermMemoryService(backend ShortTermBackendType, config interface{})
 			return nil, err
 		}
 		return sessionService, nil
+	case BackendShortTermMySQL:
+		var mysqlCfg *short_term_memory_backends.MysqlBackendConfig
+		if config == nil {
+			mysqlCfg = &short_term_memory_backends.MysqlBackendConfig{
+				MysqlConfig: configs.GetGlobalConfig().Database.Mysql,
+			}
+		} else {
+			var ok bool
+			mysqlCfg, ok = config.(*short_term_memory_backends.MysqlBackendConfig)
+			if !ok {
+				return nil, fmt.Errorf("mysql backend requires *MysqlBackendConfig, got %T", config)
+			}
+		}
+		return short_term_memory_backends.NewMySqlSTMBackend(mysqlCfg)
+	case BackendShortTermSQLite:
+		var sqliteCfg *short_term_memory_backends.SqliteBackendConfig
+		if config == nil {
+			sqliteCfg = &short_term_memory_backends.SqliteBackendConfig{
+				SqliteConfig: configs.GetGlobalConfig().Database.Sqlite,
+			}
+		} else {
+			var ok bool
+			sqliteCfg, ok = config.(*short_term_memory_backends.SqliteBackendConfig)
+			if !ok {
+				return nil, fmt.Errorf("sqlite backend requires *SqliteBackendConfig, got %T", config)
+			}
+		}
+		return short_term_memory_backends.NewSqliteSTMBackend(sqliteCfg)
+	case BackendShortTermRedis:
+		var redisCfg *short_term_memory_backends.RedisBackendConfig
+		if config == nil {
+			redisCfg = &short_term_memory_backends.RedisBackendConfig{
+				RedisConfig: configs.GetGlobalConfig().Database.Redis,
+			}
+		} else {
+			var ok bool
+			redisCfg, ok = config.(*short_term_memory_backends.RedisBackendConfig)
+			if !ok {
+				return nil, fmt.Errorf("redis backend requires *RedisBackendConfig, got %T", config)
+			}
+		}
+		return short_term_memory_backends.NewRedisSTMBackend(redisCfg)
 	default:
 		return nil, fmt.Errorf("unsupported backend type: %s", backend)
 	}
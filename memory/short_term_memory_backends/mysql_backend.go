@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlTLSConfigPrefix starts the name the TLS config built from MysqlConfig.CACert is registered under. The
+// driver keeps the configs in a global registry, so the name ends with a digest of the certificate, so that
+// backends with different CAs do not replace each other's config.
+const mysqlTLSConfigPrefix = "veadk-"
+
+type MysqlBackendConfig struct {
+	*configs.MysqlConfig
+}
+
+func NewMySqlSTMBackend(config *MysqlBackendConfig) (session.Service, error) {
+	if config == nil || config.MysqlConfig == nil {
+		return nil, fmt.Errorf("mysql config is nil")
+	}
+	dsn, err := mysqlDSN(config.MysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionService, err := database.NewSessionService(
+		mysql.Open(dsn),
+		&gorm.Config{PrepareStmt: true, Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		log.Error(fmt.Sprintf("init DatabaseSessionService failed: %v", err))
+		return nil, err
+	}
+	if err := database.AutoMigrate(sessionService); err != nil {
+		return nil, fmt.Errorf("AutoMigrate DatabaseSessionService failed: %w", err)
+	}
+
+	return sessionService, nil
+}
+
+// mysqlDSN returns the DSN of config. Like the postgresql URL, the user and password are escaped by the
+// driver when the DSN is built from the fields. The options the session tables need, parseTime and utf8mb4,
+// are added to a given DSN too.
+func mysqlDSN(config *configs.MysqlConfig) (string, error) {
+	var cfg *mysqldriver.Config
+	if config.DSN != "" {
+		log.Info("DSN is set, ignore backend option")
+		var err error
+		cfg, err = mysqldriver.ParseDSN(config.DSN)
+		if err != nil {
+			return "", fmt.Errorf("invalid mysql DSN: %w", err)
+		}
+	} else {
+		port := config.Port
+		if port == "" {
+			port = "3306"
+		}
+		cfg = mysqldriver.NewConfig()
+		cfg.User = config.User
+		cfg.Passwd = config.Password
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(config.Host, port)
+		cfg.DBName = config.Database
+	}
+	cfg.ParseTime = true
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	if _, ok := cfg.Params["charset"]; !ok {
+		cfg.Params["charset"] = "utf8mb4"
+	}
+
+	switch {
+	case config.CACert != "":
+		pem, err := os.ReadFile(config.CACert)
+		if err != nil {
+			return "", fmt.Errorf("read mysql CA certificate failed: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("no certificate found in %s", config.CACert)
+		}
+		digest := sha256.Sum256(pem)
+		name := mysqlTLSConfigPrefix + hex.EncodeToString(digest[:8])
+		if err := mysqldriver.RegisterTLSConfig(name, &tls.Config{RootCAs: pool}); err != nil {
+			return "", err
+		}
+		cfg.TLSConfig = name
+	case config.TLS != "":
+		cfg.TLSConfig = config.TLS
+	}
+	return cfg.FormatDSN(), nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/configs"
+)
+
+func TestMysqlDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *configs.MysqlConfig
+		wantDSN string
+		wantErr bool
+	}{
+		{
+			name: "fields with special characters",
+			config: &configs.MysqlConfig{
+				User:     "test@",
+				Password: "p@ss:w/rd",
+				Host:     "127.0.0.1",
+				Database: "test_veadk",
+			},
+			wantDSN: "test@:p@ss:w/rd@tcp(127.0.0.1:3306)/test_veadk?parseTime=true&charset=utf8mb4",
+		},
+		{
+			name: "dsn gets the required options",
+			config: &configs.MysqlConfig{
+				DSN: "root:secret@tcp(rds.example.com:3307)/veadk?charset=utf8",
+				TLS: "skip-verify",
+			},
+			wantDSN: "root:secret@tcp(rds.example.com:3307)/veadk?parseTime=true&tls=skip-verify&charset=utf8",
+		},
+		{
+			name:    "invalid dsn",
+			config:  &configs.MysqlConfig{DSN: "root:secret@tcp(rds.example.com:3307"},
+			wantErr: true,
+		},
+		{
+			name:    "missing ca certificate",
+			config:  &configs.MysqlConfig{Host: "127.0.0.1", CACert: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := mysqlDSN(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDSN, dsn)
+		})
+	}
+}
+
+// writeCA writes a new self-signed CA certificate and returns its path.
+func writeCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path
+}
+
+func TestMysqlDSNWithCACert(t *testing.T) {
+	tlsName := func(path string) string {
+		dsn, err := mysqlDSN(&configs.MysqlConfig{User: "root", Host: "rds.example.com", Port: "3306", Database: "veadk", CACert: path})
+		require.NoError(t, err)
+		cfg, err := mysqldriver.ParseDSN(dsn)
+		require.NoError(t, err)
+		assert.Equal(t, "root", cfg.User)
+		assert.NotNil(t, cfg.TLS)
+		return cfg.TLSConfig
+	}
+
+	first, second := writeCA(t), writeCA(t)
+	assert.True(t, strings.HasPrefix(tlsName(first), mysqlTLSConfigPrefix))
+	assert.Equal(t, tlsName(first), tlsName(first))
+	assert.NotEqual(t, tlsName(first), tlsName(second), "backends with different CAs keep their own TLS config")
+}
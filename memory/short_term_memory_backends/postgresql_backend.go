@@ -15,25 +15,202 @@
 package short_term_memory_backends
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/volcengine/veadk-go/configs"
 	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/session/database"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const (
+	DefaultPostgresqlMaxOpenConns    = 20
+	DefaultPostgresqlMaxIdleConns    = 10
+	DefaultPostgresqlConnMaxLifetime = 30 * time.Minute
+	DefaultPostgresqlConnMaxIdleTime = 5 * time.Minute
+)
+
+// PoolConfig sizes the connection pool of the primary and of each replica.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout makes PostgreSQL cancel the statements running longer. Zero keeps the server setting.
+	StatementTimeout time.Duration
+}
+
 type PostgresqlBackendConfig struct {
 	*configs.CommonDatabaseConfig
+	// Retention, when set, starts a RetentionJanitor deleting the old sessions and events in the background.
+	Retention *RetentionPolicy
+	// Pool defaults field by field to the pool settings of CommonDatabaseConfig, then to the DefaultPostgresql*
+	// values.
+	Pool PoolConfig
+	// ReplicaURLs are read replicas serving Get and List in turn. Defaults to CommonDatabaseConfig.ReplicaURLs.
+	ReplicaURLs []string
+	// StrictMigration makes NewPostgreSqlSTMBackend fail when AutoMigrate fails, instead of logging the failure.
+	// Defaults to CommonDatabaseConfig.StrictMigration.
+	StrictMigration bool
+}
+
+// resolve fills the pool settings, replicas and migration mode left unset from CommonDatabaseConfig.
+func (c *PostgresqlBackendConfig) resolve() error {
+	ints := []struct {
+		value *int
+		text  string
+		def   int
+	}{
+		{&c.Pool.MaxOpenConns, c.MaxOpenConns, DefaultPostgresqlMaxOpenConns},
+		{&c.Pool.MaxIdleConns, c.MaxIdleConns, DefaultPostgresqlMaxIdleConns},
+	}
+	for _, field := range ints {
+		if *field.value != 0 {
+			continue
+		}
+		*field.value = field.def
+		if field.text != "" {
+			n, err := strconv.Atoi(field.text)
+			if err != nil {
+				return fmt.Errorf("invalid postgresql pool size %q: %w", field.text, err)
+			}
+			*field.value = n
+		}
+	}
+
+	durations := []struct {
+		value *time.Duration
+		text  string
+		def   time.Duration
+	}{
+		{&c.Pool.ConnMaxLifetime, c.ConnMaxLifetime, DefaultPostgresqlConnMaxLifetime},
+		{&c.Pool.ConnMaxIdleTime, c.ConnMaxIdleTime, DefaultPostgresqlConnMaxIdleTime},
+		{&c.Pool.StatementTimeout, c.StatementTimeout, 0},
+	}
+	for _, field := range durations {
+		if *field.value != 0 {
+			continue
+		}
+		*field.value = field.def
+		if field.text != "" {
+			d, err := time.ParseDuration(field.text)
+			if err != nil {
+				return fmt.Errorf("invalid postgresql pool duration %q: %w", field.text, err)
+			}
+			*field.value = d
+		}
+	}
+
+	if len(c.ReplicaURLs) == 0 && c.CommonDatabaseConfig.ReplicaURLs != "" {
+		for _, replica := range strings.Split(c.CommonDatabaseConfig.ReplicaURLs, ",") {
+			if replica = strings.TrimSpace(replica); replica != "" {
+				c.ReplicaURLs = append(c.ReplicaURLs, replica)
+			}
+		}
+	}
+	if !c.StrictMigration && c.CommonDatabaseConfig.StrictMigration != "" {
+		strict, err := strconv.ParseBool(c.CommonDatabaseConfig.StrictMigration)
+		if err != nil {
+			return fmt.Errorf("invalid postgresql strict migration %q: %w", c.CommonDatabaseConfig.StrictMigration, err)
+		}
+		c.StrictMigration = strict
+	}
+	return nil
+}
+
+// PostgresqlService is the session.Service returned by NewPostgreSqlSTMBackend.
+type PostgresqlService struct {
+	session.Service
+	db       *gorm.DB
+	replicas []session.Service
+	next     atomic.Uint64
+	pools    []*sql.DB
+	janitor  *RetentionJanitor
+	// unregister stops exporting the pool metrics.
+	unregister []func()
+}
+
+// Get reads the session from a replica, unless the primary has a newer version of it: a session returned by Get
+// can always be appended to. The app and user state may lag behind the primary.
+func (s *PostgresqlService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	replica := s.replica()
+	if replica == nil {
+		return s.Service.Get(ctx, req)
+	}
+	resp, err := replica.Get(ctx, req)
+	if err != nil {
+		// the session may not be replicated yet
+		return s.Service.Get(ctx, req)
+	}
+	var updated time.Time
+	err = s.db.WithContext(ctx).Table("sessions").
+		Select("update_time").
+		Where("app_name = ? AND user_id = ? AND id = ?", req.AppName, req.UserID, req.SessionID).
+		Row().Scan(&updated)
+	if err != nil || updated.UnixMicro() > resp.Session.LastUpdateTime().UnixMicro() {
+		return s.Service.Get(ctx, req)
+	}
+	return resp, nil
+}
+
+// List reads the sessions from a replica, falling back to the primary when the replica fails.
+func (s *PostgresqlService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	if replica := s.replica(); replica != nil {
+		resp, err := replica.List(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		log.Warn("list sessions from replica failed, reading the primary", "error", err)
+	}
+	return s.Service.List(ctx, req)
+}
+
+func (s *PostgresqlService) replica() session.Service {
+	if len(s.replicas) == 0 {
+		return nil
+	}
+	return s.replicas[(s.next.Add(1)-1)%uint64(len(s.replicas))]
+}
+
+// PurgeUser deletes every session, event and user state of a user in all apps, including the archived ones, to
+// comply with erasure requests. It returns the number of deleted sessions.
+func (s *PostgresqlService) PurgeUser(ctx context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return 0, fmt.Errorf("user id is required")
+	}
+	return purgeUser(ctx, s.db, userID, DefaultRetentionBatchSize)
 }
 
+// Close stops the retention janitor and closes the connections to the primary and the replicas.
+func (s *PostgresqlService) Close() error {
+	if s.janitor != nil {
+		_ = s.janitor.Close()
+	}
+	for _, unregister := range s.unregister {
+		unregister()
+	}
+	var errs []error
+	for _, pool := range s.pools {
+		errs = append(errs, pool.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// NewPostgreSqlSTMBackend stores the sessions in PostgreSQL. The service it returns is a *PostgresqlService.
 func NewPostgreSqlSTMBackend(config *PostgresqlBackendConfig) (session.Service, error) {
-	if config == nil {
+	if config == nil || config.CommonDatabaseConfig == nil {
 		return nil, fmt.Errorf("postgresql config is nil")
 	}
 	if config.DBUrl != "" {
@@ -46,27 +223,97 @@ func NewPostgreSqlSTMBackend(config *PostgresqlBackendConfig) (session.Service,
 			)
 		}
 	} else {
-		encodedUsername := url.QueryEscape(config.User)
-		encodedPassword := url.QueryEscape(config.Password)
-
-		config.DBUrl = fmt.Sprintf(
-			"postgresql://%s:%s@%s:%s/%s",
-			encodedUsername, encodedPassword,
-			config.Host, config.Port, config.Database,
-		)
+		config.DBUrl = config.PostgresqlURL()
+	}
+	if err := config.resolve(); err != nil {
+		return nil, err
 	}
 
-	sessionService, err := database.NewSessionService(
-		postgres.Open(config.DBUrl),
-		&gorm.Config{PrepareStmt: true, Logger: log.NewGormLogger(slog.LevelError)},
-	)
+	service := &PostgresqlService{}
+	db, sessionService, err := service.open(config.DBUrl, "sessions", config.Pool)
 	if err != nil {
 		log.Error(fmt.Sprintf("init DatabaseSessionService failed: %v", err))
+		_ = service.Close()
 		return nil, err
 	}
+	service.Service, service.db = sessionService, db
 	if initErr := database.AutoMigrate(sessionService); initErr != nil {
+		if config.StrictMigration {
+			_ = service.Close()
+			return nil, fmt.Errorf("AutoMigrate DatabaseSessionService failed: %w", initErr)
+		}
 		log.Error(fmt.Sprintf("AutoMigrate DatabaseSessionService failed: %v", initErr))
 	}
 
-	return sessionService, nil
+	for i, replicaURL := range config.ReplicaURLs {
+		_, replica, err := service.open(replicaURL, fmt.Sprintf("sessions_replica_%d", i), config.Pool)
+		if err != nil {
+			_ = service.Close()
+			return nil, fmt.Errorf("connect to replica %d failed: %w", i, err)
+		}
+		service.replicas = append(service.replicas, replica)
+	}
+
+	if config.Retention != nil {
+		service.janitor, err = NewRetentionJanitor(db, *config.Retention)
+		if err != nil {
+			_ = service.Close()
+			return nil, err
+		}
+		if err := service.janitor.Start(); err != nil {
+			_ = service.Close()
+			return nil, err
+		}
+	}
+	return service, nil
+}
+
+// open connects a pool to a database and exports its metrics as pool name. The returned gorm.DB shares the
+// connections of the session service, for the janitor and PurgeUser.
+func (s *PostgresqlService) open(dsn, name string, pool PoolConfig) (*gorm.DB, session.Service, error) {
+	db, err := gorm.Open(
+		postgres.Open(withStatementTimeout(dsn, pool.StatementTimeout)),
+		&gorm.Config{DisableAutomaticPing: true, Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	s.pools = append(s.pools, sqlDB)
+	s.unregister = append(s.unregister, observability.RegisterDBPool(name, sqlDB.Stats))
+
+	sessionService, err := database.NewSessionService(
+		postgres.New(postgres.Config{Conn: sqlDB}),
+		&gorm.Config{PrepareStmt: true, Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, sessionService, nil
+}
+
+// withStatementTimeout sets the statement_timeout parameter of a URL or key=value connection string.
+func withStatementTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return dsn
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if !strings.Contains(dsn, "://") {
+		return dsn + " statement_timeout=" + ms
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+	query := u.Query()
+	query.Set("statement_timeout", ms)
+	u.RawQuery = query.Encode()
+	return u.String()
 }
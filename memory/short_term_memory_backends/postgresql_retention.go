@@ -0,0 +1,405 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volcengine/veadk-go/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultRetentionInterval  = time.Hour
+	DefaultRetentionBatchSize = 500
+)
+
+var (
+	ErrNoRetention    = errors.New("retention policy requires a max age or max events")
+	ErrJanitorStarted = errors.New("retention janitor already started")
+)
+
+// RetentionPolicy bounds what the session database keeps. The tables are the ones of the adk database session
+// service: sessions, events and user_states.
+type RetentionPolicy struct {
+	// MaxAge deletes the sessions not updated for longer than MaxAge, with their events. Zero keeps them forever.
+	MaxAge time.Duration
+	// AppMaxAge overrides MaxAge for some apps. A zero duration keeps the sessions of the app forever.
+	AppMaxAge map[string]time.Duration
+	// MaxEvents deletes the oldest events of the sessions having more than MaxEvents events. Zero keeps them all.
+	MaxEvents int
+	// Archive copies the deleted sessions and events to the sessions_archive and events_archive tables, in the
+	// transaction deleting them.
+	Archive bool
+	// Interval is the period of the janitor runs, DefaultRetentionInterval by default.
+	Interval time.Duration
+	// BatchSize is the number of rows deleted per transaction, DefaultRetentionBatchSize by default. Small
+	// batches keep the locks short while agents keep writing.
+	BatchSize int
+	// OnReport receives the report of every run of the janitor. Reports are logged by default.
+	OnReport func(*RetentionReport)
+}
+
+// RetentionReport counts the rows deleted by a run of the janitor.
+type RetentionReport struct {
+	ExpiredSessions int64
+	// ExpiredEvents are the events of the expired sessions.
+	ExpiredEvents int64
+	// TrimmedEvents are the events deleted by MaxEvents.
+	TrimmedEvents int64
+	Archived      bool
+}
+
+// RetentionJanitor enforces a RetentionPolicy. NewPostgreSqlSTMBackend starts one when
+// PostgresqlBackendConfig.Retention is set.
+type RetentionJanitor struct {
+	db     *gorm.DB
+	policy RetentionPolicy
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRetentionJanitor(db *gorm.DB, policy RetentionPolicy) (*RetentionJanitor, error) {
+	enforced := policy.MaxAge > 0 || policy.MaxEvents > 0
+	for _, age := range policy.AppMaxAge {
+		enforced = enforced || age > 0
+	}
+	if !enforced {
+		return nil, ErrNoRetention
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultRetentionInterval
+	}
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = DefaultRetentionBatchSize
+	}
+	if policy.OnReport == nil {
+		policy.OnReport = logRetentionReport
+	}
+	return &RetentionJanitor{db: db, policy: policy}, nil
+}
+
+// Start enforces the policy every Interval in the background, until Close is called.
+func (j *RetentionJanitor) Start() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		return ErrJanitorStarted
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(j.policy.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report, err := j.RunOnce(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Warn("enforce session retention failed", "error", err)
+				}
+				if report != nil {
+					j.policy.OnReport(report)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// RunOnce enforces the policy once. The report counts the rows deleted before an error.
+func (j *RetentionJanitor) RunOnce(ctx context.Context) (*RetentionReport, error) {
+	report := &RetentionReport{Archived: j.policy.Archive}
+	if j.policy.Archive {
+		for _, table := range []string{"sessions", "events"} {
+			if err := j.db.WithContext(ctx).Exec(fmt.Sprintf(
+				"CREATE TABLE IF NOT EXISTS %s_archive AS SELECT * FROM %s WHERE 1 = 0", table, table)).Error; err != nil {
+				return report, fmt.Errorf("create %s_archive failed: %w", table, err)
+			}
+		}
+	}
+
+	for _, rule := range j.ageRules() {
+		cutoff := time.Now().Add(-rule.age)
+		where, args := "update_time < @cutoff", map[string]any{"cutoff": cutoff}
+		if rule.app != "" {
+			where += " AND app_name = @app"
+			args["app"] = rule.app
+		} else if len(rule.except) > 0 {
+			where += " AND app_name NOT IN @except"
+			args["except"] = rule.except
+		}
+
+		sessions, events, err := j.expireSessions(ctx, where, args)
+		report.ExpiredSessions += sessions
+		report.ExpiredEvents += events
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if j.policy.MaxEvents > 0 {
+		n, err := j.trimEvents(ctx)
+		report.TrimmedEvents += n
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Close stops the scheduled runs and waits for the current one to return.
+func (j *RetentionJanitor) Close() error {
+	j.mu.Lock()
+	cancel := j.cancel
+	j.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	j.wg.Wait()
+	return nil
+}
+
+type ageRule struct {
+	app    string
+	age    time.Duration
+	except []string
+}
+
+// ageRules returns one rule per app of AppMaxAge, and a rule applying MaxAge to the other apps.
+func (j *RetentionJanitor) ageRules() []ageRule {
+	var rules []ageRule
+	apps := slices.Sorted(maps.Keys(j.policy.AppMaxAge))
+	for _, app := range apps {
+		if age := j.policy.AppMaxAge[app]; age > 0 {
+			rules = append(rules, ageRule{app: app, age: age})
+		}
+	}
+	if j.policy.MaxAge > 0 {
+		rules = append(rules, ageRule{age: j.policy.MaxAge, except: apps})
+	}
+	return rules
+}
+
+// expireSessions deletes the sessions matching where with their events, BatchSize sessions per transaction. The
+// sessions of a batch are locked while they and their events are deleted, and where is checked again for both, so
+// that a session updated in between keeps its events.
+func (j *RetentionJanitor) expireSessions(ctx context.Context, where string, args map[string]any) (int64, int64, error) {
+	columns := strings.Join(sessionKeys, ", ")
+	var sessions, events int64
+	for {
+		var selected int
+		var batchSessions, batchEvents int64
+		err := j.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var rows []map[string]any
+			err := tx.Table("sessions").
+				Select(columns).
+				Where(where, args).
+				Order(columns).
+				Limit(j.policy.BatchSize).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Find(&rows).Error
+			if err != nil {
+				return fmt.Errorf("select sessions to delete failed: %w", err)
+			}
+			selected = len(rows)
+			if selected == 0 {
+				return nil
+			}
+
+			batchArgs := maps.Clone(args)
+			batchArgs["batch"] = keyBatch(rows, sessionKeys)
+			condition := fmt.Sprintf("(%s) AND (%s) IN @batch", where, columns)
+			batchEvents, err = archiveAndDelete(tx, "events",
+				"(app_name, user_id, session_id) IN (SELECT app_name, user_id, id FROM sessions WHERE "+condition+")", batchArgs, j.policy.Archive)
+			if err != nil {
+				return err
+			}
+			batchSessions, err = archiveAndDelete(tx, "sessions", condition, batchArgs, j.policy.Archive)
+			return err
+		})
+		if err != nil {
+			return sessions, events, err
+		}
+		sessions += batchSessions
+		events += batchEvents
+		if selected < j.policy.BatchSize {
+			return sessions, events, nil
+		}
+	}
+}
+
+// trimEvents deletes the oldest events of the sessions having more than MaxEvents events.
+func (j *RetentionJanitor) trimEvents(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		var sessions []struct {
+			AppName   string
+			UserID    string
+			SessionID string
+		}
+		err := j.db.WithContext(ctx).Table("events").
+			Select("app_name, user_id, session_id").
+			Group("app_name, user_id, session_id").
+			Having("COUNT(*) > ?", j.policy.MaxEvents).
+			Limit(j.policy.BatchSize).
+			Find(&sessions).Error
+		if err != nil {
+			return total, fmt.Errorf("find sessions to trim failed: %w", err)
+		}
+		if len(sessions) == 0 {
+			return total, nil
+		}
+		for _, s := range sessions {
+			n, err := j.deleteBatches(ctx, "events", eventKeys,
+				"app_name = @app AND user_id = @user AND session_id = @session AND id NOT IN "+
+					"(SELECT id FROM events WHERE app_name = @app AND user_id = @user AND session_id = @session "+
+					"ORDER BY timestamp DESC, id DESC LIMIT @max)",
+				map[string]any{"app": s.AppName, "user": s.UserID, "session": s.SessionID, "max": j.policy.MaxEvents},
+				j.policy.Archive)
+			total += n
+			if err != nil {
+				return total, err
+			}
+		}
+	}
+}
+
+var (
+	eventKeys     = []string{"app_name", "user_id", "session_id", "id"}
+	sessionKeys   = []string{"app_name", "user_id", "id"}
+	userStateKeys = []string{"app_name", "user_id"}
+)
+
+func (j *RetentionJanitor) deleteBatches(ctx context.Context, table string, keys []string, where string, args map[string]any, archive bool) (int64, error) {
+	return deleteBatches(ctx, j.db, table, keys, where, args, j.policy.BatchSize, archive)
+}
+
+// deleteBatches deletes the rows of table matching where, batchSize rows per transaction. Each batch is selected
+// by its primary keys, and where is checked again when deleting, so that the rows updated in between are kept.
+// With archive, the rows are copied to the <table>_archive table before being deleted.
+func deleteBatches(ctx context.Context, db *gorm.DB, table string, keys []string, where string, args map[string]any, batchSize int, archive bool) (int64, error) {
+	columns := strings.Join(keys, ", ")
+	var total int64
+	for {
+		var rows []map[string]any
+		err := db.WithContext(ctx).Table(table).
+			Select(columns).
+			Where(where, args).
+			Order(columns).
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return total, fmt.Errorf("select %s to delete failed: %w", table, err)
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		batchArgs := maps.Clone(args)
+		batchArgs["batch"] = keyBatch(rows, keys)
+		condition := fmt.Sprintf("(%s) AND (%s) IN @batch", where, columns)
+
+		var deleted int64
+		err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			deleted, err = archiveAndDelete(tx, table, condition, batchArgs, archive)
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if len(rows) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// keyBatch returns the primary keys of rows, in the order of keys.
+func keyBatch(rows []map[string]any, keys []string) [][]any {
+	batch := make([][]any, len(rows))
+	for i, row := range rows {
+		for _, key := range keys {
+			batch[i] = append(batch[i], row[key])
+		}
+	}
+	return batch
+}
+
+// archiveAndDelete deletes the rows of table matching condition in tx, copying them to <table>_archive first
+// with archive.
+func archiveAndDelete(tx *gorm.DB, table, condition string, args map[string]any, archive bool) (int64, error) {
+	if archive {
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s_archive SELECT * FROM %s WHERE %s", table, table, condition), args).Error; err != nil {
+			return 0, fmt.Errorf("archive %s failed: %w", table, err)
+		}
+	}
+	result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", table, condition), args)
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete %s failed: %w", table, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// purgeUser deletes every session, event and user state of a user, in all apps, including the archived ones.
+func purgeUser(ctx context.Context, db *gorm.DB, userID string, batchSize int) (int64, error) {
+	args := map[string]any{"user": userID}
+	if _, err := deleteBatches(ctx, db, "events", eventKeys, "user_id = @user", args, batchSize, false); err != nil {
+		return 0, err
+	}
+	sessions, err := deleteBatches(ctx, db, "sessions", sessionKeys, "user_id = @user", args, batchSize, false)
+	if err != nil {
+		return sessions, err
+	}
+	if _, err := deleteBatches(ctx, db, "user_states", userStateKeys, "user_id = @user", args, batchSize, false); err != nil {
+		return sessions, err
+	}
+	for _, table := range []string{"events_archive", "sessions_archive"} {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		if err := db.WithContext(ctx).Exec(fmt.Sprintf("DELETE FROM %s WHERE user_id = ?", table), userID).Error; err != nil {
+			return sessions, fmt.Errorf("delete %s failed: %w", table, err)
+		}
+	}
+	return sessions, nil
+}
+
+func logRetentionReport(report *RetentionReport) {
+	if report.ExpiredSessions == 0 && report.ExpiredEvents == 0 && report.TrimmedEvents == 0 {
+		return
+	}
+	action := "deleted"
+	if report.Archived {
+		action = "archived"
+	}
+	log.Infof("Session retention %s %d expired sessions with %d events, and %d events over the limit",
+		action, report.ExpiredSessions, report.ExpiredEvents, report.TrimmedEvents)
+}
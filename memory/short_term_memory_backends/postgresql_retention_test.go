@@ -0,0 +1,175 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"gorm.io/gorm"
+)
+
+// newRetentionDB returns a session service and a connection to its database. The janitor only uses standard
+// SQL, so SQLite stands in for PostgreSQL.
+func newRetentionDB(t *testing.T) (session.Service, *gorm.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "sessions.db") + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	service, err := database.NewSessionService(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(service))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	return service, db
+}
+
+func createSession(t *testing.T, service session.Service, app, user, id string, events int, age time.Duration) {
+	t.Helper()
+	created, err := service.Create(t.Context(), &session.CreateRequest{AppName: app, UserID: user, SessionID: id, State: map[string]any{"user:name": user}})
+	require.NoError(t, err)
+	for i := range events {
+		event := textEvent("user", "message", nil)
+		event.Timestamp = time.Now().Add(-age).Add(time.Duration(i) * time.Second)
+		require.NoError(t, service.AppendEvent(t.Context(), created.Session, event))
+	}
+}
+
+func backdate(t *testing.T, db *gorm.DB, age time.Duration) {
+	t.Helper()
+	require.NoError(t, db.Exec("UPDATE sessions SET update_time = ? WHERE id LIKE 'old%'", time.Now().Add(-age)).Error)
+}
+
+func count(t *testing.T, db *gorm.DB, table, where string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	require.NoError(t, db.Table(table).Where(where, args...).Count(&n).Error)
+	return n
+}
+
+func TestRetentionJanitor(t *testing.T) {
+	service, db := newRetentionDB(t)
+	createSession(t, service, "chat", "alice", "old-chat", 3, 0)
+	createSession(t, service, "chat", "alice", "new-chat", 3, 0)
+	createSession(t, service, "audit", "alice", "old-audit", 2, 0)
+	createSession(t, service, "support", "bob", "old-support", 2, 0)
+	backdate(t, db, 48*time.Hour)
+
+	janitor, err := NewRetentionJanitor(db, RetentionPolicy{
+		MaxAge:    24 * time.Hour,
+		AppMaxAge: map[string]time.Duration{"audit": 0, "support": 72 * time.Hour},
+		BatchSize: 2,
+		Archive:   true,
+	})
+	require.NoError(t, err)
+	report, err := janitor.RunOnce(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &RetentionReport{ExpiredSessions: 1, ExpiredEvents: 3, Archived: true}, report)
+
+	var ids []string
+	require.NoError(t, db.Table("sessions").Order("id").Pluck("id", &ids).Error)
+	assert.Equal(t, []string{"new-chat", "old-audit", "old-support"}, ids)
+	assert.Equal(t, int64(0), count(t, db, "events", "session_id = ?", "old-chat"))
+	assert.Equal(t, int64(1), count(t, db, "sessions_archive", "id = ?", "old-chat"))
+	assert.Equal(t, int64(3), count(t, db, "events_archive", "session_id = ?", "old-chat"))
+
+	report, err = janitor.RunOnce(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &RetentionReport{Archived: true}, report)
+}
+
+func TestRetentionJanitorDeletesSessionsWithEvents(t *testing.T) {
+	service, db := newRetentionDB(t)
+	createSession(t, service, "chat", "alice", "old-chat", 3, 0)
+	backdate(t, db, 48*time.Hour)
+	require.NoError(t, db.Callback().Raw().Before("gorm:raw").Register("fail_session_delete", func(tx *gorm.DB) {
+		if strings.HasPrefix(tx.Statement.SQL.String(), "DELETE FROM sessions") {
+			_ = tx.AddError(errors.New("session delete failed"))
+		}
+	}))
+
+	janitor, err := NewRetentionJanitor(db, RetentionPolicy{MaxAge: 24 * time.Hour, BatchSize: 2})
+	require.NoError(t, err)
+	_, err = janitor.RunOnce(t.Context())
+	require.Error(t, err)
+	// the events are deleted in the transaction deleting their session, or not at all
+	assert.Equal(t, int64(1), count(t, db, "sessions", "id = ?", "old-chat"))
+	assert.Equal(t, int64(3), count(t, db, "events", "session_id = ?", "old-chat"))
+
+	require.NoError(t, db.Callback().Raw().Remove("fail_session_delete"))
+	report, err := janitor.RunOnce(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &RetentionReport{ExpiredSessions: 1, ExpiredEvents: 3}, report)
+}
+
+func TestRetentionJanitorMaxEvents(t *testing.T) {
+	service, db := newRetentionDB(t)
+	createSession(t, service, "chat", "alice", "long", 7, time.Hour)
+	createSession(t, service, "chat", "alice", "short", 2, time.Hour)
+
+	janitor, err := NewRetentionJanitor(db, RetentionPolicy{MaxEvents: 3, BatchSize: 2})
+	require.NoError(t, err)
+	report, err := janitor.RunOnce(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), report.TrimmedEvents)
+	assert.False(t, db.Migrator().HasTable("events_archive"))
+
+	got, err := service.Get(t.Context(), &session.GetRequest{AppName: "chat", UserID: "alice", SessionID: "long"})
+	require.NoError(t, err)
+	require.Equal(t, 3, got.Session.Events().Len())
+	latest := time.Now().Add(-time.Hour).Add(6 * time.Second)
+	assert.WithinDuration(t, latest, got.Session.Events().At(2).Timestamp, time.Second)
+	assert.Equal(t, int64(2), count(t, db, "events", "session_id = ?", "short"))
+}
+
+func TestPurgeUser(t *testing.T) {
+	service, db := newRetentionDB(t)
+	createSession(t, service, "chat", "alice", "old-1", 2, 0)
+	createSession(t, service, "support", "alice", "new-1", 2, 0)
+	createSession(t, service, "chat", "bob", "new-2", 2, 0)
+	backdate(t, db, 48*time.Hour)
+	janitor, err := NewRetentionJanitor(db, RetentionPolicy{MaxAge: time.Hour, Archive: true})
+	require.NoError(t, err)
+	_, err = janitor.RunOnce(t.Context())
+	require.NoError(t, err)
+
+	purged, err := (&PostgresqlService{Service: service, db: db}).PurgeUser(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	for _, table := range []string{"sessions", "events", "user_states", "sessions_archive", "events_archive"} {
+		assert.Equal(t, int64(0), count(t, db, table, "user_id = ?", "alice"), table)
+	}
+	assert.Equal(t, int64(1), count(t, db, "sessions", "user_id = ?", "bob"))
+	assert.Equal(t, int64(2), count(t, db, "events", "user_id = ?", "bob"))
+}
+
+func TestNewRetentionJanitor(t *testing.T) {
+	_, err := NewRetentionJanitor(nil, RetentionPolicy{AppMaxAge: map[string]time.Duration{"chat": 0}})
+	assert.ErrorIs(t, err, ErrNoRetention)
+
+	janitor, err := NewRetentionJanitor(nil, RetentionPolicy{MaxEvents: 10})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRetentionInterval, janitor.policy.Interval)
+	assert.Equal(t, DefaultRetentionBatchSize, janitor.policy.BatchSize)
+	require.NoError(t, janitor.Start())
+	assert.ErrorIs(t, janitor.Start(), ErrJanitorStarted)
+	require.NoError(t, janitor.Close())
+}
@@ -0,0 +1,548 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/volcengine/veadk-go/configs"
+	"google.golang.org/adk/session"
+)
+
+const (
+	DefaultRedisKeyPrefix  = "veadk"
+	DefaultRedisSessionTTL = 24 * time.Hour
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExists   = errors.New("session already exists")
+	// ErrStaleSession is returned by AppendEvent when another event was appended to the session since it was
+	// read. Get the session again and retry.
+	ErrStaleSession = errors.New("stale session")
+)
+
+type RedisBackendConfig struct {
+	*configs.RedisConfig
+	// Client reuses an open client instead of connecting from RedisConfig.
+	Client redis.UniversalClient
+	// KeyPrefix defaults to DefaultRedisKeyPrefix.
+	KeyPrefix string
+	// TTL is the sliding expiration of a session, renewed when it is read or written. Defaults to RedisConfig.TTL,
+	// then to DefaultRedisSessionTTL; a negative value keeps the sessions forever. App and user state never
+	// expire.
+	TTL time.Duration
+}
+
+// redisService stores each session in a hash of metadata, a hash of state and a list of events. The keys of an
+// app share a hash tag, so that the scripts updating them run on Redis Cluster.
+type redisService struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisSTMBackend(config *RedisBackendConfig) (session.Service, error) {
+	if config == nil {
+		return nil, fmt.Errorf("redis config is nil")
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRedisKeyPrefix
+	}
+	if config.TTL == 0 && config.RedisConfig != nil && config.RedisConfig.TTL != "" {
+		ttl, err := time.ParseDuration(config.RedisConfig.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis session TTL: %w", err)
+		}
+		config.TTL = ttl
+	}
+	if config.TTL == 0 {
+		config.TTL = DefaultRedisSessionTTL
+	}
+
+	client := config.Client
+	if client == nil {
+		if config.RedisConfig == nil {
+			return nil, fmt.Errorf("redis config is nil")
+		}
+		options, err := redisOptions(config.RedisConfig)
+		if err != nil {
+			return nil, err
+		}
+		client = redis.NewClient(options)
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("connect to redis failed: %w", err)
+	}
+	return &redisService{client: client, prefix: config.KeyPrefix, ttl: config.TTL}, nil
+}
+
+func redisOptions(config *configs.RedisConfig) (*redis.Options, error) {
+	if config.URL != "" {
+		options, err := redis.ParseURL(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis URL: %w", err)
+		}
+		return options, nil
+	}
+	port := config.Port
+	if port == "" {
+		port = "6379"
+	}
+	options := &redis.Options{
+		Addr:     net.JoinHostPort(config.Host, port),
+		Username: config.Username,
+		Password: config.Password,
+	}
+	if config.DB != "" {
+		db, err := strconv.Atoi(config.DB)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis DB %q: %w", config.DB, err)
+		}
+		options.DB = db
+	}
+	return options, nil
+}
+
+// redisKeys are the keys of a session.
+type redisKeys struct {
+	meta, state, events      string
+	appState, userState      string
+	appIndex, userIndex      string
+	indexMember, sessionPart string
+}
+
+func (s *redisService) keys(appName, userID, sessionID string) redisKeys {
+	app := fmt.Sprintf("%s:{%s}", s.prefix, url.QueryEscape(appName))
+	user := url.QueryEscape(userID)
+	id := url.QueryEscape(sessionID)
+	sessionKey := fmt.Sprintf("%s:session:%s:%s", app, user, id)
+	return redisKeys{
+		meta:        sessionKey,
+		state:       sessionKey + ":state",
+		events:      sessionKey + ":events",
+		appState:    app + ":state",
+		userState:   fmt.Sprintf("%s:user:%s:state", app, user),
+		appIndex:    app + ":sessions",
+		userIndex:   fmt.Sprintf("%s:user:%s:sessions", app, user),
+		indexMember: user + ":" + id,
+		sessionPart: id,
+	}
+}
+
+// list returns the KEYS of the scripts.
+func (k redisKeys) list() []string {
+	return []string{k.meta, k.state, k.events, k.appState, k.userState, k.appIndex, k.userIndex}
+}
+
+// stateArgs encodes the state deltas of the app, the user and the session as the counts of their entries
+// followed by the entries.
+func stateArgs(delta map[string]any) ([]any, error) {
+	app, user, sess := map[string]any{}, map[string]any{}, map[string]any{}
+	for key, value := range delta {
+		switch {
+		case strings.HasPrefix(key, session.KeyPrefixApp):
+			app[strings.TrimPrefix(key, session.KeyPrefixApp)] = value
+		case strings.HasPrefix(key, session.KeyPrefixUser):
+			user[strings.TrimPrefix(key, session.KeyPrefixUser)] = value
+		case strings.HasPrefix(key, session.KeyPrefixTemp):
+		default:
+			sess[key] = value
+		}
+	}
+	args := []any{len(app), len(user), len(sess)}
+	for _, scope := range []map[string]any{app, user, sess} {
+		for key, value := range scope {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("encode state %q failed: %w", key, err)
+			}
+			args = append(args, key, string(encoded))
+		}
+	}
+	return args, nil
+}
+
+// redisApplyState applies the state arguments starting at ARGV[first] to the app, user and session state hashes.
+const redisApplyState = `
+local function apply_state(first)
+  local counts = {tonumber(ARGV[first]), tonumber(ARGV[first + 1]), tonumber(ARGV[first + 2])}
+  local hashes = {KEYS[4], KEYS[5], KEYS[2]}
+  local i = first + 3
+  for scope = 1, 3 do
+    for _ = 1, counts[scope] do
+      redis.call('HSET', hashes[scope], ARGV[i], ARGV[i + 1])
+      i = i + 2
+    end
+  end
+end
+
+local function touch(ttl, score)
+  if ttl > 0 then
+    for k = 1, 3 do
+      if redis.call('EXISTS', KEYS[k]) == 1 then
+        redis.call('PEXPIRE', KEYS[k], ttl)
+      end
+    end
+  end
+  redis.call('ZADD', KEYS[6], score, ARGV[1])
+  redis.call('ZADD', KEYS[7], score, ARGV[2])
+end
+`
+
+// createScript creates a session unless it exists.
+// ARGV: index member, session id, update time, ttl, index score, state arguments.
+var createScript = redis.NewScript(redisApplyState + `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+  return -1
+end
+redis.call('HSET', KEYS[1], 'version', 0, 'updated', ARGV[3])
+apply_state(6)
+touch(tonumber(ARGV[4]), ARGV[5])
+return 0
+`)
+
+// appendScript appends an event to a session read at the expected version.
+// ARGV: index member, session id, update time, ttl, index score, expected version, event, state arguments.
+var appendScript = redis.NewScript(redisApplyState + `
+local version = redis.call('HGET', KEYS[1], 'version')
+if not version then
+  return -1
+end
+if version ~= ARGV[6] then
+  return -2
+end
+redis.call('RPUSH', KEYS[3], ARGV[7])
+apply_state(8)
+local next = redis.call('HINCRBY', KEYS[1], 'version', 1)
+redis.call('HSET', KEYS[1], 'updated', ARGV[3])
+touch(tonumber(ARGV[4]), ARGV[5])
+return next
+`)
+
+// score is the index score of a session: its expiration in unix milliseconds, or its update time when it does
+// not expire.
+func (s *redisService) score(now time.Time) int64 {
+	if s.ttl > 0 {
+		return now.Add(s.ttl).UnixMilli()
+	}
+	return now.UnixMilli()
+}
+
+func (s *redisService) ttlMillis() int64 {
+	if s.ttl > 0 {
+		return s.ttl.Milliseconds()
+	}
+	return 0
+}
+
+func (s *redisService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required")
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+
+	keys := s.keys(req.AppName, req.UserID, sessionID)
+	state, err := stateArgs(req.State)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	args := append([]any{keys.indexMember, keys.sessionPart, now.UnixMicro(), s.ttlMillis(), s.score(now)}, state...)
+	code, err := createScript.Run(ctx, s.client, keys.list(), args...).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("create redis session failed: %w", err)
+	}
+	if code == -1 {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, sessionID)
+	}
+
+	resp, err := s.Get(ctx, &session.GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: resp.Session}, nil
+}
+
+func (s *redisService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	keys := s.keys(appName, userID, sessionID)
+
+	start := int64(0)
+	if req.NumRecentEvents > 0 {
+		start = -int64(req.NumRecentEvents)
+	}
+	var meta, sessionState, appState, userState *redis.MapStringStringCmd
+	var events *redis.StringSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		meta = pipe.HGetAll(ctx, keys.meta)
+		sessionState = pipe.HGetAll(ctx, keys.state)
+		events = pipe.LRange(ctx, keys.events, start, -1)
+		appState = pipe.HGetAll(ctx, keys.appState)
+		userState = pipe.HGetAll(ctx, keys.userState)
+		if s.ttl > 0 {
+			pipe.PExpire(ctx, keys.meta, s.ttl)
+			pipe.PExpire(ctx, keys.state, s.ttl)
+			pipe.PExpire(ctx, keys.events, s.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get redis session failed: %w", err)
+	}
+	if len(meta.Val()) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	if s.ttl > 0 {
+		score := float64(s.score(time.Now()))
+		_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZAdd(ctx, keys.appIndex, redis.Z{Score: score, Member: keys.indexMember})
+			pipe.ZAdd(ctx, keys.userIndex, redis.Z{Score: score, Member: keys.sessionPart})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("renew redis session failed: %w", err)
+		}
+	}
+
+	sess, err := newRedisSession(appName, userID, sessionID, meta.Val())
+	if err != nil {
+		return nil, err
+	}
+	if sess.state, err = mergeRedisStates(appState.Val(), userState.Val(), sessionState.Val()); err != nil {
+		return nil, err
+	}
+	for _, encoded := range events.Val() {
+		var event session.Event
+		if err := json.Unmarshal([]byte(encoded), &event); err != nil {
+			return nil, fmt.Errorf("decode redis event failed: %w", err)
+		}
+		if !req.After.IsZero() && event.Timestamp.Before(req.After) {
+			continue
+		}
+		sess.events = append(sess.events, &event)
+	}
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// List returns the sessions without their events, like the database service.
+func (s *redisService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	keys := s.keys(req.AppName, req.UserID, "")
+	index := keys.appIndex
+	if req.UserID != "" {
+		index = keys.userIndex
+	}
+	if s.ttl > 0 {
+		expired := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		if err := s.client.ZRemRangeByScore(ctx, index, "-inf", "("+expired).Err(); err != nil {
+			return nil, fmt.Errorf("list redis sessions failed: %w", err)
+		}
+	}
+	members, err := s.client.ZRange(ctx, index, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list redis sessions failed: %w", err)
+	}
+
+	type entry struct {
+		userID, sessionID string
+		meta, state       *redis.MapStringStringCmd
+	}
+	entries := make([]*entry, 0, len(members))
+	users := map[string]*redis.MapStringStringCmd{}
+	var appState *redis.MapStringStringCmd
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		appState = pipe.HGetAll(ctx, keys.appState)
+		for _, member := range members {
+			e := &entry{userID: req.UserID, sessionID: member}
+			if req.UserID == "" {
+				user, id, _ := strings.Cut(member, ":")
+				if e.userID, err = url.QueryUnescape(user); err != nil {
+					return err
+				}
+				e.sessionID = id
+			}
+			if e.sessionID, err = url.QueryUnescape(e.sessionID); err != nil {
+				return err
+			}
+			sessionKeys := s.keys(req.AppName, e.userID, e.sessionID)
+			e.meta = pipe.HGetAll(ctx, sessionKeys.meta)
+			e.state = pipe.HGetAll(ctx, sessionKeys.state)
+			if _, ok := users[e.userID]; !ok {
+				users[e.userID] = pipe.HGetAll(ctx, sessionKeys.userState)
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list redis sessions failed: %w", err)
+	}
+
+	sessions := make([]session.Session, 0, len(entries))
+	for _, e := range entries {
+		if len(e.meta.Val()) == 0 {
+			// expired since the index was cleaned
+			continue
+		}
+		sess, err := newRedisSession(req.AppName, e.userID, e.sessionID, e.meta.Val())
+		if err != nil {
+			return nil, err
+		}
+		if sess.state, err = mergeRedisStates(appState.Val(), users[e.userID].Val(), e.state.Val()); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+func (s *redisService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	appName, userID, sessionID := req.AppName, req.UserID, req.SessionID
+	if appName == "" || userID == "" || sessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", appName, userID, sessionID)
+	}
+	keys := s.keys(appName, userID, sessionID)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys.meta, keys.state, keys.events)
+		pipe.ZRem(ctx, keys.appIndex, keys.indexMember)
+		pipe.ZRem(ctx, keys.userIndex, keys.sessionPart)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete redis session failed: %w", err)
+	}
+	return nil
+}
+
+// AppendEvent appends an event and applies its state delta in a single script, which fails with
+// ErrStaleSession when the session changed since it was read.
+func (s *redisService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	if event.Partial {
+		return nil
+	}
+	sess, ok := curSession.(*redisSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+
+	event = trimTempDeltaState(event)
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event failed: %w", err)
+	}
+	state, err := stateArgs(event.Actions.StateDelta)
+	if err != nil {
+		return err
+	}
+
+	keys := s.keys(sess.appName, sess.userID, sess.id)
+	now := time.Now()
+	args := append([]any{keys.indexMember, keys.sessionPart, event.Timestamp.UnixMicro(), s.ttlMillis(), s.score(now),
+		sess.currentVersion(), string(encoded)}, state...)
+	version, err := appendScript.Run(ctx, s.client, keys.list(), args...).Int64()
+	if err != nil {
+		return fmt.Errorf("append redis event failed: %w", err)
+	}
+	switch version {
+	case -1:
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sess.id)
+	case -2:
+		return fmt.Errorf("%w: %s was updated since it was read", ErrStaleSession, sess.id)
+	}
+	sess.appendEvent(event, version)
+	return nil
+}
+
+// trimTempDeltaState removes the temporary state from the event, which is not persisted.
+func trimTempDeltaState(event *session.Event) *session.Event {
+	if len(event.Actions.StateDelta) == 0 {
+		return event
+	}
+	delta := make(map[string]any, len(event.Actions.StateDelta))
+	for key, value := range event.Actions.StateDelta {
+		if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			delta[key] = value
+		}
+	}
+	event.Actions.StateDelta = delta
+	return event
+}
+
+func mergeRedisStates(appState, userState, sessionState map[string]string) (map[string]any, error) {
+	merged := make(map[string]any, len(appState)+len(userState)+len(sessionState))
+	for _, scope := range []struct {
+		prefix string
+		state  map[string]string
+	}{
+		{"", sessionState},
+		{session.KeyPrefixApp, appState},
+		{session.KeyPrefixUser, userState},
+	} {
+		for key, encoded := range scope.state {
+			var value any
+			if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+				return nil, fmt.Errorf("decode redis state %q failed: %w", key, err)
+			}
+			merged[scope.prefix+key] = value
+		}
+	}
+	return merged, nil
+}
+
+func newRedisSession(appName, userID, sessionID string, meta map[string]string) (*redisSession, error) {
+	version, err := strconv.ParseInt(meta["version"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis session version %q: %w", meta["version"], err)
+	}
+	updated, err := strconv.ParseInt(meta["updated"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis session update time %q: %w", meta["updated"], err)
+	}
+	return &redisSession{
+		appName:   appName,
+		userID:    userID,
+		id:        sessionID,
+		state:     map[string]any{},
+		version:   version,
+		updatedAt: time.UnixMicro(updated),
+	}, nil
+}
+
+var _ session.Service = (*redisService)(nil)
@@ -0,0 +1,197 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"iter"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/configs"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func newRedisBackend(t *testing.T, ttl time.Duration) (session.Service, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	service, err := NewRedisSTMBackend(&RedisBackendConfig{
+		RedisConfig: &configs.RedisConfig{URL: "redis://" + server.Addr()},
+		TTL:         ttl,
+	})
+	require.NoError(t, err)
+	return service, server
+}
+
+func textEvent(author, text string, delta map[string]any) *session.Event {
+	event := session.NewEvent("invocation")
+	event.Author = author
+	event.Content = genai.NewContentFromText(text, genai.RoleUser)
+	maps.Copy(event.Actions.StateDelta, delta)
+	return event
+}
+
+func stateOf(s session.Session) map[string]any {
+	state := map[string]any{}
+	maps.Insert(state, s.State().All())
+	return state
+}
+
+func TestRedisSTMBackend(t *testing.T) {
+	ctx := t.Context()
+	service, _ := newRedisBackend(t, 0)
+
+	created, err := service.Create(ctx, &session.CreateRequest{
+		AppName:   "app",
+		UserID:    "user:1",
+		SessionID: "s1",
+		State:     map[string]any{"topic": "tea", "app:greeting": "hi", "user:city": "Hangzhou", "temp:scratch": 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"topic": "tea", "app:greeting": "hi", "user:city": "Hangzhou"}, stateOf(created.Session))
+
+	_, err = service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user:1", SessionID: "s1"})
+	assert.ErrorIs(t, err, ErrSessionExists)
+
+	require.NoError(t, service.AppendEvent(ctx, created.Session, textEvent("user", "hello", map[string]any{"turns": 1, "temp:draft": "x"})))
+	require.NoError(t, service.AppendEvent(ctx, created.Session, textEvent("model", "hi there", map[string]any{"turns": 2, "user:city": "Berlin"})))
+	require.NoError(t, service.AppendEvent(ctx, created.Session, &session.Event{LLMResponse: textEvent("model", "partial", nil).LLMResponse, Timestamp: time.Now()}))
+	assert.Equal(t, 3, created.Session.Events().Len())
+
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user:1", SessionID: "s1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"topic": "tea", "turns": float64(2), "app:greeting": "hi", "user:city": "Berlin"}, stateOf(got.Session))
+	require.Equal(t, 3, got.Session.Events().Len())
+	assert.Equal(t, "hello", got.Session.Events().At(0).Content.Parts[0].Text)
+	assert.Equal(t, map[string]any{"turns": float64(1)}, got.Session.Events().At(0).Actions.StateDelta)
+	assert.Equal(t, created.Session.LastUpdateTime().UnixMicro(), got.Session.LastUpdateTime().UnixMicro())
+
+	recent, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user:1", SessionID: "s1", NumRecentEvents: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, recent.Session.Events().Len())
+	assert.Equal(t, "partial", recent.Session.Events().At(0).Content.Parts[0].Text)
+
+	_, err = service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "other", SessionID: "s2"})
+	require.NoError(t, err)
+	listed, err := service.List(ctx, &session.ListRequest{AppName: "app", UserID: "user:1"})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 1)
+	assert.Equal(t, "s1", listed.Sessions[0].ID())
+	assert.Equal(t, "Berlin", stateOf(listed.Sessions[0])["user:city"])
+	listed, err = service.List(ctx, &session.ListRequest{AppName: "app"})
+	require.NoError(t, err)
+	var ids []string
+	for _, s := range listed.Sessions {
+		ids = append(ids, s.UserID()+"/"+s.ID())
+	}
+	assert.ElementsMatch(t, []string{"user:1/s1", "other/s2"}, ids)
+
+	require.NoError(t, service.Delete(ctx, &session.DeleteRequest{AppName: "app", UserID: "user:1", SessionID: "s1"}))
+	_, err = service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user:1", SessionID: "s1"})
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	listed, err = service.List(ctx, &session.ListRequest{AppName: "app", UserID: "user:1"})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Sessions)
+}
+
+func TestRedisSTMBackendStaleSession(t *testing.T) {
+	ctx := t.Context()
+	service, _ := newRedisBackend(t, 0)
+	_, err := service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+
+	first, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	second, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.AppendEvent(ctx, first.Session, textEvent("user", "one", map[string]any{"n": 1})))
+	require.NoError(t, service.AppendEvent(ctx, first.Session, textEvent("user", "two", map[string]any{"n": 2})))
+	err = service.AppendEvent(ctx, second.Session, textEvent("user", "conflict", map[string]any{"n": 3}))
+	assert.ErrorIs(t, err, ErrStaleSession)
+
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Session.Events().Len())
+	assert.Equal(t, float64(2), stateOf(got.Session)["n"])
+}
+
+func TestRedisSTMBackendSlidingTTL(t *testing.T) {
+	ctx := t.Context()
+	service, server := newRedisBackend(t, time.Hour)
+	created, err := service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s", State: map[string]any{"user:name": "Ann"}})
+	require.NoError(t, err)
+	require.NoError(t, service.AppendEvent(ctx, created.Session, textEvent("user", "hello", map[string]any{"k": "v"})))
+
+	server.FastForward(50 * time.Minute)
+	_, err = service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err)
+	server.FastForward(50 * time.Minute)
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	require.NoError(t, err, "reading the session renews its TTL")
+	assert.Equal(t, 1, got.Session.Events().Len())
+
+	server.FastForward(2 * time.Hour)
+	_, err = service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	listed, err := service.List(ctx, &session.ListRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Sessions)
+
+	// user state outlives the sessions
+	created, err = service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ann", stateOf(created.Session)["user:name"])
+}
+
+func TestRedisSTMBackendWithRunner(t *testing.T) {
+	ctx := t.Context()
+	service, _ := newRedisBackend(t, time.Hour)
+	counter, err := agent.New(agent.Config{
+		Name: "counter",
+		Run: func(ictx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				count, _ := ictx.Session().State().Get("count")
+				n, _ := count.(float64)
+				event := session.NewEvent(ictx.InvocationID())
+				event.Author = "counter"
+				event.Content = genai.NewContentFromText("counted", genai.RoleModel)
+				event.Actions.StateDelta["count"] = n + 1
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	r, err := runner.New(runner.Config{AppName: "app", Agent: counter, SessionService: service})
+	require.NoError(t, err)
+	created, err := service.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+
+	for range 2 {
+		for _, err := range r.Run(ctx, "user", created.Session.ID(), genai.NewContentFromText("count", genai.RoleUser), agent.RunConfig{}) {
+			require.NoError(t, err)
+		}
+	}
+	got, err := service.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), stateOf(got.Session)["count"])
+	assert.Equal(t, 4, got.Session.Events().Len())
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// redisSession is a session read from Redis. version is the number of events appended to it when it was read,
+// which AppendEvent checks to detect concurrent appends.
+type redisSession struct {
+	appName string
+	userID  string
+	id      string
+
+	// guards all mutable fields
+	mu        sync.RWMutex
+	events    []*session.Event
+	state     map[string]any
+	version   int64
+	updatedAt time.Time
+}
+
+func (s *redisSession) ID() string {
+	return s.id
+}
+
+func (s *redisSession) AppName() string {
+	return s.appName
+}
+
+func (s *redisSession) UserID() string {
+	return s.userID
+}
+
+func (s *redisSession) State() session.State {
+	return &redisState{mu: &s.mu, state: s.state}
+}
+
+func (s *redisSession) Events() session.Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return redisEvents(s.events)
+}
+
+func (s *redisSession) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+func (s *redisSession) currentVersion() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// appendEvent applies an event persisted as version.
+func (s *redisSession) appendEvent(event *session.Event, version int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range event.Actions.StateDelta {
+		if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			s.state[key] = value
+		}
+	}
+	s.events = append(s.events, event)
+	s.version = version
+	s.updatedAt = event.Timestamp
+}
+
+type redisEvents []*session.Event
+
+func (e redisEvents) All() iter.Seq[*session.Event] {
+	return func(yield func(*session.Event) bool) {
+		for _, event := range e {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+func (e redisEvents) Len() int {
+	return len(e)
+}
+
+func (e redisEvents) At(i int) *session.Event {
+	if i >= 0 && i < len(e) {
+		return e[i]
+	}
+	return nil
+}
+
+type redisState struct {
+	mu    *sync.RWMutex
+	state map[string]any
+}
+
+func (s *redisState) Get(key string) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return value, nil
+}
+
+func (s *redisState) Set(key string, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state[key] = value
+	return nil
+}
+
+func (s *redisState) All() iter.Seq2[string, any] {
+	return func(yield func(key string, value any) bool) {
+		s.mu.RLock()
+		for key, value := range s.state {
+			s.mu.RUnlock()
+			if !yield(key, value) {
+				return
+			}
+			s.mu.RLock()
+		}
+		s.mu.RUnlock()
+	}
+}
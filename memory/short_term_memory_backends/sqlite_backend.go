@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"github.com/volcengine/veadk-go/common"
+	"github.com/volcengine/veadk-go/configs"
+	"github.com/volcengine/veadk-go/log"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"gorm.io/gorm"
+)
+
+type SqliteBackendConfig struct {
+	*configs.SqliteConfig
+}
+
+// NewSqliteSTMBackend keeps the sessions in a SQLite file, for single-node deployments and local development.
+// The file and its directory are created when missing, and the file is opened in WAL mode so that reads do not
+// block the writes of running agents.
+func NewSqliteSTMBackend(config *SqliteBackendConfig) (session.Service, error) {
+	if config == nil || config.SqliteConfig == nil {
+		return nil, fmt.Errorf("sqlite config is nil")
+	}
+	if config.Path == "" {
+		config.Path = common.DEFAULT_DATABASE_SQLITE_PATH
+	}
+	if dir := filepath.Dir(config.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create sqlite directory %s failed: %w", dir, err)
+		}
+	}
+
+	sessionService, err := database.NewSessionService(
+		sqlite.Open(config.Path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"),
+		&gorm.Config{PrepareStmt: true, Logger: log.NewGormLogger(slog.LevelError)},
+	)
+	if err != nil {
+		log.Error(fmt.Sprintf("init DatabaseSessionService failed: %v", err))
+		return nil, err
+	}
+	if err := database.AutoMigrate(sessionService); err != nil {
+		return nil, fmt.Errorf("AutoMigrate DatabaseSessionService failed: %w", err)
+	}
+
+	return sessionService, nil
+}
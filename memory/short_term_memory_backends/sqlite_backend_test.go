@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package short_term_memory_backends
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/configs"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestNewSqliteSTMBackend(t *testing.T) {
+	_, err := NewSqliteSTMBackend(nil)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "data", "sessions.db")
+	sessionService, err := NewSqliteSTMBackend(&SqliteBackendConfig{SqliteConfig: &configs.SqliteConfig{Path: path}})
+	require.NoError(t, err)
+
+	created, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName: "app",
+		UserID:  "user",
+		State:   map[string]any{"city": "Hangzhou"},
+	})
+	require.NoError(t, err)
+	event := session.NewEvent("invocation")
+	event.Author = "user"
+	event.Content = genai.NewContentFromText("hello", genai.RoleUser)
+	require.NoError(t, sessionService.AppendEvent(t.Context(), created.Session, event))
+
+	_, err = os.Stat(path + "-wal")
+	assert.NoError(t, err, "sqlite should run in WAL mode")
+
+	// A new service on the same file sees the session, as after a restart.
+	reopened, err := NewSqliteSTMBackend(&SqliteBackendConfig{SqliteConfig: &configs.SqliteConfig{Path: path}})
+	require.NoError(t, err)
+	got, err := reopened.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	city, err := got.Session.State().Get("city")
+	require.NoError(t, err)
+	assert.Equal(t, "Hangzhou", city)
+	require.Equal(t, 1, got.Session.Events().Len())
+	assert.Equal(t, "hello", got.Session.Events().At(0).Content.Parts[0].Text)
+}
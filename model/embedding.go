@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/volcengine/veadk-go/common"
+)
+
+// Embedder turns texts into vectors, one per text and in the same order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, or 0 when their sizes differ or one of them is zero.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+type openAIEmbedder struct {
+	name       string
+	dimensions int
+	config     *ClientConfig
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder calls an OpenAI compatible /embeddings endpoint. An empty modelName and the unset config
+// fields are read from the MODEL_EMBEDDING_* environment variables, then from the defaults.
+func NewOpenAIEmbedder(modelName string, config *ClientConfig) (Embedder, error) {
+	if config == nil {
+		config = &ClientConfig{}
+	}
+	if modelName == "" {
+		modelName = os.Getenv(common.MODEL_EMBEDDING_NAME)
+		if modelName == "" {
+			modelName = common.DEFAULT_MODEL_EMBEDDING_NAME
+		}
+	}
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv(common.MODEL_EMBEDDING_API_KEY)
+		if config.APIKey == "" {
+			config.APIKey = os.Getenv(common.MODEL_AGENT_API_KEY)
+		}
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("openai: embedding API key not found, set MODEL_EMBEDDING_API_KEY environment variable or provide config.APIKey")
+		}
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = os.Getenv(common.MODEL_EMBEDDING_API_BASE)
+		if config.BaseURL == "" {
+			config.BaseURL = common.DEFAULT_MODEL_EMBEDDING_API_BASE
+		}
+	}
+	dimensions, _ := strconv.Atoi(os.Getenv(common.MODEL_EMBEDDING_DIM))
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &openAIEmbedder{
+		name:       modelName,
+		dimensions: dimensions,
+		config:     config,
+		httpClient: httpClient,
+	}, nil
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	EncodingFormat string   `json:"encoding_format"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	reqBody, err := json.Marshal(embeddingRequest{Model: e.name, Input: texts, EncodingFormat: "float", Dimensions: e.dimensions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(e.config.BaseURL, "/")
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/embeddings", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+
+	done := func(error) {}
+	if e.config.CircuitBreaker != nil {
+		done, err = e.config.CircuitBreaker.Allow(ctx, baseURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	httpResp, err := e.httpClient.Do(httpReq)
+	if err != nil {
+		done(err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		done(err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		apiErr := fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, string(body))
+		if isEndpointFailure(httpResp.StatusCode) {
+			done(apiErr)
+		} else {
+			done(nil)
+		}
+		return nil, apiErr
+	}
+	done(nil)
+
+	var resp embeddingResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+type hashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder returns an offline embedder hashing the words of a text into dimensions buckets. It only
+// captures shared words, not meaning, and is meant for tests and offline development.
+func NewHashEmbedder(dimensions int) Embedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &hashEmbedder{dimensions: dimensions}
+}
+
+func (e *hashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.dimensions)
+		for _, word := range hashWords(text) {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(word))
+			sum := h.Sum32()
+			sign := float32(1)
+			if sum&(1<<31) != 0 {
+				sign = -1
+			}
+			vector[int(sum%uint32(e.dimensions))] += sign
+		}
+		var norm float64
+		for _, v := range vector {
+			norm += float64(v) * float64(v)
+		}
+		if norm > 0 {
+			scale := float32(1 / math.Sqrt(norm))
+			for j := range vector {
+				vector[j] *= scale
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// hashWords splits text into lower-cased words, with each CJK character as a word of its own.
+func hashWords(text string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		var req embeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "test-embedding", req.Model)
+		assert.Equal(t, []string{"a", "b"}, req.Input)
+		// answered out of order on purpose
+		_, _ = w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewOpenAIEmbedder("test-embedding", &ClientConfig{APIKey: "test-key", BaseURL: server.URL + "/"})
+	require.NoError(t, err)
+	vectors, err := embedder.Embed(t.Context(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+}
+
+func TestHashEmbedder(t *testing.T) {
+	vectors, err := NewHashEmbedder(64).Embed(t.Context(), []string{"I like green tea", "Green TEA, I like", "我喜欢绿茶", ""})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, vectors[0], vectors[1], 1e-6)
+	assert.NotEqual(t, vectors[0], vectors[2])
+	assert.Len(t, vectors[3], 64)
+	assert.Equal(t, []string{"我", "喜", "欢", "绿", "茶", "and", "tea"}, hashWords("我喜欢绿茶 and TEA!"))
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, CosineSimilarity([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0, CosineSimilarity([]float32{1, 0}, []float32{0, 3}), 1e-9)
+	assert.Equal(t, float64(0), CosineSimilarity([]float32{1}, []float32{1, 2}))
+	assert.Equal(t, float64(0), CosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
@@ -28,6 +28,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/volcengine/veadk-go/circuitbreaker"
 	"github.com/volcengine/veadk-go/common"
 	"google.golang.org/adk/model"
 	"google.golang.org/genai"
@@ -38,6 +39,9 @@ type ClientConfig struct {
 	BaseURL    string
 	ExtraBody  map[string]any
 	HTTPClient *http.Client
+	// CircuitBreaker, when set, guards requests per BaseURL and fails fast with
+	// *circuitbreaker.OpenError while the endpoint is unhealthy.
+	CircuitBreaker *circuitbreaker.Breaker
 }
 
 type openAIModel struct {
@@ -218,12 +222,18 @@ type usage struct {
 	OutputTokens        int                  `json:"output_tokens"` // Ark-compatible field
 	TotalTokens         int                  `json:"total_tokens"`
 	PromptTokensDetails *promptTokensDetails `json:"prompt_tokens_details,omitempty"`
+
+	CompletionTokensDetails *completionTokensDetails `json:"completion_tokens_details,omitempty"`
 }
 
 type promptTokensDetails struct {
 	CachedTokens int `json:"cached_tokens,omitempty"`
 }
 
+type completionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+}
+
 func (m *openAIModel) convertOpenAIRequest(req *model.LLMRequest) (*openAIRequest, error) {
 	openaiReq := &openAIRequest{
 		Model:    m.name,
@@ -681,22 +691,44 @@ func (m *openAIModel) sendRequest(ctx context.Context, openaiReq *openAIRequest)
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+m.config.APIKey)
+
+	done := func(error) {}
+	if m.config.CircuitBreaker != nil {
+		done, err = m.config.CircuitBreaker.Allow(ctx, baseURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	httpResp, err := m.httpClient.Do(httpReq)
 	if err != nil {
+		done(err)
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(httpResp.Body)
+		apiErr := fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, string(body))
+		if isEndpointFailure(httpResp.StatusCode) {
+			done(apiErr)
+		} else {
+			done(nil)
+		}
 		if err = httpResp.Body.Close(); err != nil {
 			return nil, fmt.Errorf("API failed to close response body: %w", err)
 		}
-		return nil, fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, string(body))
+		return nil, apiErr
 	}
 
+	done(nil)
 	return httpResp, nil
 }
 
+// isEndpointFailure reports whether a status code indicates an unhealthy endpoint rather than a bad request.
+func isEndpointFailure(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
 func (m *openAIModel) doRequest(ctx context.Context, openaiReq *openAIRequest) (*response, error) {
 	httpResp, err := m.sendRequest(ctx, openaiReq)
 	if err != nil {
@@ -848,6 +880,9 @@ func buildUsageMetadata(usage *usage) *genai.GenerateContentResponseUsageMetadat
 	if usage.PromptTokensDetails != nil {
 		metadata.CachedContentTokenCount = int32(usage.PromptTokensDetails.CachedTokens)
 	}
+	if usage.CompletionTokensDetails != nil {
+		metadata.ThoughtsTokenCount = int32(usage.CompletionTokensDetails.ReasoningTokens)
+	}
 	return metadata
 }
 
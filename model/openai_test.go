@@ -25,6 +25,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/volcengine/veadk-go/circuitbreaker"
 	"google.golang.org/adk/model"
 	"google.golang.org/genai"
 )
@@ -575,6 +576,43 @@ func TestModel_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestModel_CircuitBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error": {"message": "overloaded"}}`))
+	}))
+	defer server.Close()
+
+	llm, err := NewOpenAIModel(context.Background(), "test-model", &ClientConfig{
+		APIKey:         "test-api-key",
+		BaseURL:        server.URL,
+		HTTPClient:     server.Client(),
+		CircuitBreaker: circuitbreaker.New(circuitbreaker.Config{MinRequests: 2}),
+	})
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	req := &model.LLMRequest{
+		Contents: genai.Text("test"),
+	}
+
+	var lastErr error
+	for i := 0; i < 3; i++ {
+		for _, err := range llm.GenerateContent(t.Context(), req, false) {
+			lastErr = err
+		}
+	}
+	if !circuitbreaker.IsOpen(lastErr) {
+		t.Errorf("expected circuit open error, got %v", lastErr)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls to reach the server, got %d", calls)
+	}
+}
+
 func TestConvertFunctionDeclaration(t *testing.T) {
 	tests := []struct {
 		name string
@@ -2354,6 +2392,23 @@ func TestBuildUsageMetadata(t *testing.T) {
 				CachedContentTokenCount: 30,
 			},
 		},
+		{
+			name: "with_reasoning_tokens",
+			usage: &usage{
+				PromptTokens:     100,
+				CompletionTokens: 50,
+				TotalTokens:      150,
+				CompletionTokensDetails: &completionTokensDetails{
+					ReasoningTokens: 20,
+				},
+			},
+			want: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     100,
+				CandidatesTokenCount: 50,
+				TotalTokenCount:      150,
+				ThoughtsTokenCount:   20,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -124,6 +124,16 @@ func setWorkflowAttributes(span trace.Span) {
 	)
 }
 
+// SetAgentSpanAttributes annotates the invoke_agent span of the agent running in ctx, so that custom agents can
+// record their decisions. Without the observability plugin it falls back to the span carried by ctx.
+func SetAgentSpanAttributes(ctx agent.InvocationContext, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	if agentSpan, ok := GetRegistry().GetAgentScopeSpan(ctx.Session().ID(), ctx.Branch(), ctx.Agent().Name()); ok && agentSpan.IsRecording() {
+		span = agentSpan
+	}
+	span.SetAttributes(attrs...)
+}
+
 // GetCallType retrieves the Call Type from the context or environment variables.
 func GetCallType(ctx context.Context) string {
 	return getContextString(ctx, ContextKeyCallType, EnvCallType)
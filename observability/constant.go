@@ -53,6 +53,9 @@ const (
 	SpanInvokeAgent = "invoke_agent" // Will be suffixed with name in code
 	SpanCallLLM     = "call_llm"
 	SpanExecuteTool = "execute_tool" // Will be suffixed with name in code
+
+	SpanLoopIteration = "loop_iteration" // Will be suffixed with index in code
+	SpanPlanStep      = "plan_step"      // Will be suffixed with index in code
 )
 
 // Metric names
@@ -64,6 +67,7 @@ const (
 	MetricNameFirstTokenLatency           = "gen_ai.chat_completions.streaming_time_to_first_token"
 	MetricNameStreamingTimeToGenerate     = "gen_ai.chat_completions.streaming_time_to_generate"
 	MetricNameStreamingTimePerOutputToken = "gen_ai.chat_completions.streaming_time_per_output_token"
+	MetricNameCost                        = "gen_ai.client.cost"
 
 	// APMPlus specific metrics
 	MetricNameAPMPlusSpanLatency    = "apmplus_span_latency"
@@ -71,6 +75,17 @@ const (
 
 	// AgentKit specific metrics
 	MetricNameAgentKitDuration = "agentkit_runtime_operation_latency"
+
+	// Circuit breaker metrics
+	MetricNameCircuitBreakerStateChange = "veadk.circuit_breaker.state_change"
+	MetricNameCircuitBreakerRejected    = "veadk.circuit_breaker.rejected"
+
+	// Database connection pool metrics
+	MetricNameDBConnectionCount        = "db.client.connection.count"
+	MetricNameDBConnectionMax          = "db.client.connection.max"
+	MetricNameDBConnectionWaits        = "veadk.db.client.connection.waits"
+	MetricNameDBConnectionWaitDuration = "veadk.db.client.connection.wait_duration"
+	MetricNameDBConnectionClosed       = "veadk.db.client.connection.closed"
 )
 
 // General attributes
@@ -92,6 +107,9 @@ const (
 	AttrInvocationID       = "invocation.id" // Alias of gen_ai.invocation.id for CozeLoop platform
 
 	AttrErrorType            = "error.type"
+	AttrDBPoolName           = "db.client.connection.pool.name"
+	AttrDBConnectionState    = "db.client.connection.state"
+	AttrDBCloseReason        = "veadk.db.client.connection.close_reason"
 	AttrCozeloopReportSource = "cozeloop.report.source" // Fixed value: veadk
 	AttrCozeloopCallType     = "cozeloop.call_type"     // CozeLoop call type
 
@@ -137,6 +155,8 @@ const (
 	AttrGenAIUsageTotalTokens              = "gen_ai.usage.total_tokens"
 	AttrGenAIUsageCacheCreationInputTokens = "gen_ai.usage.cache_creation_input_tokens"
 	AttrGenAIUsageCacheReadInputTokens     = "gen_ai.usage.cache_read_input_tokens"
+	AttrGenAIUsageReasoningTokens          = "gen_ai.usage.reasoning_tokens"
+	AttrGenAIClientCost                    = "gen_ai.client.cost"
 	AttrGenAIMessages                      = "gen_ai.messages"
 	AttrGenAIChoice                        = "gen_ai.choice"
 	AttrGenAIResponsePromptTokenCount      = "gen_ai.response.prompt_token_count"
@@ -171,6 +191,32 @@ const (
 	AttrGenAIOutput    = "gen_ai.output"
 )
 
+// Workflow agent attributes
+const (
+	AttrWorkflowType   = "veadk.workflow.type"
+	AttrWorkflowBranch = "veadk.workflow.branch"
+
+	AttrRouterRoute  = "veadk.router.route"
+	AttrRouterReason = "veadk.router.reason"
+
+	AttrLoopIteration  = "veadk.loop.iteration"
+	AttrLoopExitReason = "veadk.loop.exit_reason"
+
+	AttrPlanStep    = "veadk.plan.step"
+	AttrPlanReplans = "veadk.plan.replans"
+	AttrPlanStatus  = "veadk.plan.status"
+)
+
+// Workflow types, values of AttrWorkflowType
+const (
+	WorkflowTypeSequential = "sequential"
+	WorkflowTypeParallel   = "parallel"
+	WorkflowTypeLoop       = "loop"
+	WorkflowTypeRouter     = "router"
+	WorkflowTypeGraph      = "graph"
+	WorkflowTypePlan       = "plan"
+)
+
 // Context keys for storing runtime values
 type contextKey string
 
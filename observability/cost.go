@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/volcengine/veadk-go/configs"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+// State keys holding the accumulated cost of LLM calls.
+const (
+	// StateKeyInvocationCost is reset at the start of every invocation.
+	StateKeyInvocationCost = session.KeyPrefixTemp + "veadk.cost.invocation"
+	// StateKeySessionCost is persisted with the session.
+	StateKeySessionCost = "veadk.cost.session"
+	// StateKeyUserCost is shared by all sessions of the same user and app.
+	StateKeyUserCost = session.KeyPrefixUser + "veadk.cost.total"
+)
+
+const tokensPerPricingUnit = 1_000_000
+
+// WithModelPricing registers or overrides the pricing of a model.
+func WithModelPricing(modelName string, pricing configs.ModelPricing) Option {
+	return func(cfg *configs.ObservabilityConfig) {
+		if cfg.Pricing == nil {
+			cfg.Pricing = make(map[string]*configs.ModelPricing)
+		}
+		cfg.Pricing[strings.ToLower(modelName)] = &pricing
+	}
+}
+
+// LookupModelPricing finds the pricing of a model by case-insensitive name. When there is no exact
+// match, the longest configured name that prefixes modelName is used, so that "doubao-seed-1-6"
+// also prices "doubao-seed-1-6-250615".
+func LookupModelPricing(pricing map[string]*configs.ModelPricing, modelName string) *configs.ModelPricing {
+	if len(pricing) == 0 || modelName == "" {
+		return nil
+	}
+	modelName = strings.ToLower(modelName)
+	if p, ok := pricing[modelName]; ok {
+		return p
+	}
+	var (
+		best    *configs.ModelPricing
+		bestLen int
+	)
+	for name, p := range pricing {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(modelName, name) && len(name) > bestLen {
+			best, bestLen = p, len(name)
+		}
+	}
+	return best
+}
+
+// CalculateCost returns the cost of a single LLM call. Cached tokens are part of the prompt tokens and
+// reasoning tokens are part of the candidate tokens, as reported by OpenAI-compatible APIs.
+func CalculateCost(pricing *configs.ModelPricing, usage *genai.GenerateContentResponseUsageMetadata) float64 {
+	if pricing == nil || usage == nil {
+		return 0
+	}
+
+	cachedPrice := pricing.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = pricing.Input
+	}
+	reasoningPrice := pricing.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = pricing.Output
+	}
+
+	cached := min(int64(usage.CachedContentTokenCount), int64(usage.PromptTokenCount))
+	reasoning := min(int64(usage.ThoughtsTokenCount), int64(usage.CandidatesTokenCount))
+	input := int64(usage.PromptTokenCount) - cached
+	output := int64(usage.CandidatesTokenCount) - reasoning
+
+	total := float64(input)*pricing.Input +
+		float64(cached)*cachedPrice +
+		float64(output)*pricing.Output +
+		float64(reasoning)*reasoningPrice
+	return total / tokensPerPricingUnit
+}
+
+// GetCost reads an accumulated cost from state, tolerating the numeric types produced by persistent backends.
+func GetCost(state session.ReadonlyState, key string) float64 {
+	val, err := state.Get(key)
+	if err != nil || val == nil {
+		return 0
+	}
+	switch v := val.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
+
+// AddInvocationUsage adds usage consumed outside the model calls of the current invocation, such as
+// by an agent called as a tool in its own runner, to the invocation totals and the costs in state.
+func AddInvocationUsage(ctx agent.CallbackContext, usage *genai.GenerateContentResponseUsageMetadata, cost float64) {
+	state := ctx.State()
+	if val, _ := state.Get(stateKeyMetadata); val != nil && usage != nil {
+		if meta, ok := val.(*spanMetadata); ok {
+			prompt := int64(usage.PromptTokenCount)
+			candidate := int64(usage.CandidatesTokenCount)
+			total := int64(usage.TotalTokenCount)
+			if total == 0 {
+				total = prompt + candidate
+			}
+			// Move the token baselines too, they are the totals before the current model call.
+			meta.PromptTokens += prompt
+			meta.PrevPromptTokens += prompt
+			meta.CandidateTokens += candidate
+			meta.PrevCandidateTokens += candidate
+			meta.TotalTokens += total
+			meta.PrevTotalTokens += total
+			meta.Cost += cost
+			_ = state.Set(stateKeyMetadata, meta)
+		}
+	}
+	if cost <= 0 {
+		return
+	}
+	_ = state.Set(StateKeyInvocationCost, GetCost(state, StateKeyInvocationCost)+cost)
+	_ = state.Set(StateKeySessionCost, GetCost(state, StateKeySessionCost)+cost)
+	_ = state.Set(StateKeyUserCost, GetCost(state, StateKeyUserCost)+cost)
+}
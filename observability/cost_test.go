@@ -0,0 +1,193 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/configs"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func TestCalculateCost(t *testing.T) {
+	pricing := &configs.ModelPricing{Input: 1, Output: 4, CachedInput: 0.5, Reasoning: 8}
+
+	tests := []struct {
+		name    string
+		pricing *configs.ModelPricing
+		usage   *genai.GenerateContentResponseUsageMetadata
+		want    float64
+	}{
+		{
+			name:    "nil pricing",
+			pricing: nil,
+			usage:   &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 100},
+			want:    0,
+		},
+		{
+			name:    "input and output",
+			pricing: pricing,
+			usage:   &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 1_000_000, CandidatesTokenCount: 500_000},
+			want:    3,
+		},
+		{
+			name:    "cached and reasoning",
+			pricing: pricing,
+			usage: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:        1_000_000,
+				CachedContentTokenCount: 400_000,
+				CandidatesTokenCount:    500_000,
+				ThoughtsTokenCount:      250_000,
+			},
+			want: 0.6 + 0.2 + 1 + 2,
+		},
+		{
+			name:    "fallback prices",
+			pricing: &configs.ModelPricing{Input: 1, Output: 2},
+			usage: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:        1_000_000,
+				CachedContentTokenCount: 1_000_000,
+				CandidatesTokenCount:    1_000_000,
+				ThoughtsTokenCount:      1_000_000,
+			},
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, CalculateCost(tt.pricing, tt.usage), 1e-9)
+		})
+	}
+}
+
+func TestLookupModelPricing(t *testing.T) {
+	table := map[string]*configs.ModelPricing{
+		"doubao-seed-1-6":       {Input: 1},
+		"doubao-seed-1-6-flash": {Input: 2},
+	}
+
+	assert.Equal(t, 1.0, LookupModelPricing(table, "doubao-seed-1-6").Input)
+	assert.Equal(t, 1.0, LookupModelPricing(table, "Doubao-Seed-1-6-250615").Input)
+	assert.Equal(t, 2.0, LookupModelPricing(table, "doubao-seed-1-6-flash-250715").Input)
+	assert.Nil(t, LookupModelPricing(table, "deepseek-v3"))
+	assert.Nil(t, LookupModelPricing(nil, "doubao-seed-1-6"))
+}
+
+func TestRecordCost(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	initializeInstruments(mp.Meter("test-meter"))
+
+	ctx := context.Background()
+	RecordCost(ctx, 0.25)
+	RecordCost(ctx, 0.5)
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(ctx, &rm))
+
+	var found bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == MetricNameCost {
+				data := m.Data.(metricdata.Sum[float64])
+				assert.InDelta(t, 0.75, data.DataPoints[0].Value, 1e-9)
+				found = true
+			}
+		}
+	}
+	assert.True(t, found, "cost metric not found")
+}
+
+// streamingLLM streams an answer with cumulative usage, and repeats its final chunk.
+type streamingLLM struct{}
+
+func (streamingLLM) Name() string { return "streaming" }
+
+func (streamingLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		chunk := func(text string, candidates int32, partial bool) *model.LLMResponse {
+			return &model.LLMResponse{
+				Content:       genai.NewContentFromText(text, genai.RoleModel),
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: candidates},
+				Partial:       partial,
+				TurnComplete:  !partial,
+			}
+		}
+		for _, resp := range []*model.LLMResponse{
+			chunk("Hel", 2, true),
+			chunk("lo", 5, true),
+			chunk("Hello", 8, false),
+			chunk("Hello", 8, false),
+		} {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestStreamedCallCost(t *testing.T) {
+	p := &adkObservabilityPlugin{
+		config: &configs.ObservabilityConfig{Pricing: map[string]*configs.ModelPricing{
+			"streaming": {Input: 1_000_000, Output: 1_000_000},
+		}},
+		tracer: otel.Tracer(InstrumentationName),
+	}
+	observabilityPlugin, err := plugin.New(plugin.Config{
+		Name:                PluginName,
+		BeforeRunCallback:   p.BeforeRun,
+		AfterRunCallback:    p.AfterRun,
+		BeforeModelCallback: p.BeforeModel,
+		AfterModelCallback:  p.AfterModel,
+	})
+	require.NoError(t, err)
+	assistant, err := llmagent.New(llmagent.Config{Name: "assistant", Model: streamingLLM{}})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "app",
+		Agent:          assistant,
+		SessionService: sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{observabilityPlugin}},
+	})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	require.NoError(t, err)
+	for range 2 {
+		for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("hi", genai.RoleUser), agent.RunConfig{StreamingMode: agent.StreamingModeSSE}) {
+			require.NoError(t, err)
+		}
+	}
+
+	got, err := sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	// each call costs its last usage, 10 prompt and 8 candidate tokens, once
+	assert.InDelta(t, 36, GetCost(got.Session.State(), StateKeySessionCost), 1e-9)
+	assert.InDelta(t, 36, GetCost(got.Session.State(), StateKeyUserCost), 1e-9)
+}
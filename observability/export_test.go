@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"github.com/volcengine/veadk-go/configs"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/adk/plugin"
+)
+
+// NewTracingPlugin returns the tracing callbacks of the plugin, on the global tracer provider and without Init,
+// for the tests of the observability_test package.
+func NewTracingPlugin() (*plugin.Plugin, error) {
+	p := &adkObservabilityPlugin{config: &configs.ObservabilityConfig{}, tracer: otel.Tracer(InstrumentationName)}
+	return plugin.New(plugin.Config{
+		Name:                PluginName,
+		BeforeRunCallback:   p.BeforeRun,
+		AfterRunCallback:    p.AfterRun,
+		BeforeAgentCallback: p.BeforeAgent,
+		AfterAgentCallback:  p.AfterAgent,
+	})
+}
+
+// HasAgentScopes reports whether the registry still holds open agent scopes of the session.
+func HasAgentScopes(sessionID string) bool {
+	_, ok := GetRegistry().agentScopes.Load(sessionID)
+	return ok
+}
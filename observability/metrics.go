@@ -16,6 +16,7 @@ package observability
 
 import (
 	"context"
+	"database/sql"
 	"sync"
 
 	"go.opentelemetry.io/otel"
@@ -65,6 +66,7 @@ var (
 	operationDurationHistograms []metric.Float64Histogram
 	chatCountCounters           []metric.Int64Counter
 	exceptionsCounters          []metric.Int64Counter
+	costCounters                []metric.Float64Counter
 	// streaming metrics
 	streamingTimeToFirstTokenHistograms   []metric.Float64Histogram
 	streamingTimeToGenerateHistograms     []metric.Float64Histogram
@@ -76,8 +78,21 @@ var (
 
 	// special metrics for AgentKit
 	agentkitDurationHistograms []metric.Float64Histogram
+
+	// circuit breaker metrics
+	circuitBreakerStateChangeCounters []metric.Int64Counter
+	circuitBreakerRejectedCounters    []metric.Int64Counter
+
+	// database connection pools observed by the pool metrics
+	dbPoolsMu sync.RWMutex
+	dbPools   []*dbPool
 )
 
+type dbPool struct {
+	name  string
+	stats func() sql.DBStats
+}
+
 // registerLocalMetrics initializes the metrics system with a local isolated MeterProvider.
 // It does NOT overwrite the global OTel MeterProvider.
 func registerLocalMetrics(readers []sdkmetric.Reader) {
@@ -163,6 +178,15 @@ func initializeInstruments(m metric.Meter) {
 		exceptionsCounters = append(exceptionsCounters, c)
 	}
 
+	// Cost counter
+	if c, err := m.Float64Counter(
+		MetricNameCost,
+		metric.WithDescription("Cost of LLM invocations computed from token usage and model pricing"),
+		metric.WithUnit("1"),
+	); err == nil {
+		costCounters = append(costCounters, c)
+	}
+
 	// Streaming time to generate histogram
 	if h, err := m.Float64Histogram(
 		MetricNameStreamingTimeToGenerate,
@@ -217,6 +241,107 @@ func initializeInstruments(m metric.Meter) {
 	); err == nil {
 		agentkitDurationHistograms = append(agentkitDurationHistograms, h)
 	}
+
+	// Circuit breaker state transitions
+	if c, err := m.Int64Counter(
+		MetricNameCircuitBreakerStateChange,
+		metric.WithDescription("Number of circuit breaker state transitions"),
+		metric.WithUnit("1"),
+	); err == nil {
+		circuitBreakerStateChangeCounters = append(circuitBreakerStateChangeCounters, c)
+	}
+
+	// Circuit breaker fail-fast rejections
+	if c, err := m.Int64Counter(
+		MetricNameCircuitBreakerRejected,
+		metric.WithDescription("Number of calls rejected by an open circuit breaker"),
+		metric.WithUnit("1"),
+	); err == nil {
+		circuitBreakerRejectedCounters = append(circuitBreakerRejectedCounters, c)
+	}
+
+	initializeDBPoolInstruments(m)
+}
+
+// initializeDBPoolInstruments observes the pools registered by RegisterDBPool.
+func initializeDBPoolInstruments(m metric.Meter) {
+	count, err := m.Int64ObservableUpDownCounter(
+		MetricNameDBConnectionCount,
+		metric.WithDescription("Number of connections in the pool, by state"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return
+	}
+	maxOpen, err := m.Int64ObservableUpDownCounter(
+		MetricNameDBConnectionMax,
+		metric.WithDescription("Maximum number of open connections allowed, 0 for unlimited"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return
+	}
+	waits, err := m.Int64ObservableCounter(
+		MetricNameDBConnectionWaits,
+		metric.WithDescription("Number of connection requests that waited for a free connection"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return
+	}
+	waitDuration, err := m.Float64ObservableCounter(
+		MetricNameDBConnectionWaitDuration,
+		metric.WithDescription("Total time spent waiting for a free connection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return
+	}
+	closed, err := m.Int64ObservableCounter(
+		MetricNameDBConnectionClosed,
+		metric.WithDescription("Number of connections closed by the pool limits, by reason"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return
+	}
+
+	_, _ = m.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		dbPoolsMu.RLock()
+		defer dbPoolsMu.RUnlock()
+		for _, pool := range dbPools {
+			stats := pool.stats()
+			name := attribute.String(AttrDBPoolName, pool.name)
+			o.ObserveInt64(count, int64(stats.InUse), metric.WithAttributes(name, attribute.String(AttrDBConnectionState, "used")))
+			o.ObserveInt64(count, int64(stats.Idle), metric.WithAttributes(name, attribute.String(AttrDBConnectionState, "idle")))
+			o.ObserveInt64(maxOpen, int64(stats.MaxOpenConnections), metric.WithAttributes(name))
+			o.ObserveInt64(waits, stats.WaitCount, metric.WithAttributes(name))
+			o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds(), metric.WithAttributes(name))
+			o.ObserveInt64(closed, stats.MaxIdleClosed, metric.WithAttributes(name, attribute.String(AttrDBCloseReason, "max_idle")))
+			o.ObserveInt64(closed, stats.MaxIdleTimeClosed, metric.WithAttributes(name, attribute.String(AttrDBCloseReason, "max_idle_time")))
+			o.ObserveInt64(closed, stats.MaxLifetimeClosed, metric.WithAttributes(name, attribute.String(AttrDBCloseReason, "max_lifetime")))
+		}
+		return nil
+	}, count, maxOpen, waits, waitDuration, closed)
+}
+
+// RegisterDBPool exports the statistics of a connection pool, usually (*sql.DB).Stats, as metrics attributed to
+// name, until the returned function is called.
+func RegisterDBPool(name string, stats func() sql.DBStats) (unregister func()) {
+	pool := &dbPool{name: name, stats: stats}
+	dbPoolsMu.Lock()
+	dbPools = append(dbPools, pool)
+	dbPoolsMu.Unlock()
+	return func() {
+		dbPoolsMu.Lock()
+		defer dbPoolsMu.Unlock()
+		for i, p := range dbPools {
+			if p == pool {
+				dbPools = append(dbPools[:i], dbPools[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 // RecordTokenUsage records the number of tokens used.
@@ -261,6 +386,13 @@ func RecordExceptions(ctx context.Context, count int64, attrs ...attribute.KeyVa
 	}
 }
 
+// RecordCost records the cost of an LLM invocation.
+func RecordCost(ctx context.Context, cost float64, attrs ...attribute.KeyValue) {
+	for _, counter := range costCounters {
+		counter.Add(ctx, cost, metric.WithAttributes(attrs...))
+	}
+}
+
 // RecordStreamingTimeToGenerate records the time to generate.
 func RecordStreamingTimeToGenerate(ctx context.Context, durationSeconds float64, attrs ...attribute.KeyValue) {
 	for _, histogram := range streamingTimeToGenerateHistograms {
@@ -294,3 +426,27 @@ func RecordAgentKitDuration(ctx context.Context, durationSeconds float64, attrs
 		histogram.Record(ctx, durationSeconds, metric.WithAttributes(attrs...))
 	}
 }
+
+// RecordCircuitBreakerStateChange records a circuit breaker transition between two states.
+func RecordCircuitBreakerStateChange(ctx context.Context, name, key, from, to string, attrs ...attribute.KeyValue) {
+	attrs = append(attrs,
+		attribute.String("circuit_breaker_name", name),
+		attribute.String("circuit_breaker_key", key),
+		attribute.String("circuit_breaker_from_state", from),
+		attribute.String("circuit_breaker_to_state", to),
+	)
+	for _, counter := range circuitBreakerStateChangeCounters {
+		counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
+
+// RecordCircuitBreakerRejected records a call that was rejected because the circuit was open.
+func RecordCircuitBreakerRejected(ctx context.Context, name, key string, attrs ...attribute.KeyValue) {
+	attrs = append(attrs,
+		attribute.String("circuit_breaker_name", name),
+		attribute.String("circuit_breaker_key", key),
+	)
+	for _, counter := range circuitBreakerRejectedCounters {
+		counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+}
@@ -16,7 +16,9 @@ package observability
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel/attribute"
@@ -144,6 +146,51 @@ func TestMetricsRecording(t *testing.T) {
 	})
 }
 
+func TestDBPoolMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	initializeDBPoolInstruments(mp.Meter("test-meter"))
+
+	unregister := RegisterDBPool("sessions", func() sql.DBStats {
+		return sql.DBStats{MaxOpenConnections: 20, InUse: 3, Idle: 2, WaitCount: 7, WaitDuration: 1500 * time.Millisecond, MaxLifetimeClosed: 4}
+	})
+	collect := func() map[string]map[string]float64 {
+		var rm metricdata.ResourceMetrics
+		assert.NoError(t, reader.Collect(context.Background(), &rm))
+		points := map[string]map[string]float64{}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				points[m.Name] = map[string]float64{}
+				switch data := m.Data.(type) {
+				case metricdata.Sum[int64]:
+					for _, dp := range data.DataPoints {
+						pool, _ := dp.Attributes.Value(AttrDBPoolName)
+						assert.Equal(t, "sessions", pool.AsString())
+						state, _ := dp.Attributes.Value(AttrDBConnectionState)
+						reason, _ := dp.Attributes.Value(AttrDBCloseReason)
+						points[m.Name][state.AsString()+reason.AsString()] = float64(dp.Value)
+					}
+				case metricdata.Sum[float64]:
+					for _, dp := range data.DataPoints {
+						points[m.Name][""] = dp.Value
+					}
+				}
+			}
+		}
+		return points
+	}
+
+	points := collect()
+	assert.Equal(t, map[string]float64{"used": 3, "idle": 2}, points[MetricNameDBConnectionCount])
+	assert.Equal(t, map[string]float64{"": 20}, points[MetricNameDBConnectionMax])
+	assert.Equal(t, map[string]float64{"": 7}, points[MetricNameDBConnectionWaits])
+	assert.Equal(t, map[string]float64{"": 1.5}, points[MetricNameDBConnectionWaitDuration])
+	assert.Equal(t, map[string]float64{"max_idle": 0, "max_idle_time": 0, "max_lifetime": 4}, points[MetricNameDBConnectionClosed])
+
+	unregister()
+	assert.Empty(t, collect()[MetricNameDBConnectionCount])
+}
+
 func TestRegisterLocalMetrics(t *testing.T) {
 	// Since registerLocalMetrics uses sync.Once, we can only test it doesn't panic.
 	// Logic verification is implicitly done via InitializeInstruments testing above.
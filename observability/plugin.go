@@ -132,6 +132,7 @@ func (p *adkObservabilityPlugin) BeforeRun(ctx agent.InvocationContext) (*genai.
 		StartTime: time.Now(),
 	}
 	p.storeSpanMetadata(ctx.Session().State(), meta)
+	_ = ctx.Session().State().Set(StateKeyInvocationCost, 0.0)
 
 	// Capture input from UserContent
 	if userContent := ctx.UserContent(); userContent != nil {
@@ -178,6 +179,9 @@ func (p *adkObservabilityPlugin) AfterRun(ctx agent.InvocationContext) {
 			if meta.TotalTokens > 0 {
 				span.SetAttributes(attribute.Int64(AttrGenAIUsageTotalTokens, meta.TotalTokens))
 			}
+			if meta.Cost > 0 {
+				span.SetAttributes(attribute.Float64(AttrGenAIClientCost, meta.Cost))
+			}
 
 			// Record final metrics for invocation
 			if !meta.StartTime.IsZero() {
@@ -224,6 +228,9 @@ func (p *adkObservabilityPlugin) AfterRun(ctx agent.InvocationContext) {
 			span.End()
 		}
 	}
+
+	// End the agent and workflow step spans left open by runs that were cut short.
+	GetRegistry().EndAgentScopes(ctx.Session().ID())
 }
 
 // BeforeModel is called before the LLM is called.
@@ -232,9 +239,9 @@ func (p *adkObservabilityPlugin) BeforeModel(ctx agent.CallbackContext, req *mod
 		"InvocationID", ctx.InvocationID(), "SessionID", ctx.SessionID(), "UserID", ctx.UserID(), "AgentName", ctx.AgentName(), "AppName", ctx.AppName())
 	parentCtx := context.Context(ctx)
 
-	if actx, _ := ctx.State().Get(stateKeyInvokeAgentCtx); actx != nil {
-		parentCtx = actx.(context.Context)
-		log.Debug("BeforeModel get a parent invoke_agent ctx from state", "parentCtx", parentCtx)
+	if actx, ok := GetRegistry().GetAgentScopeContext(ctx.SessionID(), ctx.Branch()); ok {
+		parentCtx = actx
+		log.Debug("BeforeModel get a parent invoke_agent ctx from registry", "parentCtx", parentCtx)
 	} else if ictx, _ := ctx.State().Get(stateKeyInvocationCtx); ictx != nil {
 		parentCtx = ictx.(context.Context)
 		log.Debug("BeforeModel get a parent invocation ctx from state", "parentCtx", parentCtx)
@@ -245,7 +252,7 @@ func (p *adkObservabilityPlugin) BeforeModel(ctx agent.CallbackContext, req *mod
 	// Align with Python: name is "call_llm"
 	_, span := p.tracer.Start(parentCtx, SpanCallLLM)
 	log.Debug("BeforeModel created a span", "span", span.SpanContext(), "is_recording", span.IsRecording())
-	_ = ctx.State().Set(stateKeyStreamingSpan, span)
+	_ = ctx.State().Set(streamingSpanKey(ctx), span)
 
 	adkSpan := trace.SpanFromContext(context.Context(ctx))
 	if adkSpan.SpanContext().IsValid() { // Register google's ADK span (currently not implemented) -> our veadk span context.
@@ -258,6 +265,8 @@ func (p *adkObservabilityPlugin) BeforeModel(ctx agent.CallbackContext, req *mod
 	meta.PrevPromptTokens = meta.PromptTokens
 	meta.PrevCandidateTokens = meta.CandidateTokens
 	meta.PrevTotalTokens = meta.TotalTokens
+	meta.CallCost = 0
+	meta.ChargedCallCost = 0
 	meta.ModelName = req.Model
 	p.storeSpanMetadata(ctx.State(), meta)
 
@@ -348,7 +357,7 @@ func (p *adkObservabilityPlugin) AfterModel(ctx agent.CallbackContext, resp *mod
 	log.Debug("AfterModel",
 		"InvocationID", ctx.InvocationID(), "SessionID", ctx.SessionID(), "UserID", ctx.UserID(), "AgentName", ctx.AgentName(), "AppName", ctx.AppName())
 	// 1. Get our managed span from state
-	s, _ := ctx.State().Get(stateKeyStreamingSpan)
+	s, _ := ctx.State().Get(streamingSpanKey(ctx))
 	if s == nil {
 		log.Warn("AfterModel: No streaming span found in state")
 		return nil, nil
@@ -475,6 +484,8 @@ func (p *adkObservabilityPlugin) AfterModel(ctx agent.CallbackContext, resp *mod
 	if !resp.Partial {
 		// Record Operation Duration and Latency
 		p.recordFinalResponseMetrics(ctx, meta, finalModelName)
+		// Accumulate the cost of this call
+		p.accumulateCost(ctx, meta, finalModelName)
 	}
 
 	return nil, nil
@@ -621,6 +632,11 @@ func (p *adkObservabilityPlugin) handleUsage(ctx agent.CallbackContext, span tra
 	meta.CandidateTokens = meta.PrevCandidateTokens + currentCandidate
 	meta.TotalTokens = meta.PrevTotalTokens + currentTotal
 
+	pricing := LookupModelPricing(p.config.Pricing, modelName)
+	currentCost := CalculateCost(pricing, resp.UsageMetadata)
+	// like the tokens, the usage of a streamed call is cumulative
+	meta.CallCost = currentCost
+
 	// 3. Update session-wide totals
 	p.storeSpanMetadata(ctx.State(), meta)
 
@@ -644,6 +660,12 @@ func (p *adkObservabilityPlugin) handleUsage(ctx agent.CallbackContext, span tra
 		}
 		// Always set cache creation to 0 if not provided, for parity with python
 		attrs = append(attrs, attribute.Int64(AttrGenAIUsageCacheCreationInputTokens, 0))
+		if resp.UsageMetadata.ThoughtsTokenCount > 0 {
+			attrs = append(attrs, attribute.Int64(AttrGenAIUsageReasoningTokens, int64(resp.UsageMetadata.ThoughtsTokenCount)))
+		}
+	}
+	if pricing != nil {
+		attrs = append(attrs, attribute.Float64(AttrGenAIClientCost, currentCost))
 	}
 
 	span.SetAttributes(attrs...)
@@ -665,6 +687,33 @@ func (p *adkObservabilityPlugin) handleUsage(ctx agent.CallbackContext, span tra
 	}
 }
 
+// accumulateCost adds the cost of the finished LLM call to the invocation, session and user totals in state.
+// Only the part of the call cost not charged yet is added, so that a repeated final chunk is not counted twice.
+func (p *adkObservabilityPlugin) accumulateCost(ctx agent.CallbackContext, meta *spanMetadata, modelName string) {
+	callCost := meta.CallCost - meta.ChargedCallCost
+	if callCost <= 0 {
+		return
+	}
+	meta.ChargedCallCost = meta.CallCost
+	meta.Cost += callCost
+	p.storeSpanMetadata(ctx.State(), meta)
+
+	state := ctx.State()
+	_ = state.Set(StateKeyInvocationCost, meta.Cost)
+	_ = state.Set(StateKeySessionCost, GetCost(state, StateKeySessionCost)+callCost)
+	_ = state.Set(StateKeyUserCost, GetCost(state, StateKeyUserCost)+callCost)
+
+	if p.isMetricsEnabled() {
+		metricAttrs := []attribute.KeyValue{
+			attribute.String(AttrGenAISystem, GetModelProvider(ctx)),
+			attribute.String("gen_ai_response_model", modelName),
+			attribute.String("gen_ai_operation_name", "chat"),
+			attribute.String("gen_ai_operation_type", "llm"),
+		}
+		RecordCost(context.Context(ctx), callCost, metricAttrs...)
+	}
+}
+
 func (p *adkObservabilityPlugin) addMessageEvents(span trace.Span, ctx agent.CallbackContext, req *model.LLMRequest) {
 	// 1. System Message
 	if req.Config != nil && req.Config.SystemInstruction != nil {
@@ -979,9 +1028,12 @@ func (p *adkObservabilityPlugin) BeforeAgent(ctx agent.CallbackContext) (*genai.
 		agentName = FallbackAgentName
 	}
 
-	// 1. Get the parent context from state to maintain hierarchy
+	// 1. Get the parent context to maintain hierarchy: the enclosing agent or workflow step on this
+	// branch, else the invocation.
 	parentCtx := context.Context(ctx)
-	if ictx, _ := ctx.State().Get(stateKeyInvocationCtx); ictx != nil {
+	if actx, ok := GetRegistry().GetAgentScopeContext(ctx.SessionID(), ctx.Branch()); ok {
+		parentCtx = actx
+	} else if ictx, _ := ctx.State().Get(stateKeyInvocationCtx); ictx != nil {
 		parentCtx = ictx.(context.Context)
 	}
 
@@ -997,14 +1049,16 @@ func (p *adkObservabilityPlugin) BeforeAgent(ctx agent.CallbackContext) (*genai.
 		GetRegistry().RegisterAgentMapping(adkSpan.SpanContext().SpanID(), adkSpan.SpanContext().TraceID(), span.SpanContext())
 	}
 
-	// 3. Store in state for AfterAgent and children
-	_ = ctx.State().Set(stateKeyInvokeAgentSpan, span)
-	_ = ctx.State().Set(stateKeyInvokeAgentCtx, newCtx)
+	// 3. Register as the parent of the agents and LLM calls started below it, until AfterAgent.
+	GetRegistry().PushAgentScope(ctx.SessionID(), ctx.Branch(), ctx.AgentName(), newCtx, span)
 
 	// 4. Set attributes
 	setCommonAttributesFromCallback(ctx, span)
 	setWorkflowAttributes(span)
 	setAgentAttributes(span, agentName)
+	if ctx.Branch() != "" {
+		span.SetAttributes(attribute.String(AttrWorkflowBranch, ctx.Branch()))
+	}
 
 	// Capture input if available (propagated from BeforeRun via state or context?)
 	// Note: BeforeRun captures UserContent, but for nested agents, input might be passed differently.
@@ -1024,8 +1078,7 @@ func (p *adkObservabilityPlugin) AfterAgent(ctx agent.CallbackContext) (*genai.C
 	log.Debug("AfterAgent",
 		"InvocationID", ctx.InvocationID(), "SessionID", ctx.SessionID(), "UserID", ctx.UserID(), "AgentName", ctx.AgentName(), "AppName", ctx.AppName())
 	// 1. End the span
-	if s, _ := ctx.State().Get(stateKeyInvokeAgentSpan); s != nil {
-		span := s.(trace.Span)
+	if span, ok := GetRegistry().PopAgentScope(ctx.SessionID(), ctx.Branch(), ctx.AgentName()); ok {
 		if span.IsRecording() {
 			// Try to capture output if available in state (propagated from AfterRun or internal execution)
 			if cached, _ := ctx.State().Get(stateKeyStreamingOutput); cached != nil {
@@ -1040,6 +1093,14 @@ func (p *adkObservabilityPlugin) AfterAgent(ctx agent.CallbackContext) (*genai.C
 	return nil, nil
 }
 
+// streamingSpanKey keeps the LLM spans of concurrent branches apart.
+func streamingSpanKey(ctx agent.CallbackContext) string {
+	if ctx.Branch() == "" {
+		return stateKeyStreamingSpan
+	}
+	return stateKeyStreamingSpan + "." + ctx.Branch()
+}
+
 func (p *adkObservabilityPlugin) getSpanMetadata(state session.State) *spanMetadata {
 	val, _ := state.Get(stateKeyMetadata)
 	if meta, ok := val.(*spanMetadata); ok {
@@ -1075,11 +1136,13 @@ func safeMarshal(v any) string {
 	return string(b)
 }
 
+// StateKeyPrefix prefixes the state the plugin keeps for itself, such as live spans, which must
+// not be copied to other sessions.
+const StateKeyPrefix = "veadk.observability."
+
 const (
-	stateKeyInvocationSpan  = "veadk.observability.invocation_span"
-	stateKeyInvocationCtx   = "veadk.observability.invocation_ctx"
-	stateKeyInvokeAgentCtx  = "veadk.observability.invoke_agent_ctx"
-	stateKeyInvokeAgentSpan = "veadk.observability.invoke_agent_span"
+	stateKeyInvocationSpan = "veadk.observability.invocation_span"
+	stateKeyInvocationCtx  = "veadk.observability.invocation_ctx"
 
 	stateKeyMetadata        = "veadk.observability.metadata"
 	stateKeyStreamingOutput = "veadk.observability.streaming_output"
@@ -1097,5 +1160,10 @@ type spanMetadata struct {
 	PrevPromptTokens    int64
 	PrevCandidateTokens int64
 	PrevTotalTokens     int64
-	ModelName           string
+	// Cost is the cost of the invocation charged so far.
+	Cost float64
+	// CallCost is the cost of the current LLM call from its latest usage, of which ChargedCallCost is in Cost.
+	CallCost        float64
+	ChargedCallCost float64
+	ModelName       string
 }
@@ -15,6 +15,8 @@
 package observability
 
 import (
+	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +46,23 @@ type TraceRegistry struct {
 
 	// shutdownChan signals the cleanup loop to exit
 	shutdownChan chan struct{}
+
+	// agentScopes tracks SessionID -> *sessionScopes, the agent and workflow step spans that are
+	// currently open, so that nested and concurrent agents find their parent.
+	agentScopes sync.Map
+}
+
+// agentScope is an open agent or workflow step span, parent of the agents started below it.
+type agentScope struct {
+	name string
+	ctx  context.Context
+	span trace.Span
+}
+
+// sessionScopes holds a stack of open scopes per branch.
+type sessionScopes struct {
+	mu       sync.Mutex
+	byBranch map[string][]*agentScope
 }
 
 type cleanupRequest struct {
@@ -303,3 +322,107 @@ func (r *TraceRegistry) EndAllInvocationSpans() {
 		return true
 	})
 }
+
+func (r *TraceRegistry) getOrCreateSessionScopes(sessionID string) *sessionScopes {
+	val, _ := r.agentScopes.LoadOrStore(sessionID, &sessionScopes{byBranch: make(map[string][]*agentScope)})
+	return val.(*sessionScopes)
+}
+
+// PushAgentScope records an open agent or workflow step span on branch. ctx carries span.
+func (r *TraceRegistry) PushAgentScope(sessionID, branch, name string, ctx context.Context, span trace.Span) {
+	scopes := r.getOrCreateSessionScopes(sessionID)
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+	scopes.byBranch[branch] = append(scopes.byBranch[branch], &agentScope{name: name, ctx: ctx, span: span})
+}
+
+// PopAgentScope removes the innermost scope called name on branch and returns its span.
+// Scopes opened above it and never closed (e.g. agents whose run was cut short) are ended.
+func (r *TraceRegistry) PopAgentScope(sessionID, branch, name string) (trace.Span, bool) {
+	val, ok := r.agentScopes.Load(sessionID)
+	if !ok {
+		return nil, false
+	}
+	scopes := val.(*sessionScopes)
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+
+	stack := scopes.byBranch[branch]
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name != name {
+			continue
+		}
+		for _, stale := range stack[i+1:] {
+			stale.span.End()
+		}
+		span := stack[i].span
+		if i == 0 {
+			delete(scopes.byBranch, branch)
+		} else {
+			scopes.byBranch[branch] = stack[:i]
+		}
+		return span, true
+	}
+	return nil, false
+}
+
+// GetAgentScopeContext returns the context of the innermost open scope enclosing branch: the top of
+// the branch's own stack, or else of the closest ancestor branch (e.g. "a" for "a.b.c").
+func (r *TraceRegistry) GetAgentScopeContext(sessionID, branch string) (context.Context, bool) {
+	val, ok := r.agentScopes.Load(sessionID)
+	if !ok {
+		return nil, false
+	}
+	scopes := val.(*sessionScopes)
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+
+	for {
+		if stack := scopes.byBranch[branch]; len(stack) > 0 {
+			return stack[len(stack)-1].ctx, true
+		}
+		if branch == "" {
+			return nil, false
+		}
+		if i := strings.LastIndex(branch, "."); i >= 0 {
+			branch = branch[:i]
+		} else {
+			branch = ""
+		}
+	}
+}
+
+// GetAgentScopeSpan returns the span of the innermost open scope called name on branch.
+func (r *TraceRegistry) GetAgentScopeSpan(sessionID, branch, name string) (trace.Span, bool) {
+	val, ok := r.agentScopes.Load(sessionID)
+	if !ok {
+		return nil, false
+	}
+	scopes := val.(*sessionScopes)
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+
+	stack := scopes.byBranch[branch]
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == name {
+			return stack[i].span, true
+		}
+	}
+	return nil, false
+}
+
+// EndAgentScopes ends every scope still open in the session, typically when the run is over.
+func (r *TraceRegistry) EndAgentScopes(sessionID string) {
+	val, ok := r.agentScopes.LoadAndDelete(sessionID)
+	if !ok {
+		return
+	}
+	scopes := val.(*sessionScopes)
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+	for _, stack := range scopes.byBranch {
+		for i := len(stack) - 1; i >= 0; i-- {
+			stack[i].span.End()
+		}
+	}
+}
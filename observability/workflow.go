@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/adk/agent"
+	"google.golang.org/genai"
+)
+
+// WorkflowTypeCallback returns a before-agent callback recording workflowType on the invoke_agent
+// span of a workflow agent, which otherwise looks like any other agent in traces.
+func WorkflowTypeCallback(workflowType string) agent.BeforeAgentCallback {
+	return func(ctx agent.CallbackContext) (*genai.Content, error) {
+		if span, ok := GetRegistry().GetAgentScopeSpan(ctx.SessionID(), ctx.Branch(), ctx.AgentName()); ok {
+			span.SetAttributes(attribute.String(AttrWorkflowType, workflowType))
+		}
+		return nil, nil
+	}
+}
+
+// StartWorkflowStep starts a span for a step of the workflow agent running in ctx, such as a loop
+// iteration, under the agent's own invoke_agent span. Sub-agents started on branch until end is
+// called are parented under the step, so traces show the real execution tree.
+//
+// end must be called exactly once, with the error that failed the step if any.
+func StartWorkflowStep(ctx agent.InvocationContext, branch, name string, attrs ...attribute.KeyValue) (end func(err error)) {
+	sessionID := ctx.Session().ID()
+	parentCtx := context.Context(ctx)
+	if actx, ok := GetRegistry().GetAgentScopeContext(sessionID, ctx.Branch()); ok {
+		parentCtx = actx
+	}
+
+	stepCtx, span := otel.Tracer(InstrumentationName).Start(parentCtx, name)
+	setCommonAttributesFromInvocation(ctx, span)
+	setWorkflowAttributes(span)
+	setAgentAttributes(span, ctx.Agent().Name())
+	span.SetAttributes(attrs...)
+
+	GetRegistry().PushAgentScope(sessionID, branch, name, stepCtx, span)
+	return func(err error) {
+		GetRegistry().PopAgentScope(sessionID, branch, name)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability_test
+
+import (
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/agent/workflowagents/loopagent"
+	"github.com/volcengine/veadk-go/agent/workflowagents/parallelagent"
+	"github.com/volcengine/veadk-go/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func leaf(t *testing.T, name string) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(name, genai.RoleModel)
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestWorkflowSpanTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		parallel parallelagent.Config
+		loop     loopagent.Config
+	}{
+		{
+			name:     "without options",
+			parallel: parallelagent.Config{},
+			loop:     loopagent.Config{MaxIterations: 2},
+		},
+		{
+			name:     "with options",
+			parallel: parallelagent.Config{Aggregator: parallelagent.Concatenate(), BranchTimeout: time.Minute},
+			// the writer always answers the same, so the loop converges on its second iteration
+			loop: loopagent.Config{MaxIterations: 5, ExitConditions: []loopagent.ExitCondition{loopagent.Converged()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := otel.GetTracerProvider()
+			defer otel.SetTracerProvider(orig)
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+			observabilityPlugin, err := observability.NewTracingPlugin()
+			require.NoError(t, err)
+
+			tt.parallel.AgentConfig = agent.Config{Name: "panel", SubAgents: []agent.Agent{leaf(t, "lawyer"), leaf(t, "banker")}}
+			panel, err := parallelagent.New(tt.parallel)
+			require.NoError(t, err)
+			tt.loop.AgentConfig = agent.Config{Name: "loop", SubAgents: []agent.Agent{leaf(t, "writer")}}
+			loop, err := loopagent.New(tt.loop)
+			require.NoError(t, err)
+			pipeline, err := sequentialagent.New(sequentialagent.Config{AgentConfig: agent.Config{
+				Name:      "pipeline",
+				SubAgents: []agent.Agent{panel, loop},
+			}})
+			require.NoError(t, err)
+
+			sessions := session.InMemoryService()
+			r, err := runner.New(runner.Config{
+				AppName:        "app",
+				Agent:          pipeline,
+				SessionService: sessions,
+				PluginConfig:   runner.PluginConfig{Plugins: []*plugin.Plugin{observabilityPlugin}},
+			})
+			require.NoError(t, err)
+			created, err := sessions.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+			require.NoError(t, err)
+			for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("go", genai.RoleUser), agent.RunConfig{}) {
+				require.NoError(t, err)
+			}
+
+			assert.Len(t, recorder.Ended(), len(recorder.Started()), "every span is ended")
+			assert.False(t, observability.HasAgentScopes(created.Session.ID()), "the registry scopes are closed")
+
+			// parents maps each span name to the names of the parents of the spans called so.
+			parents := make(map[string][]string)
+			byID := make(map[string]string)
+			attrs := make(map[string]map[attribute.Key]attribute.Value)
+			for _, span := range recorder.Ended() {
+				byID[span.SpanContext().SpanID().String()] = span.Name()
+				attrs[span.Name()] = make(map[attribute.Key]attribute.Value)
+				for _, kv := range span.Attributes() {
+					attrs[span.Name()][kv.Key] = kv.Value
+				}
+			}
+			for _, span := range recorder.Ended() {
+				parents[span.Name()] = append(parents[span.Name()], byID[span.Parent().SpanID().String()])
+			}
+
+			assert.Equal(t, []string{observability.SpanInvocation}, parents["invoke_agent pipeline"])
+			assert.Equal(t, []string{"invoke_agent pipeline"}, parents["invoke_agent panel"])
+			assert.Equal(t, []string{"invoke_agent panel"}, parents["invoke_agent lawyer"])
+			assert.Equal(t, []string{"invoke_agent panel"}, parents["invoke_agent banker"])
+			assert.Equal(t, "panel.lawyer", attrs["invoke_agent lawyer"][observability.AttrWorkflowBranch].AsString())
+			assert.Equal(t, "panel.banker", attrs["invoke_agent banker"][observability.AttrWorkflowBranch].AsString())
+			assert.Equal(t, observability.WorkflowTypeParallel, attrs["invoke_agent panel"][observability.AttrWorkflowType].AsString())
+
+			assert.Equal(t, []string{"invoke_agent pipeline"}, parents["invoke_agent loop"])
+			assert.Equal(t, []string{"invoke_agent loop"}, parents["loop_iteration 1"])
+			assert.Equal(t, []string{"invoke_agent loop"}, parents["loop_iteration 2"])
+			assert.NotContains(t, parents, "loop_iteration 3")
+			assert.Equal(t, []string{"loop_iteration 1", "loop_iteration 2"}, parents["invoke_agent writer"])
+			assert.Equal(t, observability.WorkflowTypeLoop, attrs["invoke_agent loop"][observability.AttrWorkflowType].AsString())
+			assert.Equal(t, int64(2), attrs["loop_iteration 2"][observability.AttrLoopIteration].AsInt64())
+			assert.Equal(t, int64(2), attrs["invoke_agent loop"][observability.AttrLoopIteration].AsInt64())
+		})
+	}
+}
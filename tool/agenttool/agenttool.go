@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agenttool lets an agent call another agent, local or remote, as a function tool.
+//
+// Unlike a sub-agent, which takes over the conversation on transfer, an agent tool runs the
+// agent on a request of the caller's model and hands back its final answer as the tool result.
+package agenttool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/volcengine/veadk-go/log"
+	"github.com/volcengine/veadk-go/observability"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/plugin"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+)
+
+// StateKeyParentSessionID links an isolated child session to the session of the caller.
+const StateKeyParentSessionID = "veadk.agent_tool.parent_session_id"
+
+var ErrNoAgent = errors.New("agent tool requires an agent")
+
+// Request is the default input of an agent tool: a free-form request, sent to the agent as is.
+type Request struct {
+	Request string `json:"request" jsonschema:"The request for the agent, with all the context it needs."`
+}
+
+// Result is the output of an agent tool.
+type Result struct {
+	// Result is the final answer of the agent.
+	Result string `json:"result"`
+}
+
+type Config struct {
+	// Name and Description of the tool, shown to the calling model. They default to the agent's.
+	Name        string
+	Description string
+
+	// SkipSummarization ends the caller's turn with the tool result, instead of letting its
+	// model rephrase it.
+	SkipSummarization bool
+
+	// Isolated runs the agent in a child session of its own, which starts with an empty state and
+	// keeps the agent's history across calls from the same parent session; only the answer goes
+	// back to the caller. Otherwise the agent runs in a throwaway session starting from a copy of
+	// the caller's state, and its state changes are written back to the caller.
+	Isolated bool
+	// SessionService keeps the isolated child sessions, "<parent session ID>-<tool name>". They are
+	// not deleted with their parent session, so long-running servers should pass a service that
+	// expires idle sessions, such as the Redis backend or the PostgreSQL backend with a RetentionJanitor.
+	// Defaults to an in-memory service, which keeps them until the process exits.
+	SessionService session.Service
+
+	// Plugins run in the runner of the agent, e.g. the observability plugin to trace the agent
+	// and price its model calls.
+	Plugins []*plugin.Plugin
+}
+
+// New creates a tool that sends a free-form Request to a.
+func New(a agent.Agent, cfg *Config) (tool.Tool, error) {
+	return NewWithInput[Request](a, cfg)
+}
+
+// NewWithInput creates a tool whose input schema is inferred from TArgs, like a function tool.
+// The arguments are sent to a as JSON.
+func NewWithInput[TArgs any](a agent.Agent, cfg *Config) (tool.Tool, error) {
+	if a == nil {
+		return nil, ErrNoAgent
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	config := *cfg
+	if config.Name == "" {
+		config.Name = a.Name()
+	}
+	if config.Description == "" {
+		config.Description = a.Description()
+	}
+
+	sessions := session.InMemoryService()
+	if config.Isolated && config.SessionService != nil {
+		sessions = config.SessionService
+	} else if config.Isolated {
+		log.Warn("isolated agent tool keeps its child sessions in memory until the process exits", "tool", config.Name)
+	}
+	r, err := runner.New(runner.Config{
+		AppName:        a.Name(),
+		Agent:          a,
+		SessionService: sessions,
+		PluginConfig:   runner.PluginConfig{Plugins: config.Plugins},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runner for agent %s: %w", a.Name(), err)
+	}
+
+	t := &agentTool[TArgs]{config: config, agent: a, runner: r, sessions: sessions}
+	return functiontool.New(functiontool.Config{Name: config.Name, Description: config.Description}, t.run)
+}
+
+type agentTool[TArgs any] struct {
+	config   Config
+	agent    agent.Agent
+	runner   *runner.Runner
+	sessions session.Service
+}
+
+func (t *agentTool[TArgs]) run(ctx tool.Context, args TArgs) (Result, error) {
+	content, err := t.content(args)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var seed map[string]any
+	var child session.Session
+	if t.config.Isolated {
+		child, err = t.isolatedSession(ctx)
+	} else {
+		seed = sharedState(ctx.State())
+		var created *session.CreateResponse
+		created, err = t.sessions.Create(ctx, &session.CreateRequest{AppName: t.agent.Name(), UserID: ctx.UserID(), State: seed})
+		if created != nil {
+			child = created.Session
+		}
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create session for agent %s: %w", t.agent.Name(), err)
+	}
+	if !t.config.Isolated {
+		defer func() {
+			_ = t.sessions.Delete(ctx, &session.DeleteRequest{AppName: child.AppName(), UserID: child.UserID(), SessionID: child.ID()})
+		}()
+	}
+	costBefore := observability.GetCost(child.State(), observability.StateKeySessionCost)
+
+	var output string
+	usage := &genai.GenerateContentResponseUsageMetadata{}
+	for event, err := range t.runner.Run(ctx, child.UserID(), child.ID(), content, agent.RunConfig{}) {
+		if err != nil {
+			return Result{}, fmt.Errorf("agent %s failed: %w", t.agent.Name(), err)
+		}
+		if event.ErrorCode != "" || event.ErrorMessage != "" {
+			return Result{}, fmt.Errorf("agent %s failed (code: %q, message: %q)", t.agent.Name(), event.ErrorCode, event.ErrorMessage)
+		}
+		if event.Partial {
+			continue
+		}
+		if event.UsageMetadata != nil {
+			usage.PromptTokenCount += event.UsageMetadata.PromptTokenCount
+			usage.CandidatesTokenCount += event.UsageMetadata.CandidatesTokenCount
+			usage.TotalTokenCount += event.UsageMetadata.TotalTokenCount
+		}
+		if text := finalText(event); text != "" {
+			output = text
+		}
+	}
+
+	got, err := t.sessions.Get(ctx, &session.GetRequest{AppName: child.AppName(), UserID: child.UserID(), SessionID: child.ID()})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read session of agent %s: %w", t.agent.Name(), err)
+	}
+	cost := observability.GetCost(got.Session.State(), observability.StateKeySessionCost) - costBefore
+	observability.AddInvocationUsage(ctx, usage, cost)
+	if !t.config.Isolated {
+		writeBack(ctx.State(), seed, got.Session.State())
+	}
+
+	if t.config.SkipSummarization {
+		ctx.Actions().SkipSummarization = true
+	}
+	return Result{Result: output}, nil
+}
+
+// content converts the tool arguments to the user message of the agent.
+func (t *agentTool[TArgs]) content(args TArgs) (*genai.Content, error) {
+	if request, ok := any(args).(Request); ok {
+		return genai.NewContentFromText(request.Request, genai.RoleUser), nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments for agent %s: %w", t.agent.Name(), err)
+	}
+	return genai.NewContentFromText(string(data), genai.RoleUser), nil
+}
+
+// isolatedSession returns the child session of the caller's session, creating it on first use.
+func (t *agentTool[TArgs]) isolatedSession(ctx tool.Context) (session.Session, error) {
+	id := fmt.Sprintf("%s-%s", ctx.SessionID(), t.config.Name)
+	got, err := t.sessions.Get(ctx, &session.GetRequest{AppName: t.agent.Name(), UserID: ctx.UserID(), SessionID: id})
+	if err == nil {
+		return got.Session, nil
+	}
+	if !isNotFound(err) {
+		return nil, err
+	}
+	created, err := t.sessions.Create(ctx, &session.CreateRequest{
+		AppName:   t.agent.Name(),
+		UserID:    ctx.UserID(),
+		SessionID: id,
+		State:     map[string]any{StateKeyParentSessionID: ctx.SessionID()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created.Session, nil
+}
+
+// isNotFound reports whether err says that a session does not exist. The session services have no common
+// sentinel: the database services wrap gorm.ErrRecordNotFound, the others say so in their message.
+func isNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || strings.Contains(err.Error(), "not found")
+}
+
+// sharedState copies the caller's state, leaving out invocation-scoped and bookkeeping keys.
+func sharedState(state session.State) map[string]any {
+	shared := make(map[string]any)
+	for key, value := range state.All() {
+		if !private(key) {
+			shared[key] = value
+		}
+	}
+	return shared
+}
+
+// writeBack sets on the caller the state the agent changed. Costs are rolled up separately.
+func writeBack(state session.State, seed map[string]any, child session.ReadonlyState) {
+	for key, value := range child.All() {
+		if private(key) || key == observability.StateKeySessionCost || key == observability.StateKeyUserCost {
+			continue
+		}
+		if old, ok := seed[key]; ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		_ = state.Set(key, value)
+	}
+}
+
+func private(key string) bool {
+	return strings.HasPrefix(key, session.KeyPrefixTemp) ||
+		strings.HasPrefix(key, "_adk") ||
+		strings.HasPrefix(key, observability.StateKeyPrefix)
+}
+
+// finalText returns the text of a complete model answer, ignoring thoughts.
+func finalText(event *session.Event) string {
+	if event.Content == nil || event.Content.Role == genai.RoleUser {
+		return ""
+	}
+	var texts []string
+	for _, part := range event.Content.Parts {
+		if part != nil && part.Text != "" && !part.Thought {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "")
+}
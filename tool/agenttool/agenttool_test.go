@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Beijing Volcano Engine Technology Co., Ltd. and/or its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agenttool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/veadk-go/observability"
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/database"
+	"google.golang.org/adk/tool"
+	"google.golang.org/genai"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// callerLLM calls the tool with args, then answers with the tool result.
+type callerLLM struct {
+	tool    string
+	args    map[string]any
+	results []map[string]any
+}
+
+func (m *callerLLM) Name() string { return "caller" }
+
+func (m *callerLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		last := req.Contents[len(req.Contents)-1]
+		if response := last.Parts[0].FunctionResponse; response != nil {
+			m.results = append(m.results, response.Response)
+			yield(&model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)}, nil)
+			return
+		}
+		yield(&model.LLMResponse{Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			genai.NewPartFromFunctionCall(m.tool, m.args),
+		}}}, nil)
+	}
+}
+
+// researcher answers with its request, the topic in state and the length of its history. It
+// records a finding in state and reports usage and cost as the observability plugin would.
+func researcher(t *testing.T) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name:        "researcher",
+		Description: "Researches a topic.",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				topic, _ := ctx.Session().State().Get("topic")
+				answer := fmt.Sprintf("%s|%v|%d", ctx.UserContent().Parts[0].Text, topic, ctx.Session().Events().Len())
+				event := session.NewEvent(ctx.InvocationID())
+				event.Content = genai.NewContentFromText(answer, genai.RoleModel)
+				event.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}
+				event.Actions.StateDelta["finding"] = "found " + answer
+				event.Actions.StateDelta[observability.StateKeySessionCost] = observability.GetCost(ctx.Session().State(), observability.StateKeySessionCost) + 0.5
+				yield(event, nil)
+			}
+		},
+	})
+	require.NoError(t, err)
+	return a
+}
+
+// call runs a caller agent twice in the same session and returns its session and tool results.
+func call(t *testing.T, agentTool tool.Tool, args map[string]any) (session.Session, []map[string]any) {
+	t.Helper()
+	llm := &callerLLM{tool: agentTool.Name(), args: args}
+	caller, err := llmagent.New(llmagent.Config{Name: "caller", Model: llm, Tools: []tool.Tool{agentTool}})
+	require.NoError(t, err)
+
+	sessions := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: caller, SessionService: sessions})
+	require.NoError(t, err)
+	created, err := sessions.Create(t.Context(), &session.CreateRequest{
+		AppName: "app",
+		UserID:  "user",
+		State:   map[string]any{"topic": "go", observability.StateKeySessionCost: 1.0},
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		for _, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("research", genai.RoleUser), agent.RunConfig{}) {
+			require.NoError(t, err)
+		}
+	}
+	got, err := sessions.Get(t.Context(), &session.GetRequest{AppName: "app", UserID: "user", SessionID: created.Session.ID()})
+	require.NoError(t, err)
+	return got.Session, llm.results
+}
+
+func stateValue(t *testing.T, s session.Session, key string) any {
+	t.Helper()
+	value, _ := s.State().Get(key)
+	return value
+}
+
+func TestSharedState(t *testing.T) {
+	agentTool, err := New(researcher(t), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "researcher", agentTool.Name())
+
+	s, results := call(t, agentTool, map[string]any{"request": "compare runtimes"})
+	assert.Equal(t, []map[string]any{{"result": "compare runtimes|go|1"}, {"result": "compare runtimes|go|1"}}, results)
+	assert.Equal(t, "found compare runtimes|go|1", stateValue(t, s, "finding"))
+	assert.InDelta(t, 2.0, observability.GetCost(s.State(), observability.StateKeySessionCost), 1e-9)
+}
+
+func TestIsolated(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "sessions.db")
+	db, err := database.NewSessionService(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(db))
+
+	for name, sessions := range map[string]session.Service{"default": nil, "database": db} {
+		t.Run(name, func(t *testing.T) {
+			agentTool, err := New(researcher(t), &Config{Name: "research", Isolated: true, SessionService: sessions})
+			require.NoError(t, err)
+
+			s, results := call(t, agentTool, map[string]any{"request": "compare runtimes"})
+			// The child keeps its own history across calls and never sees the caller's state.
+			assert.Equal(t, []map[string]any{{"result": "compare runtimes|<nil>|1"}, {"result": "compare runtimes|<nil>|3"}}, results)
+			assert.Nil(t, stateValue(t, s, "finding"))
+			assert.InDelta(t, 2.0, observability.GetCost(s.State(), observability.StateKeySessionCost), 1e-9)
+		})
+	}
+}
+
+// unavailableService fails to read sessions, and counts the sessions it is asked to create.
+type unavailableService struct {
+	session.Service
+	creates int
+}
+
+func (s *unavailableService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (s *unavailableService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	s.creates++
+	return s.Service.Create(ctx, req)
+}
+
+func TestIsolatedSessionServiceError(t *testing.T) {
+	sessions := &unavailableService{Service: session.InMemoryService()}
+	agentTool, err := New(researcher(t), &Config{Name: "research", Isolated: true, SessionService: sessions})
+	require.NoError(t, err)
+
+	_, results := call(t, agentTool, map[string]any{"request": "compare runtimes"})
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0]["error"], "connection refused")
+	assert.Zero(t, sessions.creates, "only a missing session is created")
+}
+
+type compareArgs struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+func TestTypedInput(t *testing.T) {
+	agentTool, err := NewWithInput[compareArgs](researcher(t), nil)
+	require.NoError(t, err)
+
+	_, results := call(t, agentTool, map[string]any{"left": "go", "right": "rust"})
+	assert.Equal(t, `{"left":"go","right":"rust"}|go|1`, results[0]["result"])
+}
@@ -15,9 +15,9 @@
 package builtin_tools
 
 import (
-	"context"
 	"fmt"
 
+	"google.golang.org/adk/memory"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
@@ -33,6 +33,29 @@ func LoadLongMemoryTool() (tool.Tool, error) {
 	)
 }
 
+// LoadLongMemoryToolWithService is LoadLongMemoryTool searching service instead of the memory
+// service of the runner.
+func LoadLongMemoryToolWithService(service memory.Service) (tool.Tool, error) {
+	handler := func(tctx tool.Context, args Args) (Result, error) {
+		searchResults, err := service.Search(tctx, &memory.SearchRequest{
+			AppName: tctx.AppName(),
+			UserID:  tctx.UserID(),
+			Query:   args.Query,
+		})
+		if err != nil {
+			return Result{}, fmt.Errorf("failed memory search: %w", err)
+		}
+		return memoryResult(searchResults), nil
+	}
+	return functiontool.New(
+		functiontool.Config{
+			Name:        "search_past_conversations",
+			Description: "Searches past conversations for relevant information.",
+		},
+		handler,
+	)
+}
+
 type Args struct {
 	Query string `json:"query" jsonschema:"The query to search for in the memory."`
 }
@@ -43,18 +66,21 @@ type Result struct {
 
 func memorySearchToolFunc(tctx tool.Context, args Args) (Result, error) {
 
-	searchResults, err := tctx.SearchMemory(context.Background(), args.Query)
+	searchResults, err := tctx.SearchMemory(tctx, args.Query)
 	if err != nil {
-		return Result{}, fmt.Errorf("failed memory search")
+		return Result{}, fmt.Errorf("failed memory search: %w", err)
 	}
+	return memoryResult(searchResults), nil
+}
 
+func memoryResult(searchResults *memory.SearchResponse) Result {
 	var results []string
 	for _, res := range searchResults.Memories {
 		if res.Content != nil {
 			results = append(results, textParts(res.Content)...)
 		}
 	}
-	return Result{Results: results}, nil
+	return Result{Results: results}
 }
 
 func textParts(Content *genai.Content) []string {